@@ -1,32 +1,108 @@
 package main
 
 import (
+	"net/http"
+
 	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/pkg/storage"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 )
 
 func (s *Server) router() {
+	s.Factory.Router.Get("/.well-known/jwks.json", s.Handlers.JWKS)
+
+	// Files of the local storage backend are served here; every URL carries
+	// its own signature, so no authentication is needed.
+	if local, ok := s.Factory.Pkgs.Storage.(*storage.Local); ok {
+		s.Factory.Router.Handle(storage.LocalRoutePrefix+"*", http.StripPrefix(storage.LocalRoutePrefix, local.Handler()))
+	}
+
 	s.Factory.Router.Route("/api/v1", func(r chi.Router) {
 		r.Use(middleware.RequestID)
 		r.Use(s.Factory.Middleware.LoggerMiddleware)
 
 		r.Get("/health", s.Handlers.HealthCheckHandler)
 		r.Post("/set-password", s.Handlers.SetPassword)
+		r.Post("/set-password/resend", s.Handlers.ResendSetPasswordCode)
 		r.Post("/login", s.Handlers.Login)
+		r.Post("/login/mfa", s.Handlers.LoginMFA)
 		r.Post("/refresh", s.Handlers.RefreshToken)
+		r.Post("/forgot-password", s.Handlers.ForgotPassword)
+		r.Post("/reset-password", s.Handlers.ResetPassword)
+
+		r.Group(func(r chi.Router) {
+			r.Use(s.Factory.Middleware.RequireAuth)
+			r.Post("/logout", s.Handlers.Logout)
+			r.Post("/mfa/enroll", s.Handlers.EnrollMFA)
+			r.Post("/mfa/verify", s.Handlers.VerifyMFA)
+		})
+
+		r.Route("/users", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(s.Factory.Middleware.RequireAuth)
+				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
+
+				r.Post("/{id}/revoke-sessions", s.Handlers.RevokeUserSessions)
+				r.Post("/{id}/unlock", s.Handlers.UnlockUser)
+			})
+		})
+
+		r.Route("/notifications", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(s.Factory.Middleware.RequireAuth)
+				r.Get("/", s.Handlers.ListNotifications)
+				r.Get("/unread-count", s.Handlers.UnreadNotificationCount)
+				r.Get("/stream", s.Handlers.NotificationStream)
+				r.Post("/{id}/read", s.Handlers.MarkNotificationRead)
+				r.Post("/read-all", s.Handlers.MarkAllNotificationsRead)
+				r.Get("/preferences", s.Handlers.GetNotificationPreferences)
+				r.Put("/preferences", s.Handlers.UpdateNotificationPreferences)
+			})
+		})
 
 		r.Route("/members", func(r chi.Router) {
 			r.Group(func(r chi.Router) {
 				r.Use(s.Factory.Middleware.RequireAuth)
 				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
 
+				r.Get("/", s.Handlers.ListMembers)
 				r.Post("/", s.Handlers.CreateMember)
+				r.Post("/import", s.Handlers.ImportMembers)
+				r.Post("/{slug}/resend-invite", s.Handlers.ResendMemberInvite)
+				r.Get("/{slug}/history", s.Handlers.MemberHistory)
+				r.Post("/{slug}/status", s.Handlers.ChangeMemberStatus)
+				r.Get("/{slug}/status-history", s.Handlers.MemberStatusHistory)
+				r.Get("/{slug}/settlement", s.Handlers.MemberSettlementStatement)
+				r.Post("/{slug}/settlement", s.Handlers.SettleMemberExit)
+				r.Get("/{slug}/documents", s.Handlers.ListMemberDocuments)
+				r.Post("/{slug}/documents", s.Handlers.UploadMemberDocument)
+				r.Get("/{slug}/documents/{id}/download", s.Handlers.DownloadMemberDocument)
+				r.Post("/{slug}/documents/{id}/verify", s.Handlers.VerifyMemberDocument)
+				r.Post("/{slug}/documents/{id}/reject", s.Handlers.RejectMemberDocument)
+				r.Get("/{slug}/beneficiaries", s.Handlers.ListMemberBeneficiaries)
+				r.Put("/{slug}/beneficiaries", s.Handlers.ReplaceMemberBeneficiaries)
+				r.Get("/change-requests", s.Handlers.ListChangeRequests)
+				r.Post("/change-requests/{id}/approve", s.Handlers.ApproveChangeRequest)
+				r.Post("/change-requests/{id}/reject", s.Handlers.RejectChangeRequest)
 			})
 
 			r.Group(func(r chi.Router) {
 				r.Use(s.Factory.Middleware.RequireAuth)
 				r.Use(s.Factory.Middleware.RequireRole(constants.RoleMember))
+				r.Put("/me", s.Handlers.UpdateProfile)
+				r.Get("/me/change-requests", s.Handlers.ListMyChangeRequests)
+				r.Get("/me/documents", s.Handlers.ListMyDocuments)
+				r.Post("/me/documents", s.Handlers.UploadMyDocument)
+				r.Get("/me/documents/{id}/download", s.Handlers.DownloadMyDocument)
+				r.Get("/me/beneficiaries", s.Handlers.ListMyBeneficiaries)
+				r.Post("/me/beneficiaries", s.Handlers.AddMyBeneficiary)
+				r.Put("/me/beneficiaries", s.Handlers.ReplaceMyBeneficiaries)
+				r.Patch("/me/beneficiaries/{id}", s.Handlers.UpdateMyBeneficiary)
+				r.Delete("/me/beneficiaries/{id}", s.Handlers.DeleteMyBeneficiary)
+				r.Get("/me/summary", s.Handlers.MemberSummary)
 				r.Get("/{slug}", s.Handlers.MemberBySlug)
 			})
 		})
@@ -54,9 +130,11 @@ func (s *Server) router() {
 			r.Group(func(r chi.Router) {
 				r.Use(s.Factory.Middleware.RequireAuth)
 				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
 
 				r.Patch("/status/{status_id}", s.Handlers.UpdateStatus)
 				r.Get("/pending", s.Handlers.ListPendingTransactions)
+				r.Post("/opening-balances", s.Handlers.ImportOpeningBalances)
 			})
 		})
 
@@ -64,6 +142,7 @@ func (s *Server) router() {
 			r.Group(func(r chi.Router) {
 				r.Use(s.Factory.Middleware.RequireAuth)
 				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
 
 				r.Patch("/unit-price", s.Handlers.SetShareUnitPrice)
 				r.Get("/total", s.Handlers.GetTotalSharesPurchased)
@@ -84,6 +163,7 @@ func (s *Server) router() {
 			r.Group(func(r chi.Router) {
 				r.Use(s.Factory.Middleware.RequireAuth)
 				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
 				r.Post("/", s.Handlers.CreateFine)
 				r.Get("/", s.Handlers.ListFines)
 			})
@@ -100,8 +180,68 @@ func (s *Server) router() {
 			r.Group(func(r chi.Router) {
 				r.Use(s.Factory.Middleware.RequireAuth)
 				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
 				r.Post("/", s.Handlers.PayRegistrationFee)
 			})
 		})
+
+		r.Route("/email-outbox", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(s.Factory.Middleware.RequireAuth)
+				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
+				r.Get("/", s.Handlers.ListEmailOutbox)
+				r.Post("/{id}/retry", s.Handlers.RetryEmail)
+			})
+		})
+
+		r.Route("/webhooks", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(s.Factory.Middleware.RequireAuth)
+				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
+				r.Get("/", s.Handlers.ListWebhookEndpoints)
+				r.Post("/", s.Handlers.CreateWebhookEndpoint)
+				r.Get("/event-types", s.Handlers.ListWebhookEventTypes)
+				r.Post("/deliveries/{id}/redeliver", s.Handlers.RedeliverWebhook)
+				r.Get("/{id}", s.Handlers.GetWebhookEndpoint)
+				r.Patch("/{id}", s.Handlers.UpdateWebhookEndpoint)
+				r.Delete("/{id}", s.Handlers.DeleteWebhookEndpoint)
+				r.Post("/{id}/rotate-secret", s.Handlers.RotateWebhookSecret)
+				r.Get("/{id}/deliveries", s.Handlers.ListWebhookDeliveries)
+			})
+		})
+
+		r.Route("/jobs", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(s.Factory.Middleware.RequireAuth)
+				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
+				r.Get("/", s.Handlers.ListJobs)
+				r.Get("/runs", s.Handlers.ListJobRuns)
+				r.Get("/{name}/runs", s.Handlers.ListJobRuns)
+				r.Post("/{name}/run", s.Handlers.TriggerJob)
+			})
+		})
+
+		r.Route("/email-templates", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(s.Factory.Middleware.RequireAuth)
+				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
+				r.Get("/", s.Handlers.ListEmailTemplates)
+				r.Get("/{name}/preview", s.Handlers.PreviewEmailTemplate)
+			})
+		})
+
+		r.Route("/dev/mailbox", func(r chi.Router) {
+			r.Group(func(r chi.Router) {
+				r.Use(s.Factory.Middleware.RequireAuth)
+				r.Use(s.Factory.Middleware.RequireRole(constants.RoleAdmin))
+				r.Use(s.Factory.Middleware.RequireMFA)
+				r.Get("/", s.Handlers.ListMailbox)
+				r.Delete("/", s.Handlers.ClearMailbox)
+			})
+		})
 	})
 }
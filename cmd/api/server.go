@@ -1,6 +1,7 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
@@ -50,6 +51,15 @@ func NewServer() (*Server, func(), error) {
 }
 
 func (s *Server) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go s.Factory.Services.Mailer.Run(ctx)
+	go s.Factory.Services.Texter.Run(ctx)
+	go s.Factory.Services.Webhooks.Run(ctx)
+	go s.Factory.Services.Events.Run(ctx)
+	go s.Factory.Services.Notifications.Hub.Run(ctx)
+
 	fmt.Printf(" Server running on http://localhost:%s%s\n", s.Config.Server.Port, "/api/v1")
 
 	srv := &http.Server{
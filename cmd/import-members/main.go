@@ -0,0 +1,63 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/Jidetireni/ara-cooperative/factory"
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run imports the members in a CSV file, the same way POST /members/import
+// does, and prints the report.
+func run() error {
+	path := flag.String("file", "", "path of the CSV file to import")
+	dryRun := flag.Bool("dry-run", false, "only validate the file")
+	flag.Parse()
+
+	if *path == "" {
+		return errors.New("-file is required")
+	}
+
+	file, err := os.Open(*path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	cfg := config.New()
+
+	factory, cleanup, err := factory.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create importer: %w", err)
+	}
+	defer cleanup()
+
+	report, err := factory.Services.Member.ImportMembers(context.Background(), file, *dryRun)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return err
+	}
+
+	if len(report.Errors) > 0 {
+		return fmt.Errorf("%d problem(s) found, see the report", len(report.Errors))
+	}
+
+	return nil
+}
@@ -57,7 +57,13 @@ func (s *Seed) createUserWithMember(ctx context.Context, seedUser SeedMember, ro
 	}
 
 	// Create member with unique slug
-	memberSlug := fmt.Sprintf("ara%06d", helpers.GetNextMemberNumber())
+	memberSlug, err := s.MemberRepo.AllocateSlug(ctx, func(number int64) string {
+		cfg := s.Config.Members
+		return helpers.FormatMemberNumber(cfg.NumberPrefix, cfg.NumberPadding, cfg.NumberCheckDigit, number)
+	}, tx)
+	if err != nil {
+		return nil, fmt.Errorf("allocate member number: %w", err)
+	}
 	member := &repository.Member{
 		UserID:    createdUser.ID,
 		Slug:      memberSlug,
@@ -0,0 +1,40 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/Jidetireni/ara-cooperative/factory"
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+)
+
+func main() {
+	if err := run(); err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// run starts the job scheduler and blocks until the process is told to stop.
+// Any number of workers can run side by side; each job still runs on only
+// one of them at a time.
+func run() error {
+	cfg := config.New()
+
+	factory, cleanup, err := factory.New(cfg)
+	if err != nil {
+		return fmt.Errorf("failed to create worker: %w", err)
+	}
+	defer cleanup()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	fmt.Println(" Worker running")
+
+	factory.Services.Jobs.Run(ctx)
+	return nil
+}
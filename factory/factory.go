@@ -1,44 +1,73 @@
 package factory
 
 import (
+	"time"
+
 	"github.com/Jidetireni/ara-cooperative/internal/config"
 	"github.com/Jidetireni/ara-cooperative/internal/middleware"
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/internal/services/events"
+	"github.com/Jidetireni/ara-cooperative/internal/services/jobs"
+	"github.com/Jidetireni/ara-cooperative/internal/services/mailer"
 	"github.com/Jidetireni/ara-cooperative/internal/services/members"
+	"github.com/Jidetireni/ara-cooperative/internal/services/notifications"
+	"github.com/Jidetireni/ara-cooperative/internal/services/texter"
 	"github.com/Jidetireni/ara-cooperative/internal/services/transactions"
 	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
 
 	"github.com/Jidetireni/ara-cooperative/pkg/cache"
 	"github.com/Jidetireni/ara-cooperative/pkg/database"
 	emailpkg "github.com/Jidetireni/ara-cooperative/pkg/email"
 	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/Jidetireni/ara-cooperative/pkg/sms"
+	"github.com/Jidetireni/ara-cooperative/pkg/storage"
 	"github.com/Jidetireni/ara-cooperative/pkg/token"
 	"github.com/go-chi/chi/v5"
 )
 
 type Repositories struct {
-	Member      *repository.MemberRepository
-	User        *repository.UserRepository
-	Role        *repository.RoleRepository
-	Permission  *repository.PermissionRepository
-	Token       *repository.TokenRepository
-	Transaction *repository.TransactionRepository
-	Share       *repository.ShareRepository
-	Fine        *repository.FineRepository
+	Member       *repository.MemberRepository
+	User         *repository.UserRepository
+	Role         *repository.RoleRepository
+	Permission   *repository.PermissionRepository
+	Token        *repository.TokenRepository
+	Transaction  *repository.TransactionRepository
+	Share        *repository.ShareRepository
+	Fine         *repository.FineRepository
+	MFA          *repository.MFARepository
+	EmailOutbox  *repository.EmailOutboxRepository
+	SMSOutbox    *repository.SMSOutboxRepository
+	Notification *repository.NotificationRepository
+	Webhook      *repository.WebhookRepository
+	DomainEvent  *repository.DomainEventRepository
+	JobRun       *repository.JobRunRepository
+	MemberChange *repository.MemberChangeRepository
+	Settlement   *repository.SettlementRepository
+	Document     *repository.MemberDocumentRepository
+	Beneficiary  *repository.MemberBeneficiaryRepository
 }
 
 type Services struct {
-	Member       *members.Member
-	User         *users.User
-	Transactions *transactions.Transaction
+	Member        *members.Member
+	User          *users.User
+	Transactions  *transactions.Transaction
+	Mailer        *mailer.Mailer
+	Texter        *texter.Texter
+	Notifications *notifications.Notifications
+	Webhooks      *webhooks.Webhooks
+	Events        *events.Dispatcher
+	Jobs          *jobs.Scheduler
 }
 
 type Packages struct {
-	DB     *database.PostgresDB
-	Email  *emailpkg.Email
-	JWTTok *token.Jwt
-	Logger *logger.Logger
-	Cache  *cache.Redis
+	DB      *database.PostgresDB
+	Email   *emailpkg.Email
+	SMS     *sms.SMS
+	JWTTok  *token.Jwt
+	Logger  *logger.Logger
+	Cache   *cache.Redis
+	Storage storage.Storage
 }
 
 type Factory struct {
@@ -55,13 +84,27 @@ func New(cfg *config.Config) (*Factory, func(), error) {
 		return nil, nil, err
 	}
 
-	jwtToken := token.NewJwt(cfg.Auth.JWTSecret, cfg.IsDev)
+	jwtKeys, err := token.NewKeySet(cfg.Auth.JWTSecret, cfg.Auth.JWTKeysFile)
+	if err != nil {
+		return nil, nil, err
+	}
+	jwtToken := token.NewJwt(jwtKeys, cfg.IsDev)
 
 	email, err := emailpkg.New(cfg)
 	if err != nil {
 		return nil, nil, err
 	}
 
+	smsPkg, err := sms.New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fileStorage, err := storage.New(cfg)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	logger := logger.New(*cfg)
 
 	redis, cacheCleanUp := cache.New(cfg, logger)
@@ -74,26 +117,70 @@ func New(cfg *config.Config) (*Factory, func(), error) {
 	transactionRepo := repository.NewTransactionRepository(db.DB)
 	shareRepo := repository.NewShareRepository(db.DB)
 	fineRepo := repository.NewFineRepository(db.DB)
+	mfaRepo := repository.NewMFARepository(db.DB)
+	emailOutboxRepo := repository.NewEmailOutboxRepository(db.DB)
+	smsOutboxRepo := repository.NewSMSOutboxRepository(db.DB)
+	notificationRepo := repository.NewNotificationRepository(db.DB)
+	webhookRepo := repository.NewWebhookRepository(db.DB)
+	domainEventRepo := repository.NewDomainEventRepository(db.DB)
+	jobRunRepo := repository.NewJobRunRepository(db.DB)
+	memberChangeRepo := repository.NewMemberChangeRepository(db.DB)
+	settlementRepo := repository.NewSettlementRepository(db.DB)
+	documentRepo := repository.NewMemberDocumentRepository(db.DB)
+	beneficiaryRepo := repository.NewMemberBeneficiaryRepository(db.DB)
 
-	membersService := members.New(
+	mailerService := mailer.New(emailOutboxRepo, email, logger)
+	texterService := texter.New(smsOutboxRepo, smsPkg, logger)
+	webhooksService := webhooks.New(cfg, webhookRepo, logger)
+	eventsDispatcher := events.New(domainEventRepo, logger)
+
+	usersService := users.New(
 		db.DB,
 		cfg,
-		memberRepo,
+		jwtToken,
 		userRepo,
 		roleRepo,
 		permissionRepo,
 		tokenRepo,
-		email,
+		memberRepo,
+		mfaRepo,
+		redis,
+		mailerService,
+		logger,
 	)
 
-	usersService := users.New(
+	membersService := members.New(
 		db.DB,
 		cfg,
-		jwtToken,
+		memberRepo,
 		userRepo,
 		roleRepo,
 		permissionRepo,
 		tokenRepo,
+		memberChangeRepo,
+		documentRepo,
+		beneficiaryRepo,
+		transactionRepo,
+		fileStorage,
+		mailerService,
+		texterService,
+		webhooksService,
+		usersService,
+		logger,
+	)
+	mailerService.OnResult(members.InvitationEmailReference, membersService.RecordInvitationResult)
+
+	notificationsService := notifications.New(
+		db.DB,
+		cfg,
+		notificationRepo,
+		userRepo,
+		memberRepo,
+		fineRepo,
+		mailerService,
+		texterService,
+		redis,
+		logger,
 	)
 
 	transactionService := transactions.New(
@@ -102,35 +189,89 @@ func New(cfg *config.Config) (*Factory, func(), error) {
 		memberRepo,
 		shareRepo,
 		fineRepo,
+		settlementRepo,
+		beneficiaryRepo,
 		redis,
+		notificationsService,
+		webhooksService,
+		eventsDispatcher,
+		membersService,
+		usersService,
 		logger,
 	)
 
-	middleware := middleware.New(jwtToken, logger)
+	events.On(eventsDispatcher, "members.activate_on_registration_fee", membersService.ActivateOnRegistrationFee)
+	events.On(eventsDispatcher, "transactions.settle_fine", transactionService.SettleFine)
+	events.On(eventsDispatcher, "transactions.notify_confirmed", transactionService.NotifyConfirmed)
+	events.On(eventsDispatcher, "transactions.notify_rejected", transactionService.NotifyRejected)
+
+	// Loan aging and interest accrual are not scheduled: there is no loans
+	// module yet to run them against.
+	jobsScheduler := jobs.New(db.DB, jobRunRepo, logger)
+	jobsScheduler.Register(jobs.Job{
+		Name:        "expire_set_password_tokens",
+		Description: "Invalidate set-password codes that expired unused",
+		Schedule:    "0 * * * *",
+		Run:         membersService.ExpireSetPasswordTokens,
+	})
+	jobsScheduler.Register(jobs.Job{
+		Name:        "fine_deadline_reminders",
+		Description: "Remind members of unpaid fines due within 48 hours",
+		Schedule:    "15 * * * *",
+		Run:         notificationsService.RemindFineDeadlines,
+	})
+	jobsScheduler.Register(jobs.Job{
+		Name:        "warm_shares_unit_price_cache",
+		Description: "Reload the cached shares unit price from the database",
+		Schedule:    "*/30 * * * *",
+		Timeout:     time.Minute,
+		Run:         transactionService.WarmSharesUnitPriceCache,
+	})
+
+	middleware := middleware.New(jwtToken, redis, logger)
 
 	return &Factory{
 			Router: chi.NewRouter(),
 			Pkgs: &Packages{
-				DB:     db,
-				Email:  email,
-				JWTTok: jwtToken,
-				Logger: logger,
-				Cache:  redis,
+				DB:      db,
+				Email:   email,
+				SMS:     smsPkg,
+				JWTTok:  jwtToken,
+				Logger:  logger,
+				Cache:   redis,
+				Storage: fileStorage,
 			},
 			Services: &Services{
-				Member:       membersService,
-				User:         usersService,
-				Transactions: transactionService,
+				Member:        membersService,
+				User:          usersService,
+				Transactions:  transactionService,
+				Mailer:        mailerService,
+				Texter:        texterService,
+				Notifications: notificationsService,
+				Webhooks:      webhooksService,
+				Events:        eventsDispatcher,
+				Jobs:          jobsScheduler,
 			},
 			Repositories: &Repositories{
-				Member:      memberRepo,
-				User:        userRepo,
-				Role:        roleRepo,
-				Permission:  permissionRepo,
-				Token:       tokenRepo,
-				Transaction: transactionRepo,
-				Share:       shareRepo,
-				Fine:        fineRepo,
+				Member:       memberRepo,
+				User:         userRepo,
+				Role:         roleRepo,
+				Permission:   permissionRepo,
+				Token:        tokenRepo,
+				Transaction:  transactionRepo,
+				Share:        shareRepo,
+				Fine:         fineRepo,
+				MFA:          mfaRepo,
+				EmailOutbox:  emailOutboxRepo,
+				SMSOutbox:    smsOutboxRepo,
+				Notification: notificationRepo,
+				Webhook:      webhookRepo,
+				DomainEvent:  domainEventRepo,
+				JobRun:       jobRunRepo,
+				MemberChange: memberChangeRepo,
+				Settlement:   settlementRepo,
+				Document:     documentRepo,
+				Beneficiary:  beneficiaryRepo,
 			},
 			Middleware: middleware,
 		}, func() {
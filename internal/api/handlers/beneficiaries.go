@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *Handlers) ListMyBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	result, err := h.factory.Services.Member.ListMyBeneficiaries(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) AddMyBeneficiary(w http.ResponseWriter, r *http.Request) {
+	var input dto.BeneficiaryInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	beneficiary, err := h.factory.Services.Member.AddMyBeneficiary(r.Context(), input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, beneficiary, nil)
+}
+
+func (h *Handlers) UpdateMyBeneficiary(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.beneficiaryID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateBeneficiaryInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	beneficiary, err := h.factory.Services.Member.UpdateMyBeneficiary(r.Context(), id, input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, beneficiary, nil)
+}
+
+func (h *Handlers) DeleteMyBeneficiary(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.beneficiaryID(w, r)
+	if !ok {
+		return
+	}
+
+	if err := h.factory.Services.Member.DeleteMyBeneficiary(r.Context(), id); err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]bool{"success": true}, nil)
+}
+
+func (h *Handlers) ReplaceMyBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	var input dto.ReplaceBeneficiariesInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	result, err := h.factory.Services.Member.ReplaceMyBeneficiaries(r.Context(), input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) ListMemberBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberReadALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	result, err := h.factory.Services.Member.ListBeneficiaries(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) ReplaceMemberBeneficiaries(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	var input dto.ReplaceBeneficiariesInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	result, err := h.factory.Services.Member.ReplaceBeneficiaries(r.Context(), chi.URLParam(r, "slug"), input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) beneficiaryID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid beneficiary ID",
+		})
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/pkg/email"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+func (h *Handlers) ListEmailOutbox(w http.ResponseWriter, r *http.Request) {
+	permission := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permission)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return
+	}
+
+	var status *string
+	if v := r.URL.Query().Get("status"); v != "" {
+		switch v {
+		case repository.EmailOutboxStatusPending, repository.EmailOutboxStatusSent, repository.EmailOutboxStatusDead:
+			status = &v
+		default:
+			h.errorResponse(w, r, &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "status must be one of PENDING, SENT, DEAD",
+			})
+			return
+		}
+	}
+
+	result, err := h.factory.Services.Mailer.List(r.Context(), status, h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) RetryEmail(w http.ResponseWriter, r *http.Request) {
+	permission := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permission)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return
+	}
+
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid email ID",
+		})
+		return
+	}
+
+	msg, err := h.factory.Services.Mailer.Retry(r.Context(), id)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, msg, nil)
+}
+
+func (h *Handlers) ListEmailTemplates(w http.ResponseWriter, r *http.Request) {
+	permission := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permission)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, email.TemplateTypes, nil)
+}
+
+// PreviewEmailTemplate renders a template with sample data. Pass ?format=html
+// or ?format=text to get the raw part, e.g. to open it in a browser.
+func (h *Handlers) PreviewEmailTemplate(w http.ResponseWriter, r *http.Request) {
+	permission := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permission)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return
+	}
+
+	name := email.EmailTemplateType(chi.URLParam(r, "name"))
+	rendered, err := h.factory.Services.Mailer.Preview(name)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	switch r.URL.Query().Get("format") {
+	case "html":
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(rendered.HTML))
+	case "text":
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(rendered.Text))
+	default:
+		h.writeJSON(w, http.StatusOK, rendered, nil)
+	}
+}
@@ -3,7 +3,9 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"math"
 	"net/http"
+	"strconv"
 
 	"github.com/Jidetireni/ara-cooperative/internal/services"
 )
@@ -27,6 +29,10 @@ func (h *Handlers) errorResponse(w http.ResponseWriter, r *http.Request, message
 		if apiErr.Errors != nil {
 			resp["errors"] = apiErr.Errors
 		}
+		if apiErr.RetryAfter > 0 {
+			seconds := int(math.Ceil(apiErr.RetryAfter.Seconds()))
+			w.Header().Set("Retry-After", strconv.Itoa(seconds))
+		}
 	} else if err, ok := message.(error); ok {
 		h.logError(r, err)
 	} else {
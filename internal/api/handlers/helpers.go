@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/Jidetireni/ara-cooperative/internal/dto"
@@ -95,6 +98,28 @@ func setRefreshCookie(w http.ResponseWriter, refreshToken string, duration time.
 	})
 }
 
+// clearRefreshCookie instructs the browser to drop the refresh token cookie
+func clearRefreshCookie(w http.ResponseWriter, isDev bool) {
+	secure := true
+	sameSite := http.SameSiteStrictMode
+
+	if isDev {
+		secure = false
+		sameSite = http.SameSiteLaxMode
+	}
+
+	http.SetCookie(w, &http.Cookie{
+		Name:     token.RefreshTokenName,
+		Value:    "",
+		Path:     "/",
+		Expires:  time.Unix(0, 0),
+		MaxAge:   -1,
+		HttpOnly: true,
+		Secure:   secure,
+		SameSite: sameSite,
+	})
+}
+
 func (h *Handlers) parseFineFilters(r *http.Request) (dto.FineFilter, error) {
 	q := r.URL.Query()
 	filters := dto.FineFilter{}
@@ -123,3 +148,116 @@ func (h *Handlers) parseFineFilters(r *http.Request) (dto.FineFilter, error) {
 
 	return filters, nil
 }
+
+func (h *Handlers) parseNotificationFilters(r *http.Request) (dto.NotificationFilter, error) {
+	filters := dto.NotificationFilter{}
+
+	if unreadStr := r.URL.Query().Get("unread"); unreadStr != "" {
+		unread, err := strconv.ParseBool(unreadStr)
+		if err != nil {
+			return filters, &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "invalid boolean for 'unread'",
+			}
+		}
+		filters.Unread = &unread
+	}
+
+	return filters, nil
+}
+
+func (h *Handlers) parseMemberFilters(r *http.Request) (dto.MemberFilter, error) {
+	q := r.URL.Query()
+	filters := dto.MemberFilter{}
+
+	if search := strings.TrimSpace(q.Get("q")); search != "" {
+		filters.Search = &search
+	}
+
+	if v := q.Get("status"); v != "" {
+		status := dto.MemberStatus(v)
+		switch status {
+		case dto.MemberStatusPending, dto.MemberStatusActive, dto.MemberStatusSuspended,
+			dto.MemberStatusDormant, dto.MemberStatusExiting, dto.MemberStatusExited:
+			filters.Status = &status
+		default:
+			return filters, &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "status must be one of pending, active, suspended, dormant, exiting, exited",
+			}
+		}
+	}
+
+	for param, target := range map[string]**time.Time{
+		"joined_from": &filters.JoinedFrom,
+		"joined_to":   &filters.JoinedTo,
+	} {
+		if v := q.Get(param); v != "" {
+			date, err := time.Parse(time.DateOnly, v)
+			if err != nil {
+				return filters, &svc.APIError{
+					Status:  http.StatusBadRequest,
+					Message: fmt.Sprintf("invalid date for '%s', expected YYYY-MM-DD", param),
+				}
+			}
+			*target = &date
+		}
+	}
+
+	if filters.JoinedFrom != nil && filters.JoinedTo != nil && filters.JoinedTo.Before(*filters.JoinedFrom) {
+		return filters, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "'joined_to' must not be before 'joined_from'",
+		}
+	}
+
+	return filters, nil
+}
+
+// clientIP returns the address of the client. Forwarding headers are only
+// believed when the direct peer is one of the configured trusted proxies,
+// since anyone else can spoof them to dodge IP throttling. X-Forwarded-For is
+// read from the right, skipping the trusted proxies that appended to it.
+func (h *Handlers) clientIP(r *http.Request) string {
+	peer, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		peer = r.RemoteAddr
+	}
+	if !h.trustedProxy(peer) {
+		return peer
+	}
+
+	if forwarded := r.Header.Values("X-Forwarded-For"); len(forwarded) > 0 {
+		hops := strings.Split(strings.Join(forwarded, ","), ",")
+		for i := len(hops) - 1; i >= 0; i-- {
+			hop := strings.TrimSpace(hops[i])
+			if net.ParseIP(hop) == nil {
+				break
+			}
+			if !h.trustedProxy(hop) {
+				return hop
+			}
+		}
+	}
+
+	if realIP := strings.TrimSpace(r.Header.Get("X-Real-IP")); net.ParseIP(realIP) != nil {
+		return realIP
+	}
+
+	return peer
+}
+
+func (h *Handlers) trustedProxy(addr string) bool {
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		return false
+	}
+
+	for _, network := range h.config.Server.TrustedProxies {
+		if network.Contains(ip) {
+			return true
+		}
+	}
+
+	return false
+}
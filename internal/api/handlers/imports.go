@@ -0,0 +1,61 @@
+package handlers
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+)
+
+// maxCSVUploadSize bounds the size of an uploaded CSV file.
+const maxCSVUploadSize = 5 << 20
+
+// readCSVUpload returns the CSV file of an import request, sent either as the
+// "file" field of a multipart form or as the raw request body, and whether the
+// import is a dry run. It writes the error response itself when it fails.
+func (h *Handlers) readCSVUpload(w http.ResponseWriter, r *http.Request) (io.ReadCloser, bool, bool) {
+	dryRun := false
+	if v := r.URL.Query().Get("dry_run"); v != "" {
+		parsed, err := strconv.ParseBool(v)
+		if err != nil {
+			h.errorResponse(w, r, &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "dry_run must be true or false",
+			})
+			return nil, false, false
+		}
+		dryRun = parsed
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxCSVUploadSize)
+
+	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
+		return r.Body, dryRun, true
+	}
+
+	upload, _, err := r.FormFile("file")
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: fmt.Sprintf("invalid upload: %v", err),
+		})
+		return nil, false, false
+	}
+
+	return upload, dryRun, true
+}
+
+// importStatus is the status an import is answered with.
+func importStatus(errors int, dryRun bool) int {
+	switch {
+	case errors > 0:
+		return http.StatusUnprocessableEntity
+	case dryRun:
+		return http.StatusOK
+	default:
+		return http.StatusCreated
+	}
+}
@@ -0,0 +1,85 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/go-chi/chi/v5"
+)
+
+// canManageJobs writes a 403 unless the admin may inspect and trigger jobs.
+func (h *Handlers) canManageJobs(w http.ResponseWriter, r *http.Request) bool {
+	permission := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permission) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return false
+	}
+
+	return true
+}
+
+func (h *Handlers) ListJobs(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageJobs(w, r) {
+		return
+	}
+
+	jobs, err := h.factory.Services.Jobs.List(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, jobs, nil)
+}
+
+// TriggerJob queues a run for the worker, so it responds before the job runs.
+func (h *Handlers) TriggerJob(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageJobs(w, r) {
+		return
+	}
+
+	run, err := h.factory.Services.Jobs.Trigger(r.Context(), chi.URLParam(r, "name"))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, run, nil)
+}
+
+func (h *Handlers) ListJobRuns(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageJobs(w, r) {
+		return
+	}
+
+	var jobName *string
+	if name := chi.URLParam(r, "name"); name != "" {
+		jobName = &name
+	}
+
+	var status *string
+	if v := r.URL.Query().Get("status"); v != "" {
+		switch v {
+		case repository.JobRunStatusQueued, repository.JobRunStatusRunning, repository.JobRunStatusSucceeded,
+			repository.JobRunStatusFailed, repository.JobRunStatusSkipped:
+			status = &v
+		default:
+			h.errorResponse(w, r, &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "status must be one of QUEUED, RUNNING, SUCCEEDED, FAILED, SKIPPED",
+			})
+			return
+		}
+	}
+
+	result, err := h.factory.Services.Jobs.ListRuns(r.Context(), jobName, status, h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
@@ -0,0 +1,54 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/pkg/email"
+)
+
+// mailbox returns the capture transport, writing a 404 when email is really being sent.
+func (h *Handlers) mailbox(w http.ResponseWriter, r *http.Request) (*email.CaptureTransport, bool) {
+	permission := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permission) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return nil, false
+	}
+
+	mailbox := h.factory.Pkgs.Email.Mailbox()
+	if mailbox == nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusNotFound,
+			Message: "mailbox is only available with EMAIL_TRANSPORT=capture",
+		})
+		return nil, false
+	}
+
+	return mailbox, true
+}
+
+func (h *Handlers) ListMailbox(w http.ResponseWriter, r *http.Request) {
+	mailbox, ok := h.mailbox(w, r)
+	if !ok {
+		return
+	}
+
+	messages := mailbox.Messages()
+	if to := r.URL.Query().Get("to"); to != "" {
+		messages = mailbox.MessagesTo(to)
+	}
+
+	h.writeJSON(w, http.StatusOK, messages, nil)
+}
+
+func (h *Handlers) ClearMailbox(w http.ResponseWriter, r *http.Request) {
+	mailbox, ok := h.mailbox(w, r)
+	if !ok {
+		return
+	}
+
+	mailbox.Reset()
+	h.writeJSON(w, http.StatusOK, map[string]bool{"success": true}, nil)
+}
@@ -0,0 +1,175 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// memberHistoryFields are the profile fields whose changes are recorded.
+var memberHistoryFields = []string{
+	"first_name", "last_name", "phone", "email", "address", "next_of_kin_name", "next_of_kin_phone",
+}
+
+func (h *Handlers) UpdateProfile(w http.ResponseWriter, r *http.Request) {
+	var input dto.UpdateProfileInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	result, err := h.factory.Services.Member.UpdateProfile(r.Context(), input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	status := http.StatusOK
+	if result.ChangeRequest != nil {
+		status = http.StatusAccepted
+	}
+
+	h.writeJSON(w, status, result, nil)
+}
+
+func (h *Handlers) ListMyChangeRequests(w http.ResponseWriter, r *http.Request) {
+	result, err := h.factory.Services.Member.ListMyChangeRequests(r.Context(), h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) ListChangeRequests(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberReadALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	var status *string
+	if v := r.URL.Query().Get("status"); v != "" {
+		switch v {
+		case repository.MemberChangeRequestStatusPending, repository.MemberChangeRequestStatusApproved,
+			repository.MemberChangeRequestStatusRejected:
+			status = &v
+		default:
+			h.errorResponse(w, r, &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "status must be one of PENDING, APPROVED, REJECTED",
+			})
+			return
+		}
+	}
+
+	result, err := h.factory.Services.Member.ListChangeRequests(r.Context(), status, h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+// ApproveChangeRequest takes an optional note in the body.
+func (h *Handlers) ApproveChangeRequest(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	id, ok := h.changeRequestID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.ApproveMemberChangeInput
+	if r.ContentLength != 0 && !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	result, err := h.factory.Services.Member.ApproveChangeRequest(r.Context(), id, input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) RejectChangeRequest(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	id, ok := h.changeRequestID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.RejectMemberChangeInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	result, err := h.factory.Services.Member.RejectChangeRequest(r.Context(), id, input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) MemberHistory(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberReadALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	var field *string
+	if v := r.URL.Query().Get("field"); v != "" {
+		if !lo.Contains(memberHistoryFields, v) {
+			h.errorResponse(w, r, &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "field must be one of " + strings.Join(memberHistoryFields, ", "),
+			})
+			return
+		}
+		field = &v
+	}
+
+	result, err := h.factory.Services.Member.ListFieldChanges(r.Context(), chi.URLParam(r, "slug"), field, h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) changeRequestID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid change request ID",
+		})
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
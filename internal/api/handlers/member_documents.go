@@ -0,0 +1,221 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/members"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// maxDocumentFormSize leaves room for the other form fields and multipart
+// framing around a document of the largest allowed size.
+const maxDocumentFormSize = members.MaxDocumentSize + 1<<20
+
+func (h *Handlers) UploadMyDocument(w http.ResponseWriter, r *http.Request) {
+	docType, file, filename, ok := h.readDocumentUpload(w, r)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	document, err := h.factory.Services.Member.UploadMyDocument(r.Context(), docType, file, filename)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, document, nil)
+}
+
+func (h *Handlers) ListMyDocuments(w http.ResponseWriter, r *http.Request) {
+	result, err := h.factory.Services.Member.ListMyDocuments(r.Context(), h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) DownloadMyDocument(w http.ResponseWriter, r *http.Request) {
+	id, ok := h.documentID(w, r)
+	if !ok {
+		return
+	}
+
+	download, err := h.factory.Services.Member.MyDocumentDownload(r.Context(), id)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, download, nil)
+}
+
+func (h *Handlers) UploadMemberDocument(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	docType, file, filename, ok := h.readDocumentUpload(w, r)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	document, err := h.factory.Services.Member.UploadDocument(r.Context(), chi.URLParam(r, "slug"), docType, file, filename)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, document, nil)
+}
+
+func (h *Handlers) ListMemberDocuments(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberReadALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	result, err := h.factory.Services.Member.ListDocuments(r.Context(), chi.URLParam(r, "slug"), h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) DownloadMemberDocument(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberReadALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	id, ok := h.documentID(w, r)
+	if !ok {
+		return
+	}
+
+	download, err := h.factory.Services.Member.DocumentDownload(r.Context(), chi.URLParam(r, "slug"), id)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, download, nil)
+}
+
+// VerifyMemberDocument takes an optional note in the body.
+func (h *Handlers) VerifyMemberDocument(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	id, ok := h.documentID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.VerifyMemberDocumentInput
+	if r.ContentLength != 0 && !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	document, err := h.factory.Services.Member.VerifyDocument(r.Context(), chi.URLParam(r, "slug"), id, input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, document, nil)
+}
+
+func (h *Handlers) RejectMemberDocument(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permissions) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	id, ok := h.documentID(w, r)
+	if !ok {
+		return
+	}
+
+	var input dto.RejectMemberDocumentInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	document, err := h.factory.Services.Member.RejectDocument(r.Context(), chi.URLParam(r, "slug"), id, input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, document, nil)
+}
+
+// readDocumentUpload returns the "type" field and "file" of a multipart
+// document upload. It writes the error response itself when it fails.
+func (h *Handlers) readDocumentUpload(w http.ResponseWriter, r *http.Request) (dto.MemberDocumentType, io.ReadCloser, string, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentFormSize)
+
+	if err := r.ParseMultipartForm(maxDocumentFormSize); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.errorResponse(w, r, &svc.APIError{
+				Status:  http.StatusRequestEntityTooLarge,
+				Message: "the upload is too large",
+			})
+			return "", nil, "", false
+		}
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "documents are uploaded as multipart/form-data with a type and a file",
+		})
+		return "", nil, "", false
+	}
+
+	file, header, err := r.FormFile("file")
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "file is required",
+			Errors:  map[string]string{"file": "file is required"},
+		})
+		return "", nil, "", false
+	}
+
+	docType := dto.MemberDocumentType(strings.ToLower(strings.TrimSpace(r.FormValue("type"))))
+	return docType, file, header.Filename, true
+}
+
+func (h *Handlers) documentID(w http.ResponseWriter, r *http.Request) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid document ID",
+		})
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
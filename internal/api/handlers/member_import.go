@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+)
+
+// ImportMembers takes a CSV of members, see readCSVUpload. With
+// ?dry_run=true it only validates the file. A report with errors is answered
+// with 422.
+func (h *Handlers) ImportMembers(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permissions)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	upload, dryRun, ok := h.readCSVUpload(w, r)
+	if !ok {
+		return
+	}
+	defer upload.Close()
+
+	report, err := h.factory.Services.Member.ImportMembers(r.Context(), upload, dryRun)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, importStatus(len(report.Errors), dryRun), report, nil)
+}
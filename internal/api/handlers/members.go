@@ -32,6 +32,29 @@ func (h *Handlers) CreateMember(w http.ResponseWriter, r *http.Request) {
 	h.writeJSON(w, http.StatusCreated, createdMember, http.Header{})
 }
 
+func (h *Handlers) ListMembers(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberReadALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permissions)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	filters, err := h.parseMemberFilters(r)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	members, err := h.factory.Services.Member.List(r.Context(), filters, h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, members, nil)
+}
+
 func (h *Handlers) MemberBySlug(w http.ResponseWriter, r *http.Request) {
 	slug := chi.URLParam(r, "slug")
 	member, err := h.factory.Services.Member.GetBySlug(r.Context(), slug)
@@ -42,3 +65,77 @@ func (h *Handlers) MemberBySlug(w http.ResponseWriter, r *http.Request) {
 
 	h.writeJSON(w, http.StatusOK, member, nil)
 }
+
+func (h *Handlers) ResendMemberInvite(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permissions)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	slug := chi.URLParam(r, "slug")
+	member, err := h.factory.Services.Member.ResendInvite(r.Context(), slug)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, member, nil)
+}
+
+func (h *Handlers) ResendSetPasswordCode(w http.ResponseWriter, r *http.Request) {
+	var input dto.ResendInviteInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	err := h.factory.Services.Member.ResendInviteByEmail(r.Context(), &input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "If this email has a pending invitation, a new code has been sent.",
+	}, nil)
+}
+
+func (h *Handlers) ChangeMemberStatus(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permissions)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	var input dto.UpdateMemberStatusInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	member, err := h.factory.Services.Member.ChangeStatus(r.Context(), chi.URLParam(r, "slug"), input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, member, nil)
+}
+
+func (h *Handlers) MemberStatusHistory(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberReadALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permissions)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	history, err := h.factory.Services.Member.ListStatusChanges(r.Context(), chi.URLParam(r, "slug"), h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, history, nil)
+}
@@ -0,0 +1,50 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/pkg/token"
+)
+
+func (h *Handlers) LoginMFA(w http.ResponseWriter, r *http.Request) {
+	var input dto.LoginMFAInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	authResponse, refreshToken, err := h.factory.Services.User.LoginMFA(r.Context(), &input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	setRefreshCookie(w, refreshToken, token.RefreshTokenExpirationTime, h.config.IsDev)
+	h.writeJSON(w, http.StatusOK, authResponse, nil)
+}
+
+func (h *Handlers) EnrollMFA(w http.ResponseWriter, r *http.Request) {
+	enrollment, err := h.factory.Services.User.EnrollMFA(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, enrollment, nil)
+}
+
+func (h *Handlers) VerifyMFA(w http.ResponseWriter, r *http.Request) {
+	var input dto.MFAVerifyInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	resp, refreshToken, err := h.factory.Services.User.VerifyMFA(r.Context(), &input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	setRefreshCookie(w, refreshToken, token.RefreshTokenExpirationTime, h.config.IsDev)
+	h.writeJSON(w, http.StatusOK, resp, nil)
+}
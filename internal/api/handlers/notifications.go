@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// streamKeepAlive keeps idle streams from being closed by proxies.
+const streamKeepAlive = time.Second * 25
+
+func (h *Handlers) ListNotifications(w http.ResponseWriter, r *http.Request) {
+	filters, err := h.parseNotificationFilters(r)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	result, err := h.factory.Services.Notifications.List(r.Context(), &filters, h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) UnreadNotificationCount(w http.ResponseWriter, r *http.Request) {
+	count, err := h.factory.Services.Notifications.UnreadCount(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, count, nil)
+}
+
+func (h *Handlers) MarkNotificationRead(w http.ResponseWriter, r *http.Request) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid notification ID",
+		})
+		return
+	}
+
+	notification, err := h.factory.Services.Notifications.MarkRead(r.Context(), id)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, notification, nil)
+}
+
+func (h *Handlers) MarkAllNotificationsRead(w http.ResponseWriter, r *http.Request) {
+	result, err := h.factory.Services.Notifications.MarkAllRead(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+// NotificationStream sends the user's new notifications, and the pending
+// transactions queue for admins, as Server-Sent Events. Clients should fetch
+// GET /notifications after (re)connecting, since events sent while they were
+// away are not replayed.
+func (h *Handlers) NotificationStream(w http.ResponseWriter, r *http.Request) {
+	subscription, err := h.factory.Services.Notifications.Subscribe(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+	defer subscription.Close()
+
+	rc := http.NewResponseController(w)
+	// The server's write timeout is meant for regular requests, not streams.
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	fmt.Fprint(w, "retry: 5000\n\n")
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	keepAlive := time.NewTicker(streamKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-keepAlive.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case event, ok := <-subscription.Events():
+			if !ok {
+				return
+			}
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, event.Data)
+		}
+
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+func (h *Handlers) GetNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	preferences, err := h.factory.Services.Notifications.GetPreferences(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, preferences, nil)
+}
+
+func (h *Handlers) UpdateNotificationPreferences(w http.ResponseWriter, r *http.Request) {
+	var input dto.UpdateNotificationPreferencesInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	preferences, err := h.factory.Services.Notifications.UpdatePreferences(r.Context(), &input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, preferences, nil)
+}
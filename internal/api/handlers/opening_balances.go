@@ -0,0 +1,34 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+)
+
+// ImportOpeningBalances takes a CSV of the balances members held before
+// go-live, see readCSVUpload. With ?dry_run=true it only validates the file.
+func (h *Handlers) ImportOpeningBalances(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permissions)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	upload, dryRun, ok := h.readCSVUpload(w, r)
+	if !ok {
+		return
+	}
+	defer upload.Close()
+
+	report, err := h.factory.Services.Transactions.ImportOpeningBalances(r.Context(), upload, dryRun)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, importStatus(len(report.Errors), dryRun), report, nil)
+}
@@ -0,0 +1,44 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/go-chi/chi/v5"
+)
+
+func (h *Handlers) MemberSettlementStatement(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberReadALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permissions)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	statement, err := h.factory.Services.Transactions.SettlementStatement(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, statement, nil)
+}
+
+func (h *Handlers) SettleMemberExit(w http.ResponseWriter, r *http.Request) {
+	permissions := []constants.UserPermissions{constants.MemberWriteALL}
+	hasPermission := users.HasAdminPermissions(r.Context(), permissions)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permissions))
+		return
+	}
+
+	settlement, err := h.factory.Services.Transactions.SettleExit(r.Context(), chi.URLParam(r, "slug"))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, settlement, nil)
+}
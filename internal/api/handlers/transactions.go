@@ -94,3 +94,13 @@ func (h *Handlers) ListPendingTransactions(w http.ResponseWriter, r *http.Reques
 		NextCursor: result.NextCursor,
 	}, nil)
 }
+
+func (h *Handlers) MemberSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := h.factory.Services.Transactions.MemberSummary(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, summary, nil)
+}
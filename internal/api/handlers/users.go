@@ -3,9 +3,13 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
 	"github.com/Jidetireni/ara-cooperative/internal/dto"
 	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
 	"github.com/Jidetireni/ara-cooperative/pkg/token"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
 )
 
 func (h *Handlers) SetPassword(w http.ResponseWriter, r *http.Request) {
@@ -33,6 +37,7 @@ func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 	if !h.decodeAndValidate(w, r, &input) {
 		return
 	}
+	input.IP = h.clientIP(r)
 
 	authResponse, refreshToken, err := h.factory.Services.User.Login(r.Context(), &input)
 	if err != nil {
@@ -40,7 +45,10 @@ func (h *Handlers) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	setRefreshCookie(w, refreshToken, token.RefreshTokenExpirationTime, h.config.IsDev)
+	// No session is issued until the MFA challenge is answered.
+	if refreshToken != "" {
+		setRefreshCookie(w, refreshToken, token.RefreshTokenExpirationTime, h.config.IsDev)
+	}
 	err = h.writeJSON(w, http.StatusOK, authResponse, nil)
 	if err != nil {
 		h.errorResponse(w, r, err)
@@ -71,3 +79,99 @@ func (h *Handlers) RefreshToken(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 }
+
+func (h *Handlers) ForgotPassword(w http.ResponseWriter, r *http.Request) {
+	var input dto.ForgotPasswordInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	err := h.factory.Services.User.ForgotPassword(r.Context(), &input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, map[string]string{
+		"message": "If an account exists for this email, a password reset link has been sent.",
+	}, nil)
+}
+
+func (h *Handlers) ResetPassword(w http.ResponseWriter, r *http.Request) {
+	var input dto.ResetPasswordInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	err := h.factory.Services.User.ResetPassword(r.Context(), &input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	clearRefreshCookie(w, h.config.IsDev)
+	h.writeJSON(w, http.StatusOK, map[string]bool{"success": true}, nil)
+}
+
+func (h *Handlers) Logout(w http.ResponseWriter, r *http.Request) {
+	err := h.factory.Services.User.Logout(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	clearRefreshCookie(w, h.config.IsDev)
+	h.writeJSON(w, http.StatusOK, map[string]bool{"success": true}, nil)
+}
+
+func (h *Handlers) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	permission := []constants.UserPermissions{constants.RoleAssign}
+	hasPermission := users.HasAdminPermissions(r.Context(), permission)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid user ID",
+		})
+		return
+	}
+
+	err = h.factory.Services.User.RevokeSessions(r.Context(), userID)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]bool{"success": true}, nil)
+}
+
+func (h *Handlers) UnlockUser(w http.ResponseWriter, r *http.Request) {
+	permission := []constants.UserPermissions{constants.RoleAssign}
+	hasPermission := users.HasAdminPermissions(r.Context(), permission)
+	if !hasPermission {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return
+	}
+
+	userID, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "Invalid user ID",
+		})
+		return
+	}
+
+	err = h.factory.Services.User.UnlockUser(r.Context(), userID)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]bool{"success": true}, nil)
+}
@@ -0,0 +1,212 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+)
+
+// canManageWebhooks writes a 403 unless the admin may manage webhooks.
+func (h *Handlers) canManageWebhooks(w http.ResponseWriter, r *http.Request) bool {
+	permission := []constants.UserPermissions{constants.MemberWriteALL}
+	if !users.HasAdminPermissions(r.Context(), permission) {
+		h.errorResponse(w, r, svc.AdminForbiddenError(permission))
+		return false
+	}
+
+	return true
+}
+
+// webhookID parses the id URL parameter, writing a 400 when it is not a UUID.
+func (h *Handlers) webhookID(w http.ResponseWriter, r *http.Request, message string) (uuid.UUID, bool) {
+	id, err := uuid.Parse(chi.URLParam(r, "id"))
+	if err != nil {
+		h.errorResponse(w, r, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: message,
+		})
+		return uuid.Nil, false
+	}
+
+	return id, true
+}
+
+func (h *Handlers) ListWebhookEventTypes(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, webhooks.EventTypes, nil)
+}
+
+func (h *Handlers) ListWebhookEndpoints(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	endpoints, err := h.factory.Services.Webhooks.ListEndpoints(r.Context())
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, endpoints, nil)
+}
+
+func (h *Handlers) CreateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	var input dto.CreateWebhookEndpointInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	endpoint, err := h.factory.Services.Webhooks.CreateEndpoint(r.Context(), &input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusCreated, endpoint, nil)
+}
+
+func (h *Handlers) GetWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	id, ok := h.webhookID(w, r, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.factory.Services.Webhooks.GetEndpoint(r.Context(), id)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, endpoint, nil)
+}
+
+func (h *Handlers) UpdateWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	id, ok := h.webhookID(w, r, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	var input dto.UpdateWebhookEndpointInput
+	if !h.decodeAndValidate(w, r, &input) {
+		return
+	}
+
+	endpoint, err := h.factory.Services.Webhooks.UpdateEndpoint(r.Context(), id, &input)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, endpoint, nil)
+}
+
+func (h *Handlers) RotateWebhookSecret(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	id, ok := h.webhookID(w, r, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	endpoint, err := h.factory.Services.Webhooks.RotateSecret(r.Context(), id)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, endpoint, nil)
+}
+
+func (h *Handlers) DeleteWebhookEndpoint(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	id, ok := h.webhookID(w, r, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	if err := h.factory.Services.Webhooks.DeleteEndpoint(r.Context(), id); err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, map[string]bool{"success": true}, nil)
+}
+
+func (h *Handlers) ListWebhookDeliveries(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	id, ok := h.webhookID(w, r, "Invalid webhook ID")
+	if !ok {
+		return
+	}
+
+	var status *string
+	if v := r.URL.Query().Get("status"); v != "" {
+		switch v {
+		case repository.WebhookDeliveryStatusPending, repository.WebhookDeliveryStatusDelivered, repository.WebhookDeliveryStatusDead:
+			status = &v
+		default:
+			h.errorResponse(w, r, &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "status must be one of PENDING, DELIVERED, DEAD",
+			})
+			return
+		}
+	}
+
+	result, err := h.factory.Services.Webhooks.ListDeliveries(r.Context(), id, status, h.getPaginationParams(r))
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, result, nil)
+}
+
+func (h *Handlers) RedeliverWebhook(w http.ResponseWriter, r *http.Request) {
+	if !h.canManageWebhooks(w, r) {
+		return
+	}
+
+	id, ok := h.webhookID(w, r, "Invalid delivery ID")
+	if !ok {
+		return
+	}
+
+	delivery, err := h.factory.Services.Webhooks.Redeliver(r.Context(), id)
+	if err != nil {
+		h.errorResponse(w, r, err)
+		return
+	}
+
+	h.writeJSON(w, http.StatusAccepted, delivery, nil)
+}
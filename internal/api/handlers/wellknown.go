@@ -0,0 +1,17 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+)
+
+// JWKS serves the public signing keys as a standard JSON Web Key Set, so it is
+// written without the usual data envelope.
+func (h *Handlers) JWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.WriteHeader(http.StatusOK)
+	if err := json.NewEncoder(w).Encode(h.factory.Pkgs.JWTTok.JWKS()); err != nil {
+		h.logError(r, err)
+	}
+}
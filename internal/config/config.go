@@ -2,7 +2,11 @@ package config
 
 import (
 	"log"
+	"net"
 	"os"
+	"slices"
+	"strconv"
+	"strings"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +17,9 @@ type ServerConfig struct {
 	FEURL            string
 	RootUserEmail    string
 	RootUserPassword string
+	// TrustedProxies are the reverse proxies whose X-Forwarded-For and
+	// X-Real-IP headers are believed when working out a client's address.
+	TrustedProxies []*net.IPNet
 }
 
 type DataBaseConfig struct {
@@ -21,11 +28,119 @@ type DataBaseConfig struct {
 }
 
 type AuthConfig struct {
+	// JWTSecret signs tokens with HS256 while no key from JWTKeysFile is
+	// active. It may be left empty when JWTKeysFile is set.
 	JWTSecret string
+	// JWTKeysFile points to a JSON manifest of asymmetric signing keys.
+	JWTKeysFile string
+	// MFAEncryptionKey encrypts TOTP secrets at rest. It is required outside
+	// development, so that rotating JWTSecret after a leak does not make
+	// enrolled secrets unreadable.
+	MFAEncryptionKey string
+	// WebhookEncryptionKey encrypts webhook signing secrets at rest. Like
+	// MFAEncryptionKey it is required outside development.
+	WebhookEncryptionKey string
 }
 
+// EmailTransport selects where outgoing email goes.
+type EmailTransport string
+
+const (
+	EmailTransportSMTP    EmailTransport = "smtp"
+	EmailTransportMaildir EmailTransport = "maildir"
+	// EmailTransportCapture keeps messages in memory, see GET /dev/mailbox.
+	EmailTransportCapture EmailTransport = "capture"
+)
+
+// SMTPTLSMode is how the SMTP connection is secured.
+type SMTPTLSMode string
+
+const (
+	SMTPTLSModeStartTLS SMTPTLSMode = "starttls"
+	SMTPTLSModeTLS      SMTPTLSMode = "tls"
+	// SMTPTLSModeNone is only meant for local catch-all servers such as MailHog.
+	SMTPTLSModeNone SMTPTLSMode = "none"
+)
+
 type EmailConfig struct {
-	Password string
+	Transport   EmailTransport
+	FromAddress string
+	FromName    string
+
+	SMTPHost     string
+	SMTPPort     int
+	SMTPUsername string
+	Password     string
+	SMTPTLSMode  SMTPTLSMode
+
+	MaildirPath string
+}
+
+// SMSProvider selects where outgoing text messages go.
+type SMSProvider string
+
+const (
+	SMSProviderTermii SMSProvider = "termii"
+	// SMSProviderLog appends messages to a local file instead of sending them.
+	SMSProviderLog SMSProvider = "log"
+)
+
+type SMSConfig struct {
+	Provider SMSProvider
+	SenderID string
+	APIKey   string
+	BaseURL  string
+	// Channel is the Termii route; "dnd" also reaches numbers on the
+	// do-not-disturb list, which transactional messages need.
+	Channel string
+	LogPath string
+}
+
+// StorageBackend selects where uploaded files are kept.
+type StorageBackend string
+
+const (
+	StorageBackendLocal StorageBackend = "local"
+	// StorageBackendS3 works with AWS S3 and S3-compatible stores such as
+	// MinIO.
+	StorageBackendS3 StorageBackend = "s3"
+)
+
+type StorageConfig struct {
+	Backend StorageBackend
+	// SigningKey signs download URLs of the local backend, which needs it
+	// outside development.
+	SigningKey string
+
+	LocalDir string
+	// PublicURL is where the API is reached, the base of local download URLs.
+	PublicURL string
+
+	S3Endpoint  string
+	S3Region    string
+	S3Bucket    string
+	S3AccessKey string
+	S3SecretKey string
+	// S3PathStyle addresses objects as endpoint/bucket/key, which MinIO and
+	// most local stand-ins need.
+	S3PathStyle bool
+}
+
+// KYCDocumentTypes are the member document types KYC_REQUIRED_DOCUMENTS may
+// list, as accepted by the document upload endpoints.
+var KYCDocumentTypes = []string{"membership_form", "id_card", "passport_photo", "utility_bill"}
+
+// MembersConfig controls how member numbers, which double as slugs, look.
+type MembersConfig struct {
+	NumberPrefix  string
+	NumberPadding int
+	// NumberCheckDigit appends a Luhn check digit to the padded number.
+	NumberCheckDigit bool
+	// ActivationRequiresKYC keeps members pending until every document in
+	// KYCRequiredDocuments has been verified, even once they have paid their
+	// registration fee.
+	ActivationRequiresKYC bool
+	KYCRequiredDocuments  []string
 }
 
 type RedisConfig struct {
@@ -38,6 +153,9 @@ type Config struct {
 	Redis    RedisConfig
 	Auth     AuthConfig
 	Email    EmailConfig
+	SMS      SMSConfig
+	Members  MembersConfig
+	Storage  StorageConfig
 	IsDev    bool
 }
 
@@ -52,10 +170,6 @@ func validateEnv() {
 		// database
 		"DB_URL",
 		"DB_TYPE",
-		// auth
-		"JWT_SECRET",
-		// email
-		"EMAIL_PASSWORD",
 		// redis
 		"REDIS_URI",
 	}
@@ -65,6 +179,237 @@ func validateEnv() {
 		}
 	}
 
+	// Tokens are signed with JWT_SECRET until an asymmetric key from
+	// JWT_KEYS_FILE is active, so one of the two must be set.
+	if os.Getenv("JWT_SECRET") == "" && os.Getenv("JWT_KEYS_FILE") == "" {
+		log.Fatalf("Environment variable JWT_SECRET or JWT_KEYS_FILE must be set")
+	}
+
+}
+
+func newAuthConfig(isDev bool) AuthConfig {
+	cfg := AuthConfig{
+		JWTSecret:   os.Getenv("JWT_SECRET"),
+		JWTKeysFile: os.Getenv("JWT_KEYS_FILE"),
+	}
+	cfg.MFAEncryptionKey = requiredSecret("MFA_ENCRYPTION_KEY", "mfa:"+cfg.JWTSecret, isDev)
+	cfg.WebhookEncryptionKey = requiredSecret("WEBHOOK_ENCRYPTION_KEY", "webhooks:"+cfg.JWTSecret, isDev)
+
+	return cfg
+}
+
+// requiredSecret reads a key that must be set on its own outside development.
+// In development it defaults to devDefault so a fresh checkout runs as is.
+func requiredSecret(env, devDefault string, isDev bool) string {
+	if v := os.Getenv(env); v != "" {
+		return v
+	}
+	if !isDev {
+		log.Fatalf("Environment variable %s is required outside development", env)
+	}
+
+	return devDefault
+}
+
+func newEmailConfig(isDev bool) EmailConfig {
+	transport := EmailTransport(os.Getenv("EMAIL_TRANSPORT"))
+	if transport == "" {
+		transport = EmailTransportSMTP
+		if isDev {
+			transport = EmailTransportCapture
+		}
+	}
+
+	cfg := EmailConfig{
+		Transport:    transport,
+		FromAddress:  os.Getenv("EMAIL_FROM"),
+		FromName:     getEnvOrDefault("EMAIL_FROM_NAME", "ARA Cooperative"),
+		SMTPHost:     os.Getenv("SMTP_HOST"),
+		SMTPUsername: os.Getenv("SMTP_USERNAME"),
+		Password:     os.Getenv("EMAIL_PASSWORD"),
+		SMTPTLSMode:  SMTPTLSMode(getEnvOrDefault("SMTP_TLS_MODE", string(SMTPTLSModeStartTLS))),
+		MaildirPath:  getEnvOrDefault("EMAIL_MAILDIR", "./tmp/mail"),
+	}
+
+	port, err := strconv.Atoi(getEnvOrDefault("SMTP_PORT", "587"))
+	if err != nil {
+		log.Fatalf("SMTP_PORT must be a number: %v", err)
+	}
+	cfg.SMTPPort = port
+
+	if cfg.FromAddress == "" {
+		cfg.FromAddress = "no-reply@localhost"
+	}
+	if cfg.SMTPUsername == "" {
+		cfg.SMTPUsername = cfg.FromAddress
+	}
+
+	switch transport {
+	case EmailTransportSMTP:
+		for _, env := range []string{"SMTP_HOST", "EMAIL_FROM", "EMAIL_PASSWORD"} {
+			if os.Getenv(env) == "" {
+				log.Fatalf("Environment variable %s is required when EMAIL_TRANSPORT is smtp", env)
+			}
+		}
+		switch cfg.SMTPTLSMode {
+		case SMTPTLSModeStartTLS, SMTPTLSModeTLS, SMTPTLSModeNone:
+		default:
+			log.Fatalf("SMTP_TLS_MODE must be one of starttls, tls, none")
+		}
+	case EmailTransportMaildir, EmailTransportCapture:
+	default:
+		log.Fatalf("EMAIL_TRANSPORT must be one of smtp, maildir, capture")
+	}
+
+	return cfg
+}
+
+func newSMSConfig(isDev bool) SMSConfig {
+	cfg := SMSConfig{
+		Provider: SMSProvider(os.Getenv("SMS_PROVIDER")),
+		SenderID: getEnvOrDefault("SMS_SENDER_ID", "ARA Coop"),
+		APIKey:   os.Getenv("SMS_API_KEY"),
+		BaseURL:  getEnvOrDefault("SMS_BASE_URL", "https://api.ng.termii.com"),
+		Channel:  getEnvOrDefault("SMS_CHANNEL", "dnd"),
+		LogPath:  getEnvOrDefault("SMS_LOG_PATH", "./tmp/sms.log"),
+	}
+
+	if cfg.Provider == "" {
+		cfg.Provider = SMSProviderTermii
+		if isDev && cfg.APIKey == "" {
+			cfg.Provider = SMSProviderLog
+		}
+	}
+
+	switch cfg.Provider {
+	case SMSProviderTermii:
+		if cfg.APIKey == "" {
+			log.Fatalf("Environment variable SMS_API_KEY is required when SMS_PROVIDER is termii")
+		}
+	case SMSProviderLog:
+		// The log provider writes every code in plaintext and texts nobody.
+		if !isDev {
+			log.Fatalf("SMS_PROVIDER=log is only allowed in development")
+		}
+	default:
+		log.Fatalf("SMS_PROVIDER must be one of termii, log")
+	}
+
+	return cfg
+}
+
+func newMembersConfig() MembersConfig {
+	padding, err := strconv.Atoi(getEnvOrDefault("MEMBER_NUMBER_PADDING", "6"))
+	if err != nil || padding < 1 {
+		log.Fatalf("MEMBER_NUMBER_PADDING must be a positive number")
+	}
+
+	checkDigit, err := strconv.ParseBool(getEnvOrDefault("MEMBER_NUMBER_CHECK_DIGIT", "false"))
+	if err != nil {
+		log.Fatalf("MEMBER_NUMBER_CHECK_DIGIT must be true or false: %v", err)
+	}
+
+	requiresKYC, err := strconv.ParseBool(getEnvOrDefault("MEMBER_ACTIVATION_REQUIRES_KYC", "false"))
+	if err != nil {
+		log.Fatalf("MEMBER_ACTIVATION_REQUIRES_KYC must be true or false: %v", err)
+	}
+
+	var kycDocuments []string
+	for _, docType := range strings.Split(getEnvOrDefault("KYC_REQUIRED_DOCUMENTS", strings.Join(KYCDocumentTypes, ",")), ",") {
+		docType = strings.ToLower(strings.TrimSpace(docType))
+		if docType == "" {
+			continue
+		}
+		// An unknown type could never be verified, so no member would ever
+		// be activated.
+		if !slices.Contains(KYCDocumentTypes, docType) {
+			log.Fatalf("KYC_REQUIRED_DOCUMENTS: unknown document type %q, must be one of %s", docType, strings.Join(KYCDocumentTypes, ", "))
+		}
+		kycDocuments = append(kycDocuments, docType)
+	}
+
+	return MembersConfig{
+		NumberPrefix:          getEnvOrDefault("MEMBER_NUMBER_PREFIX", "ara"),
+		NumberPadding:         padding,
+		NumberCheckDigit:      checkDigit,
+		ActivationRequiresKYC: requiresKYC,
+		KYCRequiredDocuments:  kycDocuments,
+	}
+}
+
+func newStorageConfig(isDev bool) StorageConfig {
+	cfg := StorageConfig{
+		Backend:     StorageBackend(getEnvOrDefault("STORAGE_BACKEND", string(StorageBackendLocal))),
+		LocalDir:    getEnvOrDefault("STORAGE_LOCAL_DIR", "./tmp/storage"),
+		PublicURL:   getEnvOrDefault("STORAGE_PUBLIC_URL", "http://localhost:"+os.Getenv("PORT")),
+		S3Endpoint:  getEnvOrDefault("S3_ENDPOINT", "https://s3.amazonaws.com"),
+		S3Region:    getEnvOrDefault("S3_REGION", "us-east-1"),
+		S3Bucket:    os.Getenv("S3_BUCKET"),
+		S3AccessKey: os.Getenv("S3_ACCESS_KEY"),
+		S3SecretKey: os.Getenv("S3_SECRET_KEY"),
+	}
+
+	pathStyle, err := strconv.ParseBool(getEnvOrDefault("S3_PATH_STYLE", "false"))
+	if err != nil {
+		log.Fatalf("S3_PATH_STYLE must be true or false: %v", err)
+	}
+	cfg.S3PathStyle = pathStyle
+
+	switch cfg.Backend {
+	case StorageBackendS3:
+		for _, env := range []string{"S3_BUCKET", "S3_ACCESS_KEY", "S3_SECRET_KEY"} {
+			if os.Getenv(env) == "" {
+				log.Fatalf("Environment variable %s is required when STORAGE_BACKEND is s3", env)
+			}
+		}
+	case StorageBackendLocal:
+		cfg.SigningKey = requiredSecret("STORAGE_SIGNING_KEY", "storage:"+os.Getenv("JWT_SECRET"), isDev)
+	default:
+		log.Fatalf("STORAGE_BACKEND must be one of local, s3")
+	}
+
+	return cfg
+}
+
+// newTrustedProxies parses TRUSTED_PROXIES, a comma-separated list of
+// addresses and CIDR ranges.
+func newTrustedProxies() []*net.IPNet {
+	var proxies []*net.IPNet
+	for _, entry := range strings.Split(os.Getenv("TRUSTED_PROXIES"), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				log.Fatalf("TRUSTED_PROXIES: invalid address %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			proxies = append(proxies, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, network, err := net.ParseCIDR(entry)
+		if err != nil {
+			log.Fatalf("TRUSTED_PROXIES: invalid range %q: %v", entry, err)
+		}
+		proxies = append(proxies, network)
+	}
+
+	return proxies
+}
+
+func getEnvOrDefault(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+
+	return fallback
 }
 
 func New() *Config {
@@ -74,6 +419,8 @@ func New() *Config {
 
 	validateEnv()
 
+	isDev := os.Getenv("ENV") == "development"
+
 	return &Config{
 		Server: ServerConfig{
 			Env:              os.Getenv("ENV"),
@@ -81,21 +428,21 @@ func New() *Config {
 			FEURL:            os.Getenv("FE_URL"),
 			RootUserEmail:    os.Getenv("ROOT_USER_EMAIL"),
 			RootUserPassword: os.Getenv("ROOT_USER_PASSWORD"),
+			TrustedProxies:   newTrustedProxies(),
 		},
 		Database: DataBaseConfig{
 			URL:  os.Getenv("DB_URL"),
 			Type: os.Getenv("DB_TYPE"),
 		},
-		Auth: AuthConfig{
-			JWTSecret: os.Getenv("JWT_SECRET"),
-		},
-		Email: EmailConfig{
-			Password: os.Getenv("EMAIL_PASSWORD"),
-		},
+		Auth:    newAuthConfig(isDev),
+		Email:   newEmailConfig(isDev),
+		SMS:     newSMSConfig(isDev),
+		Members: newMembersConfig(),
+		Storage: newStorageConfig(isDev),
 		Redis: RedisConfig{
 			URI: os.Getenv("REDIS_URI"),
 		},
 
-		IsDev: os.Getenv("ENV") == "development",
+		IsDev: isDev,
 	}
 }
@@ -0,0 +1,76 @@
+package constants
+
+import (
+	"slices"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+)
+
+// MemberStatusTransitions lists the states a member can be moved to from each
+// state. Exited is final.
+var MemberStatusTransitions = map[string][]string{
+	repository.MemberStatusPending: {
+		repository.MemberStatusActive,
+		repository.MemberStatusExited,
+	},
+	repository.MemberStatusActive: {
+		repository.MemberStatusSuspended,
+		repository.MemberStatusDormant,
+		repository.MemberStatusExiting,
+	},
+	repository.MemberStatusSuspended: {
+		repository.MemberStatusActive,
+		repository.MemberStatusExiting,
+	},
+	repository.MemberStatusDormant: {
+		repository.MemberStatusActive,
+		repository.MemberStatusSuspended,
+		repository.MemberStatusExiting,
+	},
+	repository.MemberStatusExiting: {
+		repository.MemberStatusActive,
+		repository.MemberStatusExited,
+	},
+}
+
+func CanTransitionMember(from, to string) bool {
+	return slices.Contains(MemberStatusTransitions[from], to)
+}
+
+// MemberAction is something a member does with their account that depends on
+// their lifecycle state.
+type MemberAction string
+
+const (
+	MemberActionPayRegistrationFee MemberAction = "pay_registration_fee"
+	MemberActionDeposit            MemberAction = "deposit"
+	MemberActionBuyShares          MemberAction = "buy_shares"
+	MemberActionPayFine            MemberAction = "pay_fine"
+)
+
+// MemberStatusActions are the actions allowed in each state. Suspended and
+// exiting members can still clear their fines.
+var MemberStatusActions = map[string][]MemberAction{
+	repository.MemberStatusPending: {
+		MemberActionPayRegistrationFee,
+	},
+	repository.MemberStatusActive: {
+		MemberActionDeposit,
+		MemberActionBuyShares,
+		MemberActionPayFine,
+	},
+	repository.MemberStatusSuspended: {
+		MemberActionPayFine,
+	},
+	repository.MemberStatusDormant: {
+		MemberActionDeposit,
+		MemberActionPayFine,
+	},
+	repository.MemberStatusExiting: {
+		MemberActionPayFine,
+	},
+}
+
+func MemberCan(status string, action MemberAction) bool {
+	return slices.Contains(MemberStatusActions[status], action)
+}
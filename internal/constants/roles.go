@@ -4,6 +4,7 @@ import (
 	"database/sql"
 	_ "embed"
 	"encoding/json"
+	"slices"
 
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
 )
@@ -39,6 +40,24 @@ var RolePermissions = map[string][]UserPermissions{
 	},
 }
 
+// MFAPermissions are the permissions that can only be used from a session
+// opened with a second factor.
+var MFAPermissions = []UserPermissions{
+	LoanApprove,
+	RoleAssign,
+}
+
+// RequiresMFA reports whether any of the given permission slugs requires MFA.
+func RequiresMFA(permissions []string) bool {
+	for _, p := range MFAPermissions {
+		if slices.Contains(permissions, string(p)) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type jsonRole struct {
 	Slug        string `json:"slug"`
 	Description string `json:"description"`
@@ -1,6 +1,7 @@
 package dto
 
 import (
+	"encoding/json"
 	"time"
 
 	"github.com/google/uuid"
@@ -40,15 +41,219 @@ type CreateMemberInput struct {
 }
 
 type Member struct {
-	ID             uuid.UUID `json:"id"`
-	FirstName      string    `json:"first_name"`
-	LastName       string    `json:"last_name"`
-	Slug           string    `json:"slug"`
-	Phone          string    `json:"phone"`
-	Address        string    `json:"address"`
-	NextOfKinName  string    `json:"next_of_kin_name"`
-	NextOfKinPhone string    `json:"next_of_kin_phone"`
-	IsActive       bool      `json:"is_active"`
+	ID        uuid.UUID `json:"id"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Slug      string    `json:"slug"`
+	// Email is only filled in the admin member directory.
+	Email            string       `json:"email,omitempty"`
+	Phone            string       `json:"phone"`
+	Address          string       `json:"address"`
+	NextOfKinName    string       `json:"next_of_kin_name"`
+	NextOfKinPhone   string       `json:"next_of_kin_phone"`
+	IsActive         bool         `json:"is_active"`
+	Status           MemberStatus `json:"status"`
+	InvitationStatus string       `json:"invitation_status,omitempty"`
+	ActivatedAt      *time.Time   `json:"activated_at,omitempty"`
+	JoinedAt         time.Time    `json:"joined_at"`
+}
+
+// UpdateProfileInput is a member's edit of their own profile. Omitted fields
+// are left alone. Address and next of kin apply at once; the other fields go
+// to an admin for approval.
+type UpdateProfileInput struct {
+	Address        *string `json:"address" validate:"omitempty,min=1"`
+	NextOfKinName  *string `json:"next_of_kin_name" validate:"omitempty,min=1"`
+	NextOfKinPhone *string `json:"next_of_kin_phone" validate:"omitempty,min=1"`
+	FirstName      *string `json:"first_name" validate:"omitempty,min=1"`
+	LastName       *string `json:"last_name" validate:"omitempty,min=1"`
+	Phone          *string `json:"phone" validate:"omitempty,min=1"`
+	Email          *string `json:"email" validate:"omitempty,email"`
+}
+
+type UpdateProfileResponse struct {
+	Member *Member `json:"member"`
+	// ChangeRequest is set when the update asked for changes that need approval.
+	ChangeRequest *MemberChangeRequest `json:"change_request,omitempty"`
+}
+
+// MemberChanges are the requested new values of the fields that need approval.
+type MemberChanges struct {
+	FirstName *string `json:"first_name,omitempty"`
+	LastName  *string `json:"last_name,omitempty"`
+	Phone     *string `json:"phone,omitempty"`
+	Email     *string `json:"email,omitempty"`
+}
+
+type MemberChangeRequest struct {
+	ID          uuid.UUID     `json:"id"`
+	MemberID    uuid.UUID     `json:"member_id"`
+	Changes     MemberChanges `json:"changes"`
+	Status      string        `json:"status"`
+	RequestedBy uuid.UUID     `json:"requested_by"`
+	ReviewedBy  *uuid.UUID    `json:"reviewed_by,omitempty"`
+	ReviewNote  *string       `json:"review_note,omitempty"`
+	ReviewedAt  *time.Time    `json:"reviewed_at,omitempty"`
+	CreatedAt   time.Time     `json:"created_at"`
+}
+
+type ApproveMemberChangeInput struct {
+	Note string `json:"note"`
+}
+
+type RejectMemberChangeInput struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+type MemberFieldChange struct {
+	ID              uuid.UUID  `json:"id"`
+	Field           string     `json:"field"`
+	OldValue        *string    `json:"old_value,omitempty"`
+	NewValue        *string    `json:"new_value,omitempty"`
+	ChangedBy       *uuid.UUID `json:"changed_by,omitempty"`
+	ChangeRequestID *uuid.UUID `json:"change_request_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+}
+
+// MemberStatus is where a member is in their membership, from joining to
+// leaving the cooperative.
+type MemberStatus string
+
+const (
+	// MemberStatusPending is a member who has not paid the registration fee yet.
+	MemberStatusPending   MemberStatus = "pending"
+	MemberStatusActive    MemberStatus = "active"
+	MemberStatusSuspended MemberStatus = "suspended"
+	MemberStatusDormant   MemberStatus = "dormant"
+	// MemberStatusExiting is a member leaving whose account is being settled.
+	MemberStatusExiting MemberStatus = "exiting"
+	MemberStatusExited  MemberStatus = "exited"
+)
+
+type UpdateMemberStatusInput struct {
+	Status MemberStatus `json:"status" validate:"required,oneof=pending active suspended dormant exiting exited"`
+	Reason string       `json:"reason" validate:"required"`
+}
+
+type MemberStatusChange struct {
+	ID         uuid.UUID    `json:"id"`
+	FromStatus MemberStatus `json:"from_status"`
+	ToStatus   MemberStatus `json:"to_status"`
+	Reason     string       `json:"reason"`
+	// ChangedBy is nil when the system made the change.
+	ChangedBy *uuid.UUID `json:"changed_by,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// Beneficiary is one of a member's next of kin or a beneficiary of their
+// entitlement on their death, or both. Percentage is their share of the
+// entitlement, zero for a next of kin only.
+type Beneficiary struct {
+	ID           uuid.UUID  `json:"id"`
+	Name         string     `json:"name"`
+	Relationship string     `json:"relationship"`
+	Phone        *string    `json:"phone,omitempty"`
+	Percentage   float64    `json:"percentage"`
+	NextOfKin    bool       `json:"next_of_kin"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    *time.Time `json:"updated_at,omitempty"`
+}
+
+// Beneficiaries are all of a member's beneficiaries. Complete is whether
+// their percentages add up to 100, as the by-laws require.
+type Beneficiaries struct {
+	Items               []Beneficiary `json:"items"`
+	AllocatedPercentage float64       `json:"allocated_percentage"`
+	Complete            bool          `json:"complete"`
+}
+
+type BeneficiaryInput struct {
+	Name         string  `json:"name" validate:"required,max=100"`
+	Relationship string  `json:"relationship" validate:"required,max=50"`
+	Phone        string  `json:"phone"`
+	Percentage   float64 `json:"percentage" validate:"gte=0,lte=100"`
+	NextOfKin    bool    `json:"next_of_kin"`
+}
+
+// UpdateBeneficiaryInput changes the fields it sets and leaves the rest alone.
+type UpdateBeneficiaryInput struct {
+	Name         *string  `json:"name" validate:"omitempty,min=1,max=100"`
+	Relationship *string  `json:"relationship" validate:"omitempty,min=1,max=50"`
+	Phone        *string  `json:"phone"`
+	Percentage   *float64 `json:"percentage" validate:"omitempty,gte=0,lte=100"`
+	NextOfKin    *bool    `json:"next_of_kin"`
+}
+
+// ReplaceBeneficiariesInput is the full list of a member's beneficiaries,
+// whose percentages must add up to 100 unless none has one.
+type ReplaceBeneficiariesInput struct {
+	Beneficiaries []BeneficiaryInput `json:"beneficiaries" validate:"dive"`
+}
+
+// MemberImportReport is the outcome of importing a CSV of members. Rows are
+// numbered as in the file, the header being row 1.
+type MemberImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Created   int              `json:"created"`
+	Errors    []ImportRowError `json:"errors"`
+	Members   []*Member        `json:"members,omitempty"`
+}
+
+// ImportRowError is a problem with one row of an imported CSV file.
+type ImportRowError struct {
+	Row     int    `json:"row"`
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// MemberDocumentType is the kind of KYC document a member uploads.
+type MemberDocumentType string
+
+const (
+	MemberDocumentTypeMembershipForm MemberDocumentType = "membership_form"
+	MemberDocumentTypeIDCard         MemberDocumentType = "id_card"
+	MemberDocumentTypePassportPhoto  MemberDocumentType = "passport_photo"
+	MemberDocumentTypeUtilityBill    MemberDocumentType = "utility_bill"
+)
+
+type MemberDocument struct {
+	ID           uuid.UUID          `json:"id"`
+	MemberID     uuid.UUID          `json:"member_id"`
+	Type         MemberDocumentType `json:"type"`
+	ContentType  string             `json:"content_type"`
+	SizeBytes    int64              `json:"size_bytes"`
+	OriginalName string             `json:"original_name"`
+	Status       string             `json:"status"`
+	UploadedBy   uuid.UUID          `json:"uploaded_by"`
+	ReviewedBy   *uuid.UUID         `json:"reviewed_by,omitempty"`
+	ReviewNote   *string            `json:"review_note,omitempty"`
+	ReviewedAt   *time.Time         `json:"reviewed_at,omitempty"`
+	CreatedAt    time.Time          `json:"created_at"`
+}
+
+type VerifyMemberDocumentInput struct {
+	Note string `json:"note"`
+}
+
+type RejectMemberDocumentInput struct {
+	Reason string `json:"reason" validate:"required"`
+}
+
+// DocumentDownload is a signed link to a document that stops working at
+// ExpiresAt.
+type DocumentDownload struct {
+	URL       string    `json:"url"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type MemberFilter struct {
+	Search *string
+	Status *MemberStatus
+	// JoinedFrom and JoinedTo are inclusive dates.
+	JoinedFrom *time.Time
+	JoinedTo   *time.Time
 }
 
 type User struct {
@@ -65,15 +270,59 @@ type SetPasswordInput struct {
 	Token    string `json:"token" validate:"required"`
 }
 
+type ForgotPasswordInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordInput struct {
+	Email    string `json:"email" validate:"required,email"`
+	Token    string `json:"token" validate:"required"`
+	Password string `json:"password" validate:"required,min=8"`
+}
+
+type ResendInviteInput struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
 type LoginInput struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required"`
+	// IP is the client address, filled in by the handler for throttling.
+	IP string `json:"-"`
 }
 
 type AuthResponse struct {
 	User         *AuthUser `json:"user"`
 	AccessToken  string    `json:"access_token"`
 	RefreshToken string    `json:"refresh_token"`
+
+	// MFARequired is set instead of a session when the user has to complete
+	// the login with a TOTP code, using MFAToken as the challenge.
+	MFARequired bool   `json:"mfa_required,omitempty"`
+	MFAToken    string `json:"mfa_token,omitempty"`
+	// MFAEnrollmentRequired warns users whose permissions require MFA that
+	// they must enroll before using those permissions.
+	MFAEnrollmentRequired bool `json:"mfa_enrollment_required,omitempty"`
+}
+
+type LoginMFAInput struct {
+	MFAToken string `json:"mfa_token" validate:"required"`
+	// Code is either a TOTP code or one of the recovery codes.
+	Code string `json:"code" validate:"required"`
+}
+
+type MFAEnrollResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+}
+
+type MFAVerifyInput struct {
+	Code string `json:"code" validate:"required,len=6,numeric"`
+}
+
+type MFAVerifyResponse struct {
+	*AuthResponse
+	RecoveryCodes []string `json:"recovery_codes"`
 }
 
 type AuthUser struct {
@@ -106,6 +355,8 @@ type QueryOptions struct {
 type ListResponse[T any] struct {
 	Items      []T     `json:"items"`
 	NextCursor *string `json:"next_cursor"`
+	// Total is the number of items across all pages, for lists that count them.
+	Total *int64 `json:"total,omitempty"`
 }
 
 type UpdateTransactionStatusInput struct {
@@ -147,6 +398,65 @@ type SharesTotal struct {
 	Amount int64   `json:"amount"`
 }
 
+// MemberSettlement is what an exiting member is owed: their savings, special
+// deposit and shares at the current unit price, less unpaid fines and loans.
+type MemberSettlement struct {
+	MemberID         uuid.UUID `json:"member_id"`
+	Savings          int64     `json:"savings"`
+	SpecialDeposit   int64     `json:"special_deposit"`
+	ShareUnits       float64   `json:"share_units"`
+	ShareUnitPrice   int64     `json:"share_unit_price"`
+	ShareValue       int64     `json:"share_value"`
+	UnpaidFines      int64     `json:"unpaid_fines"`
+	OutstandingLoans int64     `json:"outstanding_loans"`
+	NetAmount        int64     `json:"net_amount"`
+	// Beneficiaries is how NetAmount is split between the member's
+	// beneficiaries, for claims on a member's death. It is empty when the
+	// member nominated none.
+	Beneficiaries []BeneficiaryAllocation `json:"beneficiaries"`
+	// Settled is false for a statement that has not been posted yet.
+	Settled   bool       `json:"settled"`
+	SettledBy *uuid.UUID `json:"settled_by,omitempty"`
+	SettledAt *time.Time `json:"settled_at,omitempty"`
+}
+
+type BeneficiaryAllocation struct {
+	BeneficiaryID uuid.UUID `json:"beneficiary_id"`
+	Name          string    `json:"name"`
+	Relationship  string    `json:"relationship"`
+	Percentage    float64   `json:"percentage"`
+	Amount        int64     `json:"amount"`
+}
+
+// MemberSummary is a member's dashboard: their confirmed balances, what is
+// still pending or owed, and their latest transactions. Shares are valued at
+// the current unit price.
+type MemberSummary struct {
+	MemberID            uuid.UUID      `json:"member_id"`
+	Status              string         `json:"status"`
+	Savings             int64          `json:"savings"`
+	SpecialDeposit      int64          `json:"special_deposit"`
+	RegistrationFee     int64          `json:"registration_fee"`
+	ShareUnits          float64        `json:"share_units"`
+	ShareUnitPrice      int64          `json:"share_unit_price"`
+	ShareValue          int64          `json:"share_value"`
+	PendingTransactions []Transactions `json:"pending_transactions"`
+	UnpaidFines         []Fine         `json:"unpaid_fines"`
+	UnpaidFinesTotal    int64          `json:"unpaid_fines_total"`
+	RecentActivity      []Transactions `json:"recent_activity"`
+	GeneratedAt         time.Time      `json:"generated_at"`
+}
+
+// OpeningBalanceImportReport is the outcome of importing opening balances.
+// Rows are numbered as in the file, the header being row 1.
+type OpeningBalanceImportReport struct {
+	DryRun    bool             `json:"dry_run"`
+	TotalRows int              `json:"total_rows"`
+	ValidRows int              `json:"valid_rows"`
+	Posted    int              `json:"posted"`
+	Errors    []ImportRowError `json:"errors"`
+}
+
 type TransactionsInput struct {
 	Amount      int64  `json:"amount" validate:"required,gt=0"`
 	Description string `json:"description" validate:"required"`
@@ -161,6 +471,8 @@ type Transactions struct {
 	Reference   string            `json:"reference"`
 	Status      TransactionStatus `json:"status"`
 	Member      Member            `json:"member"`
+	// OpeningBalance marks balances carried over from before go-live.
+	OpeningBalance bool `json:"opening_balance,omitempty"`
 }
 type TransactionStatus struct {
 	ID          uuid.UUID             `json:"id"`
@@ -184,6 +496,8 @@ type Fine struct {
 	Reason      string        `json:"reason"`
 	Deadline    time.Time     `json:"deadline"`
 	Paid        bool          `json:"paid"`
+	// OpeningBalance marks fines carried over from before go-live.
+	OpeningBalance bool `json:"opening_balance,omitempty"`
 }
 
 type TransactionFilters struct {
@@ -196,3 +510,127 @@ type FineFilter struct {
 	MemberID *uuid.UUID `json:"member_id,omitempty"`
 	Paid     *bool      `json:"paid,omitempty"`
 }
+
+type EmailOutboxMessage struct {
+	ID            uuid.UUID  `json:"id"`
+	Recipient     string     `json:"recipient"`
+	Subject       string     `json:"subject"`
+	Template      *string    `json:"template,omitempty"`
+	Status        string     `json:"status"`
+	Attempts      int32      `json:"attempts"`
+	MaxAttempts   int32      `json:"max_attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     *string    `json:"last_error,omitempty"`
+	ReferenceType *string    `json:"reference_type,omitempty"`
+	ReferenceID   *uuid.UUID `json:"reference_id,omitempty"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type Notification struct {
+	ID            uuid.UUID  `json:"id"`
+	Event         string     `json:"event"`
+	Title         string     `json:"title"`
+	Body          string     `json:"body"`
+	ReferenceType *string    `json:"reference_type,omitempty"`
+	ReferenceID   *uuid.UUID `json:"reference_id,omitempty"`
+	Read          bool       `json:"read"`
+	ReadAt        *time.Time `json:"read_at,omitempty"`
+	CreatedAt     time.Time  `json:"created_at"`
+}
+
+type NotificationUnreadCount struct {
+	Unread int64 `json:"unread"`
+}
+
+// NotificationsRead describes notifications that were just marked as read,
+// either the listed IDs or all of them.
+type NotificationsRead struct {
+	IDs   []uuid.UUID `json:"ids,omitempty"`
+	All   bool        `json:"all,omitempty"`
+	Count int64       `json:"count,omitempty"`
+}
+
+type NotificationFilter struct {
+	Unread *bool `json:"unread,omitempty"`
+}
+
+type NotificationPreference struct {
+	Event   string `json:"event"`
+	Channel string `json:"channel"`
+	Enabled bool   `json:"enabled"`
+}
+
+type NotificationPreferenceInput struct {
+	Event   string `json:"event" validate:"required"`
+	Channel string `json:"channel" validate:"required"`
+	Enabled *bool  `json:"enabled" validate:"required"`
+}
+
+type UpdateNotificationPreferencesInput struct {
+	Preferences []NotificationPreferenceInput `json:"preferences" validate:"required,min=1,dive"`
+}
+
+type WebhookEndpoint struct {
+	ID          uuid.UUID  `json:"id"`
+	URL         string     `json:"url"`
+	Description *string    `json:"description,omitempty"`
+	EventTypes  []string   `json:"event_types"`
+	IsActive    bool       `json:"is_active"`
+	CreatedBy   uuid.UUID  `json:"created_by"`
+	UpdatedAt   *time.Time `json:"updated_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	// Secret is only returned when it is generated, on creation and rotation.
+	Secret string `json:"secret,omitempty"`
+}
+
+type CreateWebhookEndpointInput struct {
+	URL         string   `json:"url" validate:"required,url"`
+	Description string   `json:"description"`
+	EventTypes  []string `json:"event_types" validate:"required,min=1,dive,required"`
+}
+
+type UpdateWebhookEndpointInput struct {
+	URL         *string   `json:"url" validate:"omitempty,url"`
+	Description *string   `json:"description"`
+	EventTypes  *[]string `json:"event_types" validate:"omitempty,min=1,dive,required"`
+	IsActive    *bool     `json:"is_active"`
+}
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	EndpointID     uuid.UUID       `json:"endpoint_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	MaxAttempts    int32           `json:"max_attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus *int            `json:"response_status,omitempty"`
+	LastError      *string         `json:"last_error,omitempty"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type Job struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// Schedule is a cron expression evaluated in UTC.
+	Schedule  string     `json:"schedule"`
+	NextRunAt *time.Time `json:"next_run_at,omitempty"`
+	LastRun   *JobRun    `json:"last_run,omitempty"`
+}
+
+type JobRun struct {
+	ID           uuid.UUID  `json:"id"`
+	JobName      string     `json:"job_name"`
+	Source       string     `json:"source"`
+	Status       string     `json:"status"`
+	ScheduledFor *time.Time `json:"scheduled_for,omitempty"`
+	TriggeredBy  *uuid.UUID `json:"triggered_by,omitempty"`
+	StartedAt    *time.Time `json:"started_at,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+	Error        *string    `json:"error,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+}
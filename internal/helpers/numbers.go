@@ -1,7 +1,37 @@
 package helpers
 
-import "time"
+import (
+	"fmt"
+	"strings"
+)
 
-func GetNextMemberNumber() int {
-	return int(time.Now().Unix()) % 1000000
+// FormatMemberNumber renders number as a member slug: the lower-cased prefix,
+// the number zero-padded to padding digits and, when checkDigit is set, a
+// Luhn check digit so mistyped numbers can be spotted.
+func FormatMemberNumber(prefix string, padding int, checkDigit bool, number int64) string {
+	digits := fmt.Sprintf("%0*d", padding, number)
+	if checkDigit {
+		digits += fmt.Sprint(LuhnCheckDigit(digits))
+	}
+
+	return strings.ToLower(prefix) + digits
+}
+
+// LuhnCheckDigit returns the digit that makes digits pass the Luhn check.
+func LuhnCheckDigit(digits string) int {
+	sum := 0
+	double := true
+	for i := len(digits) - 1; i >= 0; i-- {
+		d := int(digits[i] - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+
+	return (10 - sum%10) % 10
 }
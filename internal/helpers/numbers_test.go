@@ -0,0 +1,100 @@
+package helpers
+
+import (
+	"strconv"
+	"testing"
+)
+
+// luhnValid checks a number that ends in its check digit, independently of
+// LuhnCheckDigit.
+func luhnValid(number string) bool {
+	sum := 0
+	for i := 0; i < len(number); i++ {
+		d := int(number[len(number)-1-i] - '0')
+		if i%2 == 1 {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+	}
+
+	return sum%10 == 0
+}
+
+func TestLuhnCheckDigit(t *testing.T) {
+	tests := []struct {
+		digits string
+		want   int
+	}{
+		{"0", 0},
+		{"1", 8},
+		{"12345", 5},
+		{"7992739871", 3},
+		{"411111111111111", 1},
+		{"37828224631000", 5},
+		{"000001", 8},
+		{"000123", 0},
+		{"999999", 6},
+	}
+
+	for _, tt := range tests {
+		if got := LuhnCheckDigit(tt.digits); got != tt.want {
+			t.Errorf("LuhnCheckDigit(%s) = %d, want %d", tt.digits, got, tt.want)
+		}
+	}
+}
+
+func TestLuhnCheckDigitCatchesTypos(t *testing.T) {
+	for n := 0; n < 10000; n++ {
+		digits := FormatMemberNumber("", 6, false, int64(n))
+		number := digits + strconv.Itoa(LuhnCheckDigit(digits))
+		if !luhnValid(number) {
+			t.Fatalf("%s does not pass the Luhn check", number)
+		}
+
+		// Every single mistyped digit is caught.
+		for i := range number {
+			for d := byte('0'); d <= '9'; d++ {
+				if d == number[i] {
+					continue
+				}
+				typo := number[:i] + string(d) + number[i+1:]
+				if luhnValid(typo) {
+					t.Fatalf("mistyping %s as %s passes the Luhn check", number, typo)
+				}
+			}
+		}
+	}
+}
+
+func TestFormatMemberNumber(t *testing.T) {
+	tests := []struct {
+		prefix     string
+		padding    int
+		checkDigit bool
+		number     int64
+		want       string
+	}{
+		{"ara", 6, false, 1, "ara000001"},
+		{"ara", 6, false, 123, "ara000123"},
+		{"ara", 6, false, 999999, "ara999999"},
+		{"ara", 6, false, 1000000, "ara1000000"},
+		{"ara", 3, false, 12345, "ara12345"},
+		{"ara", 0, false, 42, "ara42"},
+		{"ARA-", 4, false, 7, "ara-0007"},
+		{"", 6, false, 7, "000007"},
+		{"ara", 6, true, 1, "ara0000018"},
+		{"ara", 6, true, 123, "ara0001230"},
+		{"ara", 6, true, 999999, "ara9999996"},
+		{"ara", 6, true, 1000000, "ara10000008"},
+	}
+
+	for _, tt := range tests {
+		got := FormatMemberNumber(tt.prefix, tt.padding, tt.checkDigit, tt.number)
+		if got != tt.want {
+			t.Errorf("FormatMemberNumber(%q, %d, %v, %d) = %s, want %s", tt.prefix, tt.padding, tt.checkDigit, tt.number, got, tt.want)
+		}
+	}
+}
@@ -1,11 +1,15 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"slices"
 	"strings"
 
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
 	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/pkg/token"
 )
 
 func (m *Middleware) RequireAuth(next http.Handler) http.Handler {
@@ -23,16 +27,40 @@ func (m *Middleware) RequireAuth(next http.Handler) http.Handler {
 		}
 
 		claims, err := m.TokenSvc.ValidateToken(tokenString)
-		if err != nil {
+		if err != nil || claims.Use != token.TokenUseAccess {
 			m.apiError(w, "Unauthorized: Invalid token", http.StatusUnauthorized)
 			return
 		}
 
+		revoked, err := m.isTokenRevoked(r.Context(), claims)
+		if err != nil {
+			m.Logger.Error().Err(err).Str("jti", claims.RegisteredClaims.ID).Msg("failed to check token revocation")
+			m.apiError(w, "Service Unavailable: Unable to verify token", http.StatusServiceUnavailable)
+			return
+		}
+		if revoked {
+			m.apiError(w, "Unauthorized: Token has been revoked", http.StatusUnauthorized)
+			return
+		}
+
+		// A status change revokes the member's sessions, so the status in
+		// the claims is current.
+		if claims.MemberStatus == repository.MemberStatusExited {
+			m.apiError(w, "Forbidden: Your membership has ended", http.StatusForbidden)
+			return
+		}
+
 		userCtx := users.UserContextValue{
-			ID:          claims.ID,
-			Email:       claims.Email,
-			Roles:       claims.Roles,
-			Permissions: claims.Permissions,
+			ID:           claims.ID,
+			Email:        claims.Email,
+			Roles:        claims.Roles,
+			Permissions:  claims.Permissions,
+			TokenID:      claims.RegisteredClaims.ID,
+			MFA:          claims.MFA,
+			MemberStatus: claims.MemberStatus,
+		}
+		if claims.ExpiresAt != nil {
+			userCtx.TokenExpiresAt = claims.ExpiresAt.Time
 		}
 
 		if slices.Contains(claims.Roles, "member") {
@@ -46,6 +74,25 @@ func (m *Middleware) RequireAuth(next http.Handler) http.Handler {
 	})
 }
 
+// isTokenRevoked reports whether the token was explicitly denylisted (logout)
+// or was issued before the user's sessions were last revoked.
+func (m *Middleware) isTokenRevoked(ctx context.Context, claims *token.UserClaims) (bool, error) {
+	denied, err := m.Cache.IsTokenDenied(ctx, claims.RegisteredClaims.ID)
+	if err != nil {
+		return false, err
+	}
+	if denied {
+		return true, nil
+	}
+
+	version, err := m.Cache.GetTokenVersion(ctx, claims.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return claims.Version < version, nil
+}
+
 func (m *Middleware) RequireRole(requiredRole string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -67,3 +114,22 @@ func (m *Middleware) RequireRole(requiredRole string) func(http.Handler) http.Ha
 		})
 	}
 }
+
+// RequireMFA rejects sessions opened without a second factor when the user
+// holds a permission that requires one. Must run after RequireAuth.
+func (m *Middleware) RequireMFA(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		claims, ok := users.FromContext(r.Context())
+		if !ok {
+			m.apiError(w, "Unauthorized: No user found", http.StatusUnauthorized)
+			return
+		}
+
+		if !claims.MFA && constants.RequiresMFA(claims.Permissions) {
+			m.apiError(w, "Forbidden: Two-factor authentication is required", http.StatusForbidden)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
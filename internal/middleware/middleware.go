@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"net/http"
 
+	"github.com/Jidetireni/ara-cooperative/pkg/cache"
 	"github.com/Jidetireni/ara-cooperative/pkg/logger"
 	"github.com/Jidetireni/ara-cooperative/pkg/token"
 )
@@ -12,12 +13,14 @@ import (
 // handle context very well too
 type Middleware struct {
 	TokenSvc *token.Jwt
+	Cache    *cache.Redis
 	Logger   *logger.Logger
 }
 
-func New(tokenSvc *token.Jwt, logger *logger.Logger) *Middleware {
+func New(tokenSvc *token.Jwt, cache *cache.Redis, logger *logger.Logger) *Middleware {
 	return &Middleware{
 		TokenSvc: tokenSvc,
+		Cache:    cache,
 		Logger:   logger,
 	}
 }
@@ -0,0 +1,181 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// FullPercentage is 100% in hundredths of a percent, what the percentages of
+// a member's beneficiaries add up to.
+const FullPercentage = 10000
+
+// MemberBeneficiaryRepository stores members' next of kin and beneficiaries.
+type MemberBeneficiaryRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewMemberBeneficiaryRepository is the constructor for
+// MemberBeneficiaryRepository.
+func NewMemberBeneficiaryRepository(db *sqlx.DB) *MemberBeneficiaryRepository {
+	return &MemberBeneficiaryRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+type MemberBeneficiaryRepositoryFilter struct {
+	ID       *uuid.UUID
+	MemberID *uuid.UUID
+}
+
+func (br *MemberBeneficiaryRepository) Create(ctx context.Context, beneficiary *MemberBeneficiary, tx *sqlx.Tx) (*MemberBeneficiary, error) {
+	query, args, err := br.psql.Insert("member_beneficiaries").
+		Columns("member_id", "name", "relationship", "phone", "percentage", "next_of_kin", "created_at").
+		Values(beneficiary.MemberID, beneficiary.Name, beneficiary.Relationship, beneficiary.Phone,
+			beneficiary.Percentage, beneficiary.NextOfKin, time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created MemberBeneficiary
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = br.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+func (br *MemberBeneficiaryRepository) Update(ctx context.Context, beneficiary *MemberBeneficiary, tx *sqlx.Tx) (*MemberBeneficiary, error) {
+	query, args, err := br.psql.Update("member_beneficiaries").
+		Set("name", beneficiary.Name).
+		Set("relationship", beneficiary.Relationship).
+		Set("phone", beneficiary.Phone).
+		Set("percentage", beneficiary.Percentage).
+		Set("next_of_kin", beneficiary.NextOfKin).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": beneficiary.ID, "member_id": beneficiary.MemberID}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var updated MemberBeneficiary
+	if tx != nil {
+		err = tx.GetContext(ctx, &updated, query, args...)
+		return &updated, err
+	}
+
+	err = br.db.GetContext(ctx, &updated, query, args...)
+	return &updated, err
+}
+
+// Delete removes the beneficiaries matching filter and returns how many there
+// were.
+func (br *MemberBeneficiaryRepository) Delete(ctx context.Context, filter MemberBeneficiaryRepositoryFilter, tx *sqlx.Tx) (int64, error) {
+	builder := br.psql.Delete("member_beneficiaries")
+	if filter.ID != nil {
+		builder = builder.Where(sq.Eq{"id": *filter.ID})
+	}
+	if filter.MemberID != nil {
+		builder = builder.Where(sq.Eq{"member_id": *filter.MemberID})
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var exec sqlx.ExecerContext = br.db
+	if tx != nil {
+		exec = tx
+	}
+
+	result, err := exec.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+// List returns all of a member's beneficiaries, oldest first. Inside tx it
+// locks the member's row first, so changes that check the allocations of the
+// list are made one at a time.
+func (br *MemberBeneficiaryRepository) List(ctx context.Context, memberID uuid.UUID, tx *sqlx.Tx) ([]MemberBeneficiary, error) {
+	query, args, err := br.psql.Select("*").
+		From("member_beneficiaries").
+		Where(sq.Eq{"member_id": memberID}).
+		OrderBy("created_at ASC", "id ASC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var beneficiaries []MemberBeneficiary
+	if tx == nil {
+		err = br.db.SelectContext(ctx, &beneficiaries, query, args...)
+		return beneficiaries, err
+	}
+
+	lockQuery, lockArgs, err := br.psql.Select("id").
+		From("members").
+		Where(sq.Eq{"id": memberID}).
+		Suffix("FOR UPDATE").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var locked uuid.UUID
+	if err := tx.GetContext(ctx, &locked, lockQuery, lockArgs...); err != nil {
+		return nil, err
+	}
+
+	err = tx.SelectContext(ctx, &beneficiaries, query, args...)
+	return beneficiaries, err
+}
+
+func (br *MemberBeneficiaryRepository) MapRepositoryToDTOModel(beneficiary *MemberBeneficiary) *dto.Beneficiary {
+	out := &dto.Beneficiary{
+		ID:           beneficiary.ID,
+		Name:         beneficiary.Name,
+		Relationship: beneficiary.Relationship,
+		Percentage:   float64(PercentageHundredths(beneficiary.Percentage)) / 100,
+		NextOfKin:    beneficiary.NextOfKin,
+		CreatedAt:    beneficiary.CreatedAt,
+	}
+	if beneficiary.Phone.Valid {
+		out.Phone = &beneficiary.Phone.String
+	}
+	if beneficiary.UpdatedAt.Valid {
+		out.UpdatedAt = &beneficiary.UpdatedAt.Time
+	}
+
+	return out
+}
+
+// PercentageHundredths reads a stored percentage in hundredths of a percent,
+// so allocations can be added up exactly.
+func PercentageHundredths(percentage string) int64 {
+	value, _ := strconv.ParseFloat(percentage, 64)
+	return int64(math.Round(value * 100))
+}
+
+// FormatPercentage is the stored form of a percentage in hundredths.
+func FormatPercentage(hundredths int64) string {
+	return fmt.Sprintf("%d.%02d", hundredths/100, hundredths%100)
+}
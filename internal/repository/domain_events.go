@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	DomainEventStatusPending   = "PENDING"
+	DomainEventStatusProcessed = "PROCESSED"
+	// DomainEventStatusDead marks events a handler kept failing on.
+	DomainEventStatusDead = "DEAD"
+)
+
+type DomainEventRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewDomainEventRepository is the constructor for DomainEventRepository.
+func NewDomainEventRepository(db *sqlx.DB) *DomainEventRepository {
+	return &DomainEventRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create records an event. Pass the transaction of the change it describes so
+// the event only exists if that change commits.
+func (dr *DomainEventRepository) Create(ctx context.Context, event *DomainEvent, tx *sqlx.Tx) (*DomainEvent, error) {
+	query, args, err := dr.psql.Insert("domain_events").
+		Columns("event_type", "payload", "status", "max_attempts", "next_attempt_at", "created_at").
+		Values(event.EventType, string(event.Payload), DomainEventStatusPending, event.MaxAttempts, time.Now(), time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created DomainEvent
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = dr.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+// ClaimDue leases up to limit due events, oldest first, see EmailOutboxRepository.ClaimDue.
+func (dr *DomainEventRepository) ClaimDue(ctx context.Context, limit uint64, lease time.Duration) ([]DomainEvent, error) {
+	now := time.Now()
+	query, args, err := dr.psql.Update("domain_events").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", now.Add(lease)).
+		Set("updated_at", now).
+		Where(
+			"id IN (SELECT id FROM domain_events WHERE status = ? AND next_attempt_at <= ? ORDER BY created_at LIMIT ? FOR UPDATE SKIP LOCKED)",
+			DomainEventStatusPending, now, limit,
+		).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []DomainEvent
+	if err := dr.db.SelectContext(ctx, &claimed, query, args...); err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// MarkHandled records that handler has processed the event, so it is skipped
+// when the event is retried for another handler.
+func (dr *DomainEventRepository) MarkHandled(ctx context.Context, id uuid.UUID, handler string) error {
+	return dr.exec(ctx, dr.psql.Update("domain_events").
+		Set("handled_by", sq.Expr("array_append(handled_by, ?)", handler)).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		Where("NOT (? = ANY(handled_by))", handler))
+}
+
+func (dr *DomainEventRepository) MarkProcessed(ctx context.Context, id uuid.UUID) error {
+	return dr.exec(ctx, dr.psql.Update("domain_events").
+		Set("status", DomainEventStatusProcessed).
+		Set("processed_at", time.Now()).
+		Set("last_error", nil).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}))
+}
+
+// MarkFailed records a failed attempt. A zero nextAttemptAt dead-letters the event.
+func (dr *DomainEventRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	builder := dr.psql.Update("domain_events").
+		Set("last_error", lastError).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})
+
+	if nextAttemptAt.IsZero() {
+		builder = builder.Set("status", DomainEventStatusDead)
+	} else {
+		builder = builder.Set("next_attempt_at", nextAttemptAt)
+	}
+
+	return dr.exec(ctx, builder)
+}
+
+func (dr *DomainEventRepository) exec(ctx context.Context, builder sq.Sqlizer) error {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = dr.db.ExecContext(ctx, query, args...)
+	return err
+}
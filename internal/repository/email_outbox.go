@@ -0,0 +1,248 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+const (
+	EmailOutboxStatusPending = "PENDING"
+	EmailOutboxStatusSent    = "SENT"
+	// EmailOutboxStatusDead marks messages that ran out of attempts. They are
+	// only sent again when an admin retries them.
+	EmailOutboxStatusDead = "DEAD"
+)
+
+type EmailOutboxRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewEmailOutboxRepository is the constructor for EmailOutboxRepository.
+func NewEmailOutboxRepository(db *sqlx.DB) *EmailOutboxRepository {
+	return &EmailOutboxRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+type EmailOutboxRepositoryFilter struct {
+	ID     *uuid.UUID
+	Status *string
+}
+
+func (er *EmailOutboxRepository) applyFilter(builder sq.SelectBuilder, filter EmailOutboxRepositoryFilter) sq.SelectBuilder {
+	if filter.ID != nil {
+		builder = builder.Where(sq.Eq{"id": *filter.ID})
+	}
+	if filter.Status != nil {
+		builder = builder.Where(sq.Eq{"status": *filter.Status})
+	}
+
+	return builder
+}
+
+// Create queues a message. Pass the transaction of the business change so the
+// message is only sent if that change commits.
+func (er *EmailOutboxRepository) Create(ctx context.Context, msg *EmailOutbox, tx *sqlx.Tx) (*EmailOutbox, error) {
+	query, args, err := er.psql.Insert("email_outbox").
+		Columns("recipient", "subject", "body", "text_body", "template", "sensitive", "status", "max_attempts", "next_attempt_at", "reference_type", "reference_id", "created_at").
+		Values(msg.Recipient, msg.Subject, msg.Body, msg.TextBody, msg.Template, msg.Sensitive, EmailOutboxStatusPending, msg.MaxAttempts, time.Now(), msg.ReferenceType, msg.ReferenceID, time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created EmailOutbox
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = er.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+// ClaimDue picks up to limit due messages and leases them for lease by pushing
+// their next attempt into the future. Concurrent workers skip rows another
+// worker is claiming, and a worker that dies mid-send only delays the message
+// until the lease runs out.
+func (er *EmailOutboxRepository) ClaimDue(ctx context.Context, limit uint64, lease time.Duration) ([]EmailOutbox, error) {
+	now := time.Now()
+	query, args, err := er.psql.Update("email_outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", now.Add(lease)).
+		Set("updated_at", now).
+		Where(
+			"id IN (SELECT id FROM email_outbox WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED)",
+			EmailOutboxStatusPending, now, limit,
+		).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []EmailOutbox
+	if err := er.db.SelectContext(ctx, &claimed, query, args...); err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// MarkSent records the delivery and wipes the body of a sensitive message.
+func (er *EmailOutboxRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	return er.exec(ctx, redactSensitive(er.psql.Update("email_outbox").
+		Set("status", EmailOutboxStatusSent).
+		Set("sent_at", time.Now()).
+		Set("last_error", nil).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})))
+}
+
+// MarkFailed records a failed attempt. A zero nextAttemptAt dead-letters the
+// message, wiping its body when it is sensitive.
+func (er *EmailOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	builder := er.psql.Update("email_outbox").
+		Set("last_error", lastError).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})
+
+	if nextAttemptAt.IsZero() {
+		builder = redactSensitive(builder.Set("status", EmailOutboxStatusDead))
+	} else {
+		builder = builder.Set("next_attempt_at", nextAttemptAt)
+	}
+
+	return er.exec(ctx, builder)
+}
+
+// redactSensitive wipes the body of a sensitive message, which holds a
+// one-time code or link, once it is no longer needed for sending.
+func redactSensitive(builder sq.UpdateBuilder) sq.UpdateBuilder {
+	return builder.
+		Set("body", sq.Expr("CASE WHEN sensitive THEN '' ELSE body END")).
+		Set("text_body", sq.Expr("CASE WHEN sensitive THEN NULL ELSE text_body END")).
+		Set("redacted_at", sq.Expr("CASE WHEN sensitive THEN NOW() ELSE redacted_at END"))
+}
+
+// Requeue makes a message due immediately with a fresh set of attempts.
+// Redacted messages cannot be requeued.
+func (er *EmailOutboxRepository) Requeue(ctx context.Context, id uuid.UUID) (*EmailOutbox, error) {
+	query, args, err := er.psql.Update("email_outbox").
+		Set("status", EmailOutboxStatusPending).
+		Set("attempts", 0).
+		Set("next_attempt_at", time.Now()).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		Where(sq.NotEq{"status": EmailOutboxStatusSent}).
+		Where(sq.Eq{"redacted_at": nil}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg EmailOutbox
+	if err := er.db.GetContext(ctx, &msg, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+func (er *EmailOutboxRepository) Get(ctx context.Context, filter EmailOutboxRepositoryFilter) (*EmailOutbox, error) {
+	query, args, err := er.applyFilter(er.psql.Select("*").From("email_outbox"), filter).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var msg EmailOutbox
+	if err := er.db.GetContext(ctx, &msg, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &msg, nil
+}
+
+func (er *EmailOutboxRepository) List(ctx context.Context, filter EmailOutboxRepositoryFilter, opts QueryOptions) (*ListResult[EmailOutbox], error) {
+	builder := er.applyFilter(er.psql.Select("*").From("email_outbox"), filter)
+	builder, err := ApplyPagination(builder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var messages []EmailOutbox
+	if err := er.db.SelectContext(ctx, &messages, query, args...); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(messages, func(m EmailOutbox, _ int) *EmailOutbox { return &m })
+	listResult := ListResult[EmailOutbox]{
+		Items: lo.Slice(items, 0, min(len(items), int(opts.Limit))),
+	}
+
+	if len(items) > int(opts.Limit) {
+		lastItem := lo.LastOr(items, nil)
+		if lastItem != nil {
+			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			listResult.NextCursor = &nextCursor
+		}
+	}
+
+	return &listResult, nil
+}
+
+// MapRepositoryToDTOModel leaves out the body: it may hold one-time codes and reset links.
+func (er *EmailOutboxRepository) MapRepositoryToDTOModel(msg *EmailOutbox) *dto.EmailOutboxMessage {
+	out := &dto.EmailOutboxMessage{
+		ID:            msg.ID,
+		Recipient:     msg.Recipient,
+		Subject:       msg.Subject,
+		Status:        msg.Status,
+		Attempts:      msg.Attempts,
+		MaxAttempts:   msg.MaxAttempts,
+		NextAttemptAt: msg.NextAttemptAt,
+		CreatedAt:     msg.CreatedAt,
+	}
+	if msg.Template.Valid {
+		out.Template = &msg.Template.String
+	}
+	if msg.LastError.Valid {
+		out.LastError = &msg.LastError.String
+	}
+	if msg.ReferenceType.Valid {
+		out.ReferenceType = &msg.ReferenceType.String
+	}
+	if msg.ReferenceID.Valid {
+		out.ReferenceID = &msg.ReferenceID.UUID
+	}
+	if msg.SentAt.Valid {
+		out.SentAt = &msg.SentAt.Time
+	}
+
+	return out
+}
+
+func (er *EmailOutboxRepository) exec(ctx context.Context, builder sq.Sqlizer) error {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = er.db.ExecContext(ctx, query, args...)
+	return err
+}
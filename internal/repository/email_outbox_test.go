@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+)
+
+// testDB connects to the migrated database in TEST_DB_URL and skips the test
+// when it is not set.
+func testDB(t *testing.T) *sqlx.DB {
+	t.Helper()
+
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+
+	return db
+}
+
+func TestEmailOutboxRedaction(t *testing.T) {
+	db := testDB(t)
+	repo := NewEmailOutboxRepository(db)
+	ctx := context.Background()
+
+	queue := func(sensitive bool) *EmailOutbox {
+		t.Helper()
+		msg, err := repo.Create(ctx, &EmailOutbox{
+			Recipient:   "ada@example.com",
+			Subject:     "Welcome",
+			Body:        "<p>Your code is 482913</p>",
+			TextBody:    sql.NullString{String: "Your code is 482913", Valid: true},
+			Sensitive:   sensitive,
+			MaxAttempts: 1,
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Exec("DELETE FROM email_outbox WHERE id = $1", msg.ID) })
+		return msg
+	}
+
+	reload := func(msg *EmailOutbox) *EmailOutbox {
+		t.Helper()
+		got, err := repo.Get(ctx, EmailOutboxRepositoryFilter{ID: &msg.ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	tests := []struct {
+		name      string
+		sensitive bool
+		finish    func(*EmailOutbox) error
+	}{
+		{"sent sensitive", true, func(msg *EmailOutbox) error { return repo.MarkSent(ctx, msg.ID) }},
+		{"dead sensitive", true, func(msg *EmailOutbox) error { return repo.MarkFailed(ctx, msg.ID, "refused", time.Time{}) }},
+		{"sent plain", false, func(msg *EmailOutbox) error { return repo.MarkSent(ctx, msg.ID) }},
+		{"dead plain", false, func(msg *EmailOutbox) error { return repo.MarkFailed(ctx, msg.ID, "refused", time.Time{}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := queue(tt.sensitive)
+			if err := tt.finish(msg); err != nil {
+				t.Fatal(err)
+			}
+
+			got := reload(msg)
+			if tt.sensitive {
+				if got.Body != "" || got.TextBody.Valid || !got.RedactedAt.Valid {
+					t.Errorf("sensitive message kept body %q, text body %v, redacted %v", got.Body, got.TextBody, got.RedactedAt.Valid)
+				}
+				if _, err := repo.Requeue(ctx, msg.ID); err == nil {
+					t.Error("requeued a redacted message")
+				}
+				return
+			}
+			if got.Body != msg.Body || got.TextBody != msg.TextBody || got.RedactedAt.Valid {
+				t.Errorf("plain message was redacted: body %q, text body %v", got.Body, got.TextBody)
+			}
+		})
+	}
+
+	t.Run("retry keeps body", func(t *testing.T) {
+		msg := queue(true)
+		if err := repo.MarkFailed(ctx, msg.ID, "timeout", time.Now().Add(time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+		if got := reload(msg); got.Body != msg.Body || got.RedactedAt.Valid {
+			t.Error("message was redacted before its last attempt")
+		}
+	})
+}
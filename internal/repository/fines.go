@@ -33,16 +33,17 @@ type PopulatedFine struct {
 }
 
 type populatedFineFlat struct {
-	FineID            uuid.UUID     `json:"f_id"`
-	FineAdminID       uuid.UUID     `json:"f_admin_id"`
-	FineMemberID      uuid.UUID     `json:"f_member_id"`
-	FineTransactionID uuid.NullUUID `json:"f_transaction_id"`
-	FineAmount        int64         `json:"f_amount"`
-	FineReason        string        `json:"f_reason"`
-	FineDeadline      time.Time     `json:"f_deadline"`
-	FinePaidAt        sql.NullTime  `json:"f_paid_at"`
-	FineCreatedAt     time.Time     `json:"f_created_at"`
-	FineUpdatedAt     sql.NullTime  `json:"f_updated_at"`
+	FineID             uuid.UUID     `json:"f_id"`
+	FineAdminID        uuid.UUID     `json:"f_admin_id"`
+	FineMemberID       uuid.UUID     `json:"f_member_id"`
+	FineTransactionID  uuid.NullUUID `json:"f_transaction_id"`
+	FineAmount         int64         `json:"f_amount"`
+	FineReason         string        `json:"f_reason"`
+	FineDeadline       time.Time     `json:"f_deadline"`
+	FinePaidAt         sql.NullTime  `json:"f_paid_at"`
+	FineCreatedAt      time.Time     `json:"f_created_at"`
+	FineUpdatedAt      sql.NullTime  `json:"f_updated_at"`
+	FineOpeningBalance bool          `json:"f_opening_balance"`
 
 	TrID          *uuid.UUID       `json:"tr_id"`
 	TrMemberID    *uuid.UUID       `json:"tr_member_id"`
@@ -74,11 +75,12 @@ type populatedFineFlat struct {
 }
 
 type FineRepositoryFilter struct {
-	ID            *uuid.UUID
-	AdminID       *uuid.UUID
-	MemberID      *uuid.UUID
-	TransactionID *uuid.UUID
-	Paid          *bool
+	ID             *uuid.UUID
+	AdminID        *uuid.UUID
+	MemberID       *uuid.UUID
+	TransactionID  *uuid.UUID
+	Paid           *bool
+	OpeningBalance *bool
 }
 
 func (f *FineRepository) populatedSelectColumns() []string {
@@ -94,6 +96,7 @@ func (f *FineRepository) populatedSelectColumns() []string {
 		"f.paid_at AS f_paid_at",
 		"f.created_at AS f_created_at",
 		"f.updated_at AS f_updated_at",
+		"f.opening_balance AS f_opening_balance",
 
 		// Transaction fields
 		"tr.id AS tr_id",
@@ -148,6 +151,9 @@ func (f *FineRepository) applyFilter(builder sq.SelectBuilder, filter FineReposi
 			builder = builder.Where(sq.Eq{"f.paid_at": nil})
 		}
 	}
+	if filter.OpeningBalance != nil {
+		builder = builder.Where(sq.Eq{"f.opening_balance": *filter.OpeningBalance})
+	}
 	return builder
 }
 
@@ -237,9 +243,17 @@ func (f *FineRepository) ListPopulated(ctx context.Context, filter FineRepositor
 }
 
 func (f *FineRepository) Create(ctx context.Context, fine *Fine, tx *sqlx.Tx) (*Fine, error) {
+	columns := []string{"admin_id", "member_id", "amount", "reason", "deadline", "opening_balance"}
+	values := []any{fine.AdminID, fine.MemberID, fine.Amount, fine.Reason, fine.Deadline, fine.OpeningBalance}
+	// Fines carried over from before go-live keep the date they were issued
+	if !fine.CreatedAt.IsZero() {
+		columns = append(columns, "created_at")
+		values = append(values, fine.CreatedAt)
+	}
+
 	builder := f.psql.Insert("fines").
-		Columns("admin_id", "member_id", "amount", "reason", "deadline").
-		Values(fine.AdminID, fine.MemberID, fine.Amount, fine.Reason, fine.Deadline).
+		Columns(columns...).
+		Values(values...).
 		Suffix("RETURNING *")
 
 	query, args, err := builder.ToSql()
@@ -285,6 +299,34 @@ func (f *FineRepository) Update(ctx context.Context, fine *Fine, tx *sqlx.Tx) (*
 	return &updatedFine, err
 }
 
+// ClaimDeadlineReminders marks up to limit unpaid fines due before dueBefore
+// as reminded and returns them. Fines with a payment awaiting confirmation are
+// skipped. Run it in the transaction that queues the reminders so a rollback
+// leaves them unclaimed.
+func (f *FineRepository) ClaimDeadlineReminders(ctx context.Context, dueBefore time.Time, limit uint64, tx *sqlx.Tx) ([]Fine, error) {
+	now := time.Now()
+	query, args, err := f.psql.Update("fines").
+		Set("reminder_sent_at", now).
+		Where(
+			"id IN (SELECT id FROM fines WHERE paid_at IS NULL AND transaction_id IS NULL AND reminder_sent_at IS NULL AND deadline > ? AND deadline <= ? ORDER BY deadline LIMIT ? FOR UPDATE SKIP LOCKED)",
+			now, dueBefore, limit,
+		).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var fines []Fine
+	if tx != nil {
+		err = tx.SelectContext(ctx, &fines, query, args...)
+		return fines, err
+	}
+
+	err = f.db.SelectContext(ctx, &fines, query, args...)
+	return fines, err
+}
+
 func (f *FineRepository) Delete(ctx context.Context, id uuid.UUID, tx *sqlx.Tx) error {
 	builder := f.psql.Delete("fines").
 		Where(sq.Eq{"id": id})
@@ -305,16 +347,17 @@ func (f *FineRepository) Delete(ctx context.Context, id uuid.UUID, tx *sqlx.Tx)
 
 func (f *FineRepository) mapFlatToPopulated(flat *populatedFineFlat) *PopulatedFine {
 	fine := Fine{
-		ID:            flat.FineID,
-		AdminID:       flat.FineAdminID,
-		MemberID:      flat.FineMemberID,
-		TransactionID: flat.FineTransactionID,
-		Amount:        flat.FineAmount,
-		Reason:        flat.FineReason,
-		Deadline:      flat.FineDeadline,
-		PaidAt:        flat.FinePaidAt,
-		CreatedAt:     flat.FineCreatedAt,
-		UpdatedAt:     flat.FineUpdatedAt,
+		ID:             flat.FineID,
+		AdminID:        flat.FineAdminID,
+		MemberID:       flat.FineMemberID,
+		TransactionID:  flat.FineTransactionID,
+		Amount:         flat.FineAmount,
+		Reason:         flat.FineReason,
+		Deadline:       flat.FineDeadline,
+		PaidAt:         flat.FinePaidAt,
+		CreatedAt:      flat.FineCreatedAt,
+		UpdatedAt:      flat.FineUpdatedAt,
+		OpeningBalance: flat.FineOpeningBalance,
 	}
 
 	member := Member{
@@ -374,11 +417,12 @@ func (f *FineRepository) MapRepositoryToDTOModel(populated *PopulatedFine) *dto.
 	}
 
 	result := &dto.Fine{
-		ID:       populated.ID,
-		Amount:   populated.Amount,
-		Reason:   populated.Reason,
-		Deadline: populated.Deadline,
-		Paid:     populated.PaidAt.Valid,
+		ID:             populated.ID,
+		Amount:         populated.Amount,
+		Reason:         populated.Reason,
+		Deadline:       populated.Deadline,
+		Paid:           populated.PaidAt.Valid,
+		OpeningBalance: populated.OpeningBalance,
 	}
 
 	if populated.Transaction != nil {
@@ -102,42 +102,82 @@ func ApplyPagination(builder sq.SelectBuilder, opts QueryOptions) (sq.SelectBuil
 		return builder, err
 	}
 
+	var cursorValue any
+	var cursorID uuid.UUID
+	if opts.Cursor != nil {
+		cursorValue, cursorID, err = decodeCursor(*opts.Cursor)
+		if err != nil {
+			return builder, err
+		}
+	}
+
+	return applyKeyset(builder, sortResult, cursorValue, cursorID, opts.Limit), nil
+}
+
+// applyKeyset orders builder by the sort column and id, starts it at the row
+// identified by cursorValue and cursorID when cursorValue is not nil, and
+// fetches one row more than limit so callers can tell there is a next page.
+func applyKeyset(builder sq.SelectBuilder, sortResult SortResult, cursorValue any, cursorID uuid.UUID, limit uint32) sq.SelectBuilder {
 	idColumn := "id"
 	if strings.Contains(sortResult.Column, ".") {
 		table := strings.Split(sortResult.Column, ".")[0]
 		idColumn = fmt.Sprintf("%s.id", table)
 	}
 
-	if opts.Cursor != nil {
-		cursorTime, cursorID, err := decodeCursor(*opts.Cursor)
-		if err != nil {
-			return builder, err
-		}
-
+	if cursorValue != nil {
 		switch sortResult.Order {
 		case SortOrderAsc:
 			builder = builder.Where(sq.Or{
-				sq.Gt{sortResult.Column: cursorTime},
+				sq.Gt{sortResult.Column: cursorValue},
 				sq.And{
-					sq.Eq{sortResult.Column: cursorTime},
+					sq.Eq{sortResult.Column: cursorValue},
 					sq.GtOrEq{idColumn: cursorID},
 				},
 			})
 		case SortOrderDesc:
 			builder = builder.Where(sq.Or{
-				sq.Lt{sortResult.Column: cursorTime},
+				sq.Lt{sortResult.Column: cursorValue},
 				sq.And{
-					sq.Eq{sortResult.Column: cursorTime},
+					sq.Eq{sortResult.Column: cursorValue},
 					sq.LtOrEq{idColumn: cursorID},
 				},
 			})
 		}
-
 	}
 
 	builder = builder.OrderBy(fmt.Sprintf("%s %s, %s %s", sortResult.Column, string(sortResult.Order), idColumn, string(sortResult.Order)))
-	builder = builder.Limit(uint64(min(opts.Limit, 100) + 1))
-	return builder, nil
+	builder = builder.Limit(uint64(min(limit, 100) + 1))
+	return builder
+}
+
+// EncodeValueCursor is EncodeCursor for lists sorted by a text column.
+func EncodeValueCursor(value string, id uuid.UUID) string {
+	return base64.StdEncoding.EncodeToString([]byte(value + "|" + id.String()))
+}
+
+func decodeValueCursor(cursor string) (string, uuid.UUID, error) {
+	decoded, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("failed to decode cursor: %w", err)
+	}
+
+	// The value may itself contain the separator, the id never does.
+	i := strings.LastIndex(string(decoded), "|")
+	if i < 0 {
+		return "", uuid.Nil, fmt.Errorf("invalid cursor format")
+	}
+
+	id, err := uuid.Parse(string(decoded[i+1:]))
+	if err != nil {
+		return "", uuid.Nil, fmt.Errorf("invalid id in cursor: %w", err)
+	}
+
+	return string(decoded[:i]), id, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s so it matches literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
 }
 
 type ListResult[T any] struct {
@@ -0,0 +1,235 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+)
+
+const (
+	JobRunSourceSchedule = "SCHEDULE"
+	JobRunSourceManual   = "MANUAL"
+
+	// JobRunStatusQueued marks manual runs waiting for a worker.
+	JobRunStatusQueued    = "QUEUED"
+	JobRunStatusRunning   = "RUNNING"
+	JobRunStatusSucceeded = "SUCCEEDED"
+	JobRunStatusFailed    = "FAILED"
+	// JobRunStatusSkipped marks runs that found the job already running.
+	JobRunStatusSkipped = "SKIPPED"
+)
+
+type JobRunRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewJobRunRepository is the constructor for JobRunRepository.
+func NewJobRunRepository(db *sqlx.DB) *JobRunRepository {
+	return &JobRunRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+type JobRunRepositoryFilter struct {
+	ID      *uuid.UUID
+	JobName *string
+	Status  *string
+}
+
+func (jr *JobRunRepository) applyFilter(builder sq.SelectBuilder, filter JobRunRepositoryFilter) sq.SelectBuilder {
+	if filter.ID != nil {
+		builder = builder.Where(sq.Eq{"id": *filter.ID})
+	}
+	if filter.JobName != nil {
+		builder = builder.Where(sq.Eq{"job_name": *filter.JobName})
+	}
+	if filter.Status != nil {
+		builder = builder.Where(sq.Eq{"status": *filter.Status})
+	}
+
+	return builder
+}
+
+// CreateScheduled records the start of the occurrence of jobName due at
+// scheduledFor. It returns sql.ErrNoRows when another worker has already
+// claimed that occurrence.
+func (jr *JobRunRepository) CreateScheduled(ctx context.Context, jobName string, scheduledFor time.Time) (*JobRun, error) {
+	query, args, err := jr.psql.Insert("job_runs").
+		Columns("job_name", "source", "status", "scheduled_for", "started_at", "created_at").
+		Values(jobName, JobRunSourceSchedule, JobRunStatusRunning, scheduledFor, time.Now(), time.Now()).
+		Suffix("ON CONFLICT (job_name, scheduled_for) DO NOTHING RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var run JobRun
+	if err := jr.db.GetContext(ctx, &run, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// CreateQueued records a manual run for a worker to pick up.
+func (jr *JobRunRepository) CreateQueued(ctx context.Context, jobName string, triggeredBy uuid.UUID) (*JobRun, error) {
+	query, args, err := jr.psql.Insert("job_runs").
+		Columns("job_name", "source", "status", "triggered_by", "created_at").
+		Values(jobName, JobRunSourceManual, JobRunStatusQueued, ToNullUUID(triggeredBy), time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var run JobRun
+	if err := jr.db.GetContext(ctx, &run, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// ClaimQueued starts up to limit queued runs of the given jobs, oldest first.
+// Concurrent workers never claim the same run.
+func (jr *JobRunRepository) ClaimQueued(ctx context.Context, jobNames []string, limit uint64) ([]JobRun, error) {
+	query, args, err := jr.psql.Update("job_runs").
+		Set("status", JobRunStatusRunning).
+		Set("started_at", time.Now()).
+		Where(
+			"id IN (SELECT id FROM job_runs WHERE status = ? AND job_name = ANY(?) ORDER BY created_at LIMIT ? FOR UPDATE SKIP LOCKED)",
+			JobRunStatusQueued, pq.StringArray(jobNames), limit,
+		).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []JobRun
+	if err := jr.db.SelectContext(ctx, &claimed, query, args...); err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// Finish records the outcome of a run. lastError is stored when not empty.
+func (jr *JobRunRepository) Finish(ctx context.Context, id uuid.UUID, status string, lastError string) error {
+	query, args, err := jr.psql.Update("job_runs").
+		Set("status", status).
+		Set("error", ToNullString(lo.EmptyableToPtr(lastError))).
+		Set("finished_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = jr.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (jr *JobRunRepository) Get(ctx context.Context, filter JobRunRepositoryFilter) (*JobRun, error) {
+	query, args, err := jr.applyFilter(jr.psql.Select("*").From("job_runs"), filter).
+		OrderBy("created_at DESC").
+		Limit(1).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var run JobRun
+	if err := jr.db.GetContext(ctx, &run, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &run, nil
+}
+
+// Latest returns the most recent run of every job that has run.
+func (jr *JobRunRepository) Latest(ctx context.Context) ([]JobRun, error) {
+	query, args, err := jr.psql.Select("*").
+		Options("DISTINCT ON (job_name)").
+		From("job_runs").
+		OrderBy("job_name", "created_at DESC").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []JobRun
+	if err := jr.db.SelectContext(ctx, &runs, query, args...); err != nil {
+		return nil, err
+	}
+
+	return runs, nil
+}
+
+func (jr *JobRunRepository) List(ctx context.Context, filter JobRunRepositoryFilter, opts QueryOptions) (*ListResult[JobRun], error) {
+	builder := jr.applyFilter(jr.psql.Select("*").From("job_runs"), filter)
+	builder, err := ApplyPagination(builder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var runs []JobRun
+	if err := jr.db.SelectContext(ctx, &runs, query, args...); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(runs, func(r JobRun, _ int) *JobRun { return &r })
+	listResult := ListResult[JobRun]{
+		Items: lo.Slice(items, 0, min(len(items), int(opts.Limit))),
+	}
+
+	if len(items) > int(opts.Limit) {
+		lastItem := lo.LastOr(items, nil)
+		if lastItem != nil {
+			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			listResult.NextCursor = &nextCursor
+		}
+	}
+
+	return &listResult, nil
+}
+
+func (jr *JobRunRepository) MapRepositoryToDTOModel(run *JobRun) *dto.JobRun {
+	out := &dto.JobRun{
+		ID:        run.ID,
+		JobName:   run.JobName,
+		Source:    run.Source,
+		Status:    run.Status,
+		CreatedAt: run.CreatedAt,
+	}
+	if run.ScheduledFor.Valid {
+		out.ScheduledFor = &run.ScheduledFor.Time
+	}
+	if run.TriggeredBy.Valid {
+		out.TriggeredBy = &run.TriggeredBy.UUID
+	}
+	if run.StartedAt.Valid {
+		out.StartedAt = &run.StartedAt.Time
+	}
+	if run.FinishedAt.Valid {
+		out.FinishedAt = &run.FinishedAt.Time
+	}
+	if run.Error.Valid {
+		out.Error = &run.Error.String
+	}
+
+	return out
+}
@@ -0,0 +1,263 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+const (
+	MemberChangeRequestStatusPending  = "PENDING"
+	MemberChangeRequestStatusApproved = "APPROVED"
+	MemberChangeRequestStatusRejected = "REJECTED"
+)
+
+// MemberChangeRepository stores requested changes to sensitive member fields
+// and the history of every change made to a member's profile.
+type MemberChangeRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewMemberChangeRepository is the constructor for MemberChangeRepository.
+func NewMemberChangeRepository(db *sqlx.DB) *MemberChangeRepository {
+	return &MemberChangeRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+type MemberChangeRequestRepositoryFilter struct {
+	ID       *uuid.UUID
+	MemberID *uuid.UUID
+	Status   *string
+}
+
+func (mr *MemberChangeRepository) applyRequestFilter(builder sq.SelectBuilder, filter MemberChangeRequestRepositoryFilter) sq.SelectBuilder {
+	if filter.ID != nil {
+		builder = builder.Where(sq.Eq{"id": *filter.ID})
+	}
+	if filter.MemberID != nil {
+		builder = builder.Where(sq.Eq{"member_id": *filter.MemberID})
+	}
+	if filter.Status != nil {
+		builder = builder.Where(sq.Eq{"status": *filter.Status})
+	}
+
+	return builder
+}
+
+type MemberFieldChangeRepositoryFilter struct {
+	MemberID *uuid.UUID
+	Field    *string
+}
+
+func (mr *MemberChangeRepository) CreateRequest(ctx context.Context, request *MemberChangeRequest, tx *sqlx.Tx) (*MemberChangeRequest, error) {
+	query, args, err := mr.psql.Insert("member_change_requests").
+		Columns("member_id", "changes", "status", "requested_by", "created_at").
+		Values(request.MemberID, string(request.Changes), MemberChangeRequestStatusPending, request.RequestedBy, time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created MemberChangeRequest
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = mr.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+func (mr *MemberChangeRepository) GetRequest(ctx context.Context, filter MemberChangeRequestRepositoryFilter) (*MemberChangeRequest, error) {
+	query, args, err := mr.applyRequestFilter(mr.psql.Select("*").From("member_change_requests"), filter).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var request MemberChangeRequest
+	if err := mr.db.GetContext(ctx, &request, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &request, nil
+}
+
+// Review closes a pending request with status. It returns sql.ErrNoRows when
+// the request does not exist or has already been reviewed, so two admins
+// cannot both act on it.
+func (mr *MemberChangeRepository) Review(ctx context.Context, id uuid.UUID, status string, reviewedBy uuid.UUID, note *string, tx *sqlx.Tx) (*MemberChangeRequest, error) {
+	query, args, err := mr.psql.Update("member_change_requests").
+		Set("status", status).
+		Set("reviewed_by", reviewedBy).
+		Set("review_note", ToNullString(note)).
+		Set("reviewed_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": MemberChangeRequestStatusPending}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var reviewed MemberChangeRequest
+	if tx != nil {
+		err = tx.GetContext(ctx, &reviewed, query, args...)
+		return &reviewed, err
+	}
+
+	err = mr.db.GetContext(ctx, &reviewed, query, args...)
+	return &reviewed, err
+}
+
+func (mr *MemberChangeRepository) ListRequests(ctx context.Context, filter MemberChangeRequestRepositoryFilter, opts QueryOptions) (*ListResult[MemberChangeRequest], error) {
+	builder := mr.applyRequestFilter(mr.psql.Select("*").From("member_change_requests"), filter)
+	builder, err := ApplyPagination(builder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var requests []MemberChangeRequest
+	if err := mr.db.SelectContext(ctx, &requests, query, args...); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(requests, func(r MemberChangeRequest, _ int) *MemberChangeRequest { return &r })
+	listResult := ListResult[MemberChangeRequest]{
+		Items: lo.Slice(items, 0, min(len(items), int(opts.Limit))),
+	}
+
+	if len(items) > int(opts.Limit) {
+		lastItem := lo.LastOr(items, nil)
+		if lastItem != nil {
+			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			listResult.NextCursor = &nextCursor
+		}
+	}
+
+	return &listResult, nil
+}
+
+// RecordFieldChanges appends changes to the member's history as part of tx.
+func (mr *MemberChangeRepository) RecordFieldChanges(ctx context.Context, changes []MemberFieldChange, tx *sqlx.Tx) error {
+	if len(changes) == 0 {
+		return nil
+	}
+
+	builder := mr.psql.Insert("member_field_changes").
+		Columns("member_id", "field", "old_value", "new_value", "changed_by", "change_request_id", "created_at")
+	for _, change := range changes {
+		builder = builder.Values(change.MemberID, change.Field, change.OldValue, change.NewValue, change.ChangedBy, change.ChangeRequestID, time.Now())
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	_, err = mr.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (mr *MemberChangeRepository) ListFieldChanges(ctx context.Context, filter MemberFieldChangeRepositoryFilter, opts QueryOptions) (*ListResult[MemberFieldChange], error) {
+	builder := mr.psql.Select("*").From("member_field_changes")
+	if filter.MemberID != nil {
+		builder = builder.Where(sq.Eq{"member_id": *filter.MemberID})
+	}
+	if filter.Field != nil {
+		builder = builder.Where(sq.Eq{"field": *filter.Field})
+	}
+
+	builder, err := ApplyPagination(builder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []MemberFieldChange
+	if err := mr.db.SelectContext(ctx, &changes, query, args...); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(changes, func(c MemberFieldChange, _ int) *MemberFieldChange { return &c })
+	listResult := ListResult[MemberFieldChange]{
+		Items: lo.Slice(items, 0, min(len(items), int(opts.Limit))),
+	}
+
+	if len(items) > int(opts.Limit) {
+		lastItem := lo.LastOr(items, nil)
+		if lastItem != nil {
+			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			listResult.NextCursor = &nextCursor
+		}
+	}
+
+	return &listResult, nil
+}
+
+func (mr *MemberChangeRepository) MapRequestToDTOModel(request *MemberChangeRequest) *dto.MemberChangeRequest {
+	out := &dto.MemberChangeRequest{
+		ID:          request.ID,
+		MemberID:    request.MemberID,
+		Status:      request.Status,
+		RequestedBy: request.RequestedBy,
+		CreatedAt:   request.CreatedAt,
+	}
+	// Changes always holds a marshalled dto.MemberChanges.
+	_ = json.Unmarshal(request.Changes, &out.Changes)
+	if request.ReviewedBy.Valid {
+		out.ReviewedBy = &request.ReviewedBy.UUID
+	}
+	if request.ReviewNote.Valid {
+		out.ReviewNote = &request.ReviewNote.String
+	}
+	if request.ReviewedAt.Valid {
+		out.ReviewedAt = &request.ReviewedAt.Time
+	}
+
+	return out
+}
+
+func (mr *MemberChangeRepository) MapFieldChangeToDTOModel(change *MemberFieldChange) *dto.MemberFieldChange {
+	out := &dto.MemberFieldChange{
+		ID:        change.ID,
+		Field:     change.Field,
+		CreatedAt: change.CreatedAt,
+	}
+	if change.OldValue.Valid {
+		out.OldValue = &change.OldValue.String
+	}
+	if change.NewValue.Valid {
+		out.NewValue = &change.NewValue.String
+	}
+	if change.ChangedBy.Valid {
+		out.ChangedBy = &change.ChangedBy.UUID
+	}
+	if change.ChangeRequestID.Valid {
+		out.ChangeRequestID = &change.ChangeRequestID.UUID
+	}
+
+	return out
+}
@@ -0,0 +1,205 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+const (
+	MemberDocumentTypeMembershipForm = "MEMBERSHIP_FORM"
+	MemberDocumentTypeIDCard         = "ID_CARD"
+	MemberDocumentTypePassportPhoto  = "PASSPORT_PHOTO"
+	MemberDocumentTypeUtilityBill    = "UTILITY_BILL"
+)
+
+const (
+	MemberDocumentStatusPending  = "PENDING"
+	MemberDocumentStatusVerified = "VERIFIED"
+	MemberDocumentStatusRejected = "REJECTED"
+)
+
+// MemberDocumentRepository stores the KYC documents members upload. The files
+// themselves are kept in storage under StorageKey.
+type MemberDocumentRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewMemberDocumentRepository is the constructor for MemberDocumentRepository.
+func NewMemberDocumentRepository(db *sqlx.DB) *MemberDocumentRepository {
+	return &MemberDocumentRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+type MemberDocumentRepositoryFilter struct {
+	ID       *uuid.UUID
+	MemberID *uuid.UUID
+	Type     *string
+	Status   *string
+}
+
+func (dr *MemberDocumentRepository) applyFilter(builder sq.SelectBuilder, filter MemberDocumentRepositoryFilter) sq.SelectBuilder {
+	if filter.ID != nil {
+		builder = builder.Where(sq.Eq{"id": *filter.ID})
+	}
+	if filter.MemberID != nil {
+		builder = builder.Where(sq.Eq{"member_id": *filter.MemberID})
+	}
+	if filter.Type != nil {
+		builder = builder.Where(sq.Eq{"type": *filter.Type})
+	}
+	if filter.Status != nil {
+		builder = builder.Where(sq.Eq{"status": *filter.Status})
+	}
+
+	return builder
+}
+
+func (dr *MemberDocumentRepository) Create(ctx context.Context, document *MemberDocument, tx *sqlx.Tx) (*MemberDocument, error) {
+	query, args, err := dr.psql.Insert("member_documents").
+		Columns("member_id", "type", "storage_key", "content_type", "size_bytes", "original_name", "status", "uploaded_by", "created_at").
+		Values(document.MemberID, document.Type, document.StorageKey, document.ContentType, document.SizeBytes,
+			document.OriginalName, MemberDocumentStatusPending, document.UploadedBy, time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created MemberDocument
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = dr.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+func (dr *MemberDocumentRepository) Get(ctx context.Context, filter MemberDocumentRepositoryFilter) (*MemberDocument, error) {
+	query, args, err := dr.applyFilter(dr.psql.Select("*").From("member_documents"), filter).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var document MemberDocument
+	if err := dr.db.GetContext(ctx, &document, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &document, nil
+}
+
+// Review closes a pending document with status. It returns sql.ErrNoRows when
+// the document does not exist or has already been reviewed, so two admins
+// cannot both act on it.
+func (dr *MemberDocumentRepository) Review(ctx context.Context, id uuid.UUID, status string, reviewedBy uuid.UUID, note *string, tx *sqlx.Tx) (*MemberDocument, error) {
+	query, args, err := dr.psql.Update("member_documents").
+		Set("status", status).
+		Set("reviewed_by", reviewedBy).
+		Set("review_note", ToNullString(note)).
+		Set("reviewed_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": MemberDocumentStatusPending}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var reviewed MemberDocument
+	if tx != nil {
+		err = tx.GetContext(ctx, &reviewed, query, args...)
+		return &reviewed, err
+	}
+
+	err = dr.db.GetContext(ctx, &reviewed, query, args...)
+	return &reviewed, err
+}
+
+func (dr *MemberDocumentRepository) List(ctx context.Context, filter MemberDocumentRepositoryFilter, opts QueryOptions) (*ListResult[MemberDocument], error) {
+	builder := dr.applyFilter(dr.psql.Select("*").From("member_documents"), filter)
+	builder, err := ApplyPagination(builder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []MemberDocument
+	if err := dr.db.SelectContext(ctx, &documents, query, args...); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(documents, func(d MemberDocument, _ int) *MemberDocument { return &d })
+	listResult := ListResult[MemberDocument]{
+		Items: lo.Slice(items, 0, min(len(items), int(opts.Limit))),
+	}
+
+	if len(items) > int(opts.Limit) {
+		lastItem := lo.LastOr(items, nil)
+		if lastItem != nil {
+			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			listResult.NextCursor = &nextCursor
+		}
+	}
+
+	return &listResult, nil
+}
+
+// VerifiedTypes returns the document types the member has at least one
+// verified document of.
+func (dr *MemberDocumentRepository) VerifiedTypes(ctx context.Context, memberID uuid.UUID, tx *sqlx.Tx) ([]string, error) {
+	query, args, err := dr.psql.Select("DISTINCT type").
+		From("member_documents").
+		Where(sq.Eq{"member_id": memberID, "status": MemberDocumentStatusVerified}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var types []string
+	if tx != nil {
+		err = tx.SelectContext(ctx, &types, query, args...)
+		return types, err
+	}
+
+	err = dr.db.SelectContext(ctx, &types, query, args...)
+	return types, err
+}
+
+func (dr *MemberDocumentRepository) MapRepositoryToDTOModel(document *MemberDocument) *dto.MemberDocument {
+	out := &dto.MemberDocument{
+		ID:           document.ID,
+		MemberID:     document.MemberID,
+		Type:         dto.MemberDocumentType(strings.ToLower(document.Type)),
+		ContentType:  document.ContentType,
+		SizeBytes:    document.SizeBytes,
+		OriginalName: document.OriginalName,
+		Status:       strings.ToLower(document.Status),
+		UploadedBy:   document.UploadedBy,
+		CreatedAt:    document.CreatedAt,
+	}
+	if document.ReviewedBy.Valid {
+		out.ReviewedBy = &document.ReviewedBy.UUID
+	}
+	if document.ReviewNote.Valid {
+		out.ReviewNote = &document.ReviewNote.String
+	}
+	if document.ReviewedAt.Valid {
+		out.ReviewedAt = &document.ReviewedAt.Time
+	}
+
+	return out
+}
@@ -0,0 +1,113 @@
+package repository
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+// UpdateStatus moves the member from one lifecycle state to another. It
+// returns sql.ErrNoRows when the member is no longer in from, so concurrent
+// transitions cannot both apply. The first move to ACTIVE also sets
+// activated_at.
+func (mq *MemberRepository) UpdateStatus(ctx context.Context, id uuid.UUID, from, to string, tx *sqlx.Tx) (*Member, error) {
+	builder := mq.psql.Update("members").
+		Set("status", to).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id, "status": from}).
+		Where("deleted_at IS NULL").
+		Suffix("RETURNING *")
+	if to == MemberStatusActive {
+		builder = builder.Set("activated_at", sq.Expr("COALESCE(activated_at, ?)", time.Now()))
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedMember Member
+	if tx != nil {
+		err = tx.GetContext(ctx, &updatedMember, query, args...)
+		return &updatedMember, err
+	}
+
+	err = mq.db.GetContext(ctx, &updatedMember, query, args...)
+	return &updatedMember, err
+}
+
+func (mq *MemberRepository) RecordStatusChange(ctx context.Context, change *MemberStatusChange, tx *sqlx.Tx) (*MemberStatusChange, error) {
+	query, args, err := mq.psql.Insert("member_status_changes").
+		Columns("member_id", "from_status", "to_status", "reason", "changed_by", "created_at").
+		Values(change.MemberID, change.FromStatus, change.ToStatus, change.Reason, change.ChangedBy, time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created MemberStatusChange
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = mq.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+func (mq *MemberRepository) ListStatusChanges(ctx context.Context, memberID uuid.UUID, opts QueryOptions) (*ListResult[MemberStatusChange], error) {
+	builder := mq.psql.Select("*").
+		From("member_status_changes").
+		Where(sq.Eq{"member_id": memberID})
+	builder, err := ApplyPagination(builder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var changes []MemberStatusChange
+	if err := mq.db.SelectContext(ctx, &changes, query, args...); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(changes, func(c MemberStatusChange, _ int) *MemberStatusChange { return &c })
+	listResult := ListResult[MemberStatusChange]{
+		Items: lo.Slice(items, 0, min(len(items), int(opts.Limit))),
+	}
+
+	if len(items) > int(opts.Limit) {
+		lastItem := lo.LastOr(items, nil)
+		if lastItem != nil {
+			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			listResult.NextCursor = &nextCursor
+		}
+	}
+
+	return &listResult, nil
+}
+
+func (mq *MemberRepository) MapStatusChangeToDTOModel(change *MemberStatusChange) *dto.MemberStatusChange {
+	out := &dto.MemberStatusChange{
+		ID:         change.ID,
+		FromStatus: dto.MemberStatus(strings.ToLower(change.FromStatus)),
+		ToStatus:   dto.MemberStatus(strings.ToLower(change.ToStatus)),
+		Reason:     change.Reason,
+		CreatedAt:  change.CreatedAt,
+	}
+	if change.ChangedBy.Valid {
+		out.ChangedBy = &change.ChangedBy.UUID
+	}
+
+	return out
+}
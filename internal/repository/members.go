@@ -2,6 +2,8 @@ package repository
 
 import (
 	"context"
+	"fmt"
+	"strings"
 	"time"
 
 	"github.com/Jidetireni/ara-cooperative/internal/dto"
@@ -11,6 +13,24 @@ import (
 	"github.com/samber/lo"
 )
 
+const (
+	InvitationStatusPending  = "PENDING"
+	InvitationStatusSent     = "SENT"
+	InvitationStatusFailed   = "FAILED"
+	InvitationStatusAccepted = "ACCEPTED"
+)
+
+// Member lifecycle states. See constants.MemberStatusTransitions for the moves
+// between them.
+const (
+	MemberStatusPending   = "PENDING"
+	MemberStatusActive    = "ACTIVE"
+	MemberStatusSuspended = "SUSPENDED"
+	MemberStatusDormant   = "DORMANT"
+	MemberStatusExiting   = "EXITING"
+	MemberStatusExited    = "EXITED"
+)
+
 type MemberRepository struct {
 	db   *sqlx.DB
 	psql sq.StatementBuilderType
@@ -23,12 +43,22 @@ func NewMemberRepository(db *sqlx.DB) *MemberRepository {
 	}
 }
 
+// MemberSortColumns are the columns members can be listed by. created_at is
+// the date a member joined.
+var MemberSortColumns = []string{"created_at", "first_name", "last_name", "slug"}
+
 type MemberRepositoryFilter struct {
-	ID       *uuid.UUID
-	UserID   *uuid.UUID
-	Slug     *string
-	Phone    *string
-	IsActive *bool
+	ID     *uuid.UUID
+	UserID *uuid.UUID
+	Slug   *string
+	Phone  *string
+	Status *string
+	// Search matches members whose name, phone, slug or email contains every
+	// word of it, ignoring case.
+	Search *string
+	// JoinedFrom and JoinedTo bound created_at, JoinedTo exclusively.
+	JoinedFrom *time.Time
+	JoinedTo   *time.Time
 }
 
 func (mq *MemberRepository) buildQuery(filter MemberRepositoryFilter, opts QueryOptions) (string, []any, error) {
@@ -64,19 +94,35 @@ func (mq *MemberRepository) buildQuery(filter MemberRepositoryFilter, opts Query
 		builder = builder.Where(sq.Eq{"phone": *filter.Phone})
 	}
 
-	if filter.IsActive != nil {
-		if *filter.IsActive {
-			builder = builder.Where("activated_at IS NOT NULL")
-		} else {
-			builder = builder.Where("activated_at IS NULL")
+	if filter.Status != nil {
+		builder = builder.Where(sq.Eq{"status": *filter.Status})
+	}
+
+	if filter.Search != nil {
+		for _, word := range strings.Fields(*filter.Search) {
+			pattern := "%" + escapeLike(word) + "%"
+			builder = builder.Where(sq.Or{
+				sq.ILike{"first_name": pattern},
+				sq.ILike{"last_name": pattern},
+				sq.ILike{"phone": pattern},
+				sq.ILike{"slug": pattern},
+				sq.Expr("EXISTS (SELECT 1 FROM users u WHERE u.id = members.user_id AND u.email ILIKE ?)", pattern),
+			})
 		}
 	}
 
+	if filter.JoinedFrom != nil {
+		builder = builder.Where(sq.GtOrEq{"created_at": *filter.JoinedFrom})
+	}
+	if filter.JoinedTo != nil {
+		builder = builder.Where(sq.Lt{"created_at": *filter.JoinedTo})
+	}
+
 	if queryType != QueryTypeCount {
 		if opts.Sort == nil {
 			opts.Sort = lo.ToPtr("created_at:desc")
 		}
-		builder, err = ApplyPagination(builder, opts)
+		builder, err = mq.applyPagination(builder, opts)
 		if err != nil {
 			return "", nil, err
 		}
@@ -85,6 +131,46 @@ func (mq *MemberRepository) buildQuery(filter MemberRepositoryFilter, opts Query
 	return builder.ToSql()
 }
 
+// applyPagination is ApplyPagination for the columns in MemberSortColumns,
+// whose cursors hold a text value rather than a time for the name columns.
+func (mq *MemberRepository) applyPagination(builder sq.SelectBuilder, opts QueryOptions) (sq.SelectBuilder, error) {
+	sortResult, err := parseSort(opts.Sort)
+	if err != nil {
+		return builder, err
+	}
+	if !lo.Contains(MemberSortColumns, sortResult.Column) {
+		return builder, fmt.Errorf("cannot sort members by %q", sortResult.Column)
+	}
+	if sortResult.Column == "created_at" {
+		return ApplyPagination(builder, opts)
+	}
+
+	var cursorValue any
+	var cursorID uuid.UUID
+	if opts.Cursor != nil {
+		cursorValue, cursorID, err = decodeValueCursor(*opts.Cursor)
+		if err != nil {
+			return builder, err
+		}
+	}
+
+	return applyKeyset(builder, sortResult, cursorValue, cursorID, opts.Limit), nil
+}
+
+// cursor returns the cursor of the page after member when sorted by column.
+func (mq *MemberRepository) cursor(member *Member, column string) string {
+	switch column {
+	case "first_name":
+		return EncodeValueCursor(member.FirstName, member.ID)
+	case "last_name":
+		return EncodeValueCursor(member.LastName, member.ID)
+	case "slug":
+		return EncodeValueCursor(member.Slug, member.ID)
+	default:
+		return EncodeCursor(member.CreatedAt, member.ID)
+	}
+}
+
 func (mq *MemberRepository) Get(ctx context.Context, filter MemberRepositoryFilter) (*Member, error) {
 	query, args, err := mq.buildQuery(filter, QueryOptions{})
 	if err != nil {
@@ -113,6 +199,22 @@ func (mq *MemberRepository) Exists(ctx context.Context, filter MemberRepositoryF
 	return count > 0, nil
 }
 
+// Count returns how many members match filter, ignoring pagination.
+func (mq *MemberRepository) Count(ctx context.Context, filter MemberRepositoryFilter) (int64, error) {
+	query, args, err := mq.buildQuery(filter, QueryOptions{
+		Type: lo.ToPtr(QueryTypeCount),
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := mq.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
 func (mq *MemberRepository) Create(ctx context.Context, member *Member, tx *sqlx.Tx) (*Member, error) {
 	builder := mq.psql.Insert("members").
 		Columns("user_id", "slug", "first_name", "last_name", "phone", "address", "next_of_kin_name", "next_of_kin_phone").
@@ -134,6 +236,33 @@ func (mq *MemberRepository) Create(ctx context.Context, member *Member, tx *sqlx
 	return &createdMember, err
 }
 
+// AllocateSlug draws the next member number from member_number_seq and
+// returns it rendered by format. Sequence values are never handed out twice,
+// so concurrent allocations cannot collide; numbers whose slug is already
+// taken by a legacy member are skipped.
+func (mq *MemberRepository) AllocateSlug(ctx context.Context, format func(number int64) string, tx *sqlx.Tx) (string, error) {
+	var q sqlx.QueryerContext = mq.db
+	if tx != nil {
+		q = tx
+	}
+
+	for {
+		var number int64
+		if err := sqlx.GetContext(ctx, q, &number, "SELECT nextval('member_number_seq')"); err != nil {
+			return "", err
+		}
+
+		slug := format(number)
+		var taken bool
+		if err := sqlx.GetContext(ctx, q, &taken, "SELECT EXISTS (SELECT 1 FROM members WHERE slug = $1)", slug); err != nil {
+			return "", err
+		}
+		if !taken {
+			return slug, nil
+		}
+	}
+}
+
 func (mq *MemberRepository) Update(ctx context.Context, member *Member, tx *sqlx.Tx) (*Member, error) {
 	builder := mq.psql.Update("members").
 		Set("first_name", member.FirstName).
@@ -162,6 +291,77 @@ func (mq *MemberRepository) Update(ctx context.Context, member *Member, tx *sqlx
 	return &updatedMember, err
 }
 
+// UpdateInvitation records the outcome of an onboarding invitation attempt.
+func (mq *MemberRepository) UpdateInvitation(ctx context.Context, member *Member, tx *sqlx.Tx) (*Member, error) {
+	builder := mq.psql.Update("members").
+		Set("invitation_status", member.InvitationStatus).
+		Set("invitation_sent_at", member.InvitationSentAt).
+		Set("invitation_count", member.InvitationCount).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": member.ID}).
+		Suffix("RETURNING *")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var updatedMember Member
+	if tx != nil {
+		err = tx.GetContext(ctx, &updatedMember, query, args...)
+		return &updatedMember, err
+	}
+
+	err = mq.db.GetContext(ctx, &updatedMember, query, args...)
+	return &updatedMember, err
+}
+
+// ExistingPhones returns which of phones are already held by a member,
+// including soft-deleted members, whose numbers stay reserved by the unique
+// index on phone.
+func (mq *MemberRepository) ExistingPhones(ctx context.Context, phones []string) ([]string, error) {
+	if len(phones) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := mq.psql.Select("phone").
+		From("members").
+		Where(sq.Eq{"phone": phones}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []string
+	if err := mq.db.SelectContext(ctx, &existing, query, args...); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
+// MarkInvitationAccepted flags the member owned by userID as onboarded.
+func (mq *MemberRepository) MarkInvitationAccepted(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) error {
+	builder := mq.psql.Update("members").
+		Set("invitation_status", InvitationStatusAccepted).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"user_id": userID}).
+		Where("deleted_at IS NULL")
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	_, err = mq.db.ExecContext(ctx, query, args...)
+	return err
+}
+
 func (mq *MemberRepository) List(ctx context.Context, filter MemberRepositoryFilter, opts QueryOptions) (*ListResult[Member], error) {
 	query, args, err := mq.buildQuery(filter, opts)
 	if err != nil {
@@ -181,7 +381,8 @@ func (mq *MemberRepository) List(ctx context.Context, filter MemberRepositoryFil
 	if len(members) > int(opts.Limit) {
 		lastItem := lo.LastOr(members, nil)
 		if lastItem != nil {
-			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			sortResult, _ := parseSort(opts.Sort)
+			nextCursor := mq.cursor(lastItem, sortResult.Column)
 			listResult.NextCursor = &nextCursor
 		}
 	}
@@ -194,19 +395,23 @@ func (mq *MemberRepository) MapRepositoryToDTOModel(member *Member) *dto.Member
 		return nil
 	}
 
-	isActive := false
+	out := &dto.Member{
+		ID:               member.ID,
+		FirstName:        member.FirstName,
+		LastName:         member.LastName,
+		Phone:            member.Phone,
+		Slug:             member.Slug,
+		Address:          member.Address.String,
+		NextOfKinName:    member.NextOfKinName.String,
+		NextOfKinPhone:   member.NextOfKinPhone.String,
+		IsActive:         member.Status == MemberStatusActive,
+		Status:           dto.MemberStatus(strings.ToLower(member.Status)),
+		InvitationStatus: member.InvitationStatus,
+		JoinedAt:         member.CreatedAt,
+	}
 	if member.ActivatedAt.Valid {
-		isActive = true
-	}
-	return &dto.Member{
-		ID:             member.ID,
-		FirstName:      member.FirstName,
-		LastName:       member.LastName,
-		Phone:          member.Phone,
-		Slug:           member.Slug,
-		Address:        member.Address.String,
-		NextOfKinName:  member.NextOfKinName.String,
-		NextOfKinPhone: member.NextOfKinPhone.String,
-		IsActive:       isActive,
+		out.ActivatedAt = &member.ActivatedAt.Time
 	}
+
+	return out
 }
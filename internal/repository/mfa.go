@@ -0,0 +1,185 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+type MFARepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewMFARepository is the constructor for MFARepository.
+func NewMFARepository(db *sqlx.DB) *MFARepository {
+	return &MFARepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Get returns the TOTP enrollment of a user, enabled or not.
+func (mr *MFARepository) Get(ctx context.Context, userID uuid.UUID) (*UserMfa, error) {
+	query, args, err := mr.psql.Select("*").
+		From("user_mfa").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var mfa UserMfa
+	if err := mr.db.GetContext(ctx, &mfa, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &mfa, nil
+}
+
+// IsEnabled reports whether the user completed TOTP enrollment.
+func (mr *MFARepository) IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error) {
+	query, args, err := mr.psql.Select("COUNT(*)").
+		From("user_mfa").
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.NotEq{"enabled_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var count int
+	if err := mr.db.GetContext(ctx, &count, query, args...); err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// UpsertPending stores a new, not yet verified secret. Enrollment is restarted
+// if the user never verified the previous one.
+func (mr *MFARepository) UpsertPending(ctx context.Context, userID uuid.UUID, secretEncrypted string, tx *sqlx.Tx) (*UserMfa, error) {
+	query, args, err := mr.psql.Insert("user_mfa").
+		Columns("user_id", "secret_encrypted", "enabled_at", "last_used_step", "created_at", "updated_at").
+		Values(userID, secretEncrypted, nil, 0, time.Now(), time.Now()).
+		Suffix("ON CONFLICT (user_id) DO UPDATE SET secret_encrypted = EXCLUDED.secret_encrypted, enabled_at = NULL, last_used_step = 0, updated_at = EXCLUDED.updated_at RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var mfa UserMfa
+	if tx != nil {
+		err = tx.GetContext(ctx, &mfa, query, args...)
+		return &mfa, err
+	}
+
+	err = mr.db.GetContext(ctx, &mfa, query, args...)
+	return &mfa, err
+}
+
+// Enable marks the enrollment as verified.
+func (mr *MFARepository) Enable(ctx context.Context, userID uuid.UUID, step int64, tx *sqlx.Tx) error {
+	builder := mr.psql.Update("user_mfa").
+		Set("enabled_at", time.Now()).
+		Set("last_used_step", step).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"user_id": userID})
+
+	return mr.exec(ctx, builder, tx)
+}
+
+// ConsumeStep records step as used. It returns false when the step (or a
+// later one) was already used, which means the code is being replayed.
+func (mr *MFARepository) ConsumeStep(ctx context.Context, userID uuid.UUID, step int64, tx *sqlx.Tx) (bool, error) {
+	query, args, err := mr.psql.Update("user_mfa").
+		Set("last_used_step", step).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"user_id": userID}).
+		Where(sq.Lt{"last_used_step": step}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var execer sqlx.ExecerContext = mr.db
+	if tx != nil {
+		execer = tx
+	}
+
+	res, err := execer.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+// ReplaceRecoveryCodes discards every existing recovery code of the user and stores the new hashes.
+func (mr *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string, tx *sqlx.Tx) error {
+	if err := mr.exec(ctx, mr.psql.Delete("mfa_recovery_codes").Where(sq.Eq{"user_id": userID}), tx); err != nil {
+		return err
+	}
+
+	if len(codeHashes) == 0 {
+		return nil
+	}
+
+	builder := mr.psql.Insert("mfa_recovery_codes").Columns("user_id", "code_hash", "created_at")
+	for _, hash := range codeHashes {
+		builder = builder.Values(userID, hash, time.Now())
+	}
+
+	return mr.exec(ctx, builder, tx)
+}
+
+// UseRecoveryCode burns an unused recovery code. It returns false when the code is unknown or already used.
+func (mr *MFARepository) UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, tx *sqlx.Tx) (bool, error) {
+	query, args, err := mr.psql.Update("mfa_recovery_codes").
+		Set("used_at", time.Now()).
+		Where(sq.Eq{"user_id": userID, "code_hash": codeHash, "used_at": nil}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	var execer sqlx.ExecerContext = mr.db
+	if tx != nil {
+		execer = tx
+	}
+
+	res, err := execer.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows > 0, nil
+}
+
+func (mr *MFARepository) exec(ctx context.Context, builder sq.Sqlizer, tx *sqlx.Tx) error {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	_, err = mr.db.ExecContext(ctx, query, args...)
+	return err
+}
@@ -7,10 +7,12 @@ package repository
 import (
 	"database/sql"
 	"database/sql/driver"
+	"encoding/json"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
 type LedgerType string
@@ -103,33 +105,193 @@ func (ns NullTransactionType) Value() (driver.Value, error) {
 	return string(ns.TransactionType), nil
 }
 
+type DomainEvent struct {
+	ID            uuid.UUID       `json:"id"`
+	EventType     string          `json:"event_type"`
+	Payload       json.RawMessage `json:"payload"`
+	Status        string          `json:"status"`
+	HandledBy     pq.StringArray  `json:"handled_by"`
+	Attempts      int32           `json:"attempts"`
+	MaxAttempts   int32           `json:"max_attempts"`
+	NextAttemptAt time.Time       `json:"next_attempt_at"`
+	LastError     sql.NullString  `json:"last_error"`
+	ProcessedAt   sql.NullTime    `json:"processed_at"`
+	UpdatedAt     sql.NullTime    `json:"updated_at"`
+	CreatedAt     time.Time       `json:"created_at"`
+}
+
+type EmailOutbox struct {
+	ID            uuid.UUID      `json:"id"`
+	Recipient     string         `json:"recipient"`
+	Subject       string         `json:"subject"`
+	Body          string         `json:"body"`
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	MaxAttempts   int32          `json:"max_attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	ReferenceType sql.NullString `json:"reference_type"`
+	ReferenceID   uuid.NullUUID  `json:"reference_id"`
+	SentAt        sql.NullTime   `json:"sent_at"`
+	UpdatedAt     sql.NullTime   `json:"updated_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Template      sql.NullString `json:"template"`
+	TextBody      sql.NullString `json:"text_body"`
+	Sensitive     bool           `json:"sensitive"`
+	RedactedAt    sql.NullTime   `json:"redacted_at"`
+}
+
 type Fine struct {
-	ID            uuid.UUID     `json:"id"`
-	AdminID       uuid.UUID     `json:"admin_id"`
-	MemberID      uuid.UUID     `json:"member_id"`
-	TransactionID uuid.NullUUID `json:"transaction_id"`
-	Amount        int64         `json:"amount"`
-	Reason        string        `json:"reason"`
-	Deadline      time.Time     `json:"deadline"`
-	PaidAt        sql.NullTime  `json:"paid_at"`
-	CreatedAt     time.Time     `json:"created_at"`
-	UpdatedAt     sql.NullTime  `json:"updated_at"`
+	ID             uuid.UUID     `json:"id"`
+	AdminID        uuid.UUID     `json:"admin_id"`
+	MemberID       uuid.UUID     `json:"member_id"`
+	TransactionID  uuid.NullUUID `json:"transaction_id"`
+	Amount         int64         `json:"amount"`
+	Reason         string        `json:"reason"`
+	Deadline       time.Time     `json:"deadline"`
+	PaidAt         sql.NullTime  `json:"paid_at"`
+	CreatedAt      time.Time     `json:"created_at"`
+	UpdatedAt      sql.NullTime  `json:"updated_at"`
+	ReminderSentAt sql.NullTime  `json:"reminder_sent_at"`
+	OpeningBalance bool          `json:"opening_balance"`
+}
+
+type JobRun struct {
+	ID           uuid.UUID      `json:"id"`
+	JobName      string         `json:"job_name"`
+	Source       string         `json:"source"`
+	Status       string         `json:"status"`
+	ScheduledFor sql.NullTime   `json:"scheduled_for"`
+	TriggeredBy  uuid.NullUUID  `json:"triggered_by"`
+	StartedAt    sql.NullTime   `json:"started_at"`
+	FinishedAt   sql.NullTime   `json:"finished_at"`
+	Error        sql.NullString `json:"error"`
+	CreatedAt    time.Time      `json:"created_at"`
 }
 
 type Member struct {
-	ID             uuid.UUID      `json:"id"`
-	UserID         uuid.UUID      `json:"user_id"`
-	Slug           string         `json:"slug"`
-	FirstName      string         `json:"first_name"`
-	LastName       string         `json:"last_name"`
-	Phone          string         `json:"phone"`
-	Address        sql.NullString `json:"address"`
-	NextOfKinName  sql.NullString `json:"next_of_kin_name"`
-	NextOfKinPhone sql.NullString `json:"next_of_kin_phone"`
-	ActivatedAt    sql.NullTime   `json:"activated_at"`
-	CreatedAt      time.Time      `json:"created_at"`
-	UpdatedAt      sql.NullTime   `json:"updated_at"`
-	DeletedAt      sql.NullTime   `json:"deleted_at"`
+	ID               uuid.UUID      `json:"id"`
+	UserID           uuid.UUID      `json:"user_id"`
+	Slug             string         `json:"slug"`
+	FirstName        string         `json:"first_name"`
+	LastName         string         `json:"last_name"`
+	Phone            string         `json:"phone"`
+	Address          sql.NullString `json:"address"`
+	NextOfKinName    sql.NullString `json:"next_of_kin_name"`
+	NextOfKinPhone   sql.NullString `json:"next_of_kin_phone"`
+	ActivatedAt      sql.NullTime   `json:"activated_at"`
+	CreatedAt        time.Time      `json:"created_at"`
+	UpdatedAt        sql.NullTime   `json:"updated_at"`
+	DeletedAt        sql.NullTime   `json:"deleted_at"`
+	InvitationStatus string         `json:"invitation_status"`
+	InvitationSentAt sql.NullTime   `json:"invitation_sent_at"`
+	InvitationCount  int32          `json:"invitation_count"`
+	Status           string         `json:"status"`
+}
+
+type MemberBeneficiary struct {
+	ID           uuid.UUID      `json:"id"`
+	MemberID     uuid.UUID      `json:"member_id"`
+	Name         string         `json:"name"`
+	Relationship string         `json:"relationship"`
+	Phone        sql.NullString `json:"phone"`
+	Percentage   string         `json:"percentage"`
+	NextOfKin    bool           `json:"next_of_kin"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    sql.NullTime   `json:"updated_at"`
+}
+
+type MemberChangeRequest struct {
+	ID          uuid.UUID       `json:"id"`
+	MemberID    uuid.UUID       `json:"member_id"`
+	Changes     json.RawMessage `json:"changes"`
+	Status      string          `json:"status"`
+	RequestedBy uuid.UUID       `json:"requested_by"`
+	ReviewedBy  uuid.NullUUID   `json:"reviewed_by"`
+	ReviewNote  sql.NullString  `json:"review_note"`
+	ReviewedAt  sql.NullTime    `json:"reviewed_at"`
+	CreatedAt   time.Time       `json:"created_at"`
+}
+
+type MemberDocument struct {
+	ID           uuid.UUID      `json:"id"`
+	MemberID     uuid.UUID      `json:"member_id"`
+	Type         string         `json:"type"`
+	StorageKey   string         `json:"storage_key"`
+	ContentType  string         `json:"content_type"`
+	SizeBytes    int64          `json:"size_bytes"`
+	OriginalName string         `json:"original_name"`
+	Status       string         `json:"status"`
+	UploadedBy   uuid.UUID      `json:"uploaded_by"`
+	ReviewedBy   uuid.NullUUID  `json:"reviewed_by"`
+	ReviewNote   sql.NullString `json:"review_note"`
+	ReviewedAt   sql.NullTime   `json:"reviewed_at"`
+	CreatedAt    time.Time      `json:"created_at"`
+}
+
+type MemberFieldChange struct {
+	ID              uuid.UUID      `json:"id"`
+	MemberID        uuid.UUID      `json:"member_id"`
+	Field           string         `json:"field"`
+	OldValue        sql.NullString `json:"old_value"`
+	NewValue        sql.NullString `json:"new_value"`
+	ChangedBy       uuid.NullUUID  `json:"changed_by"`
+	ChangeRequestID uuid.NullUUID  `json:"change_request_id"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
+
+type MemberSettlement struct {
+	ID               uuid.UUID       `json:"id"`
+	MemberID         uuid.UUID       `json:"member_id"`
+	Savings          int64           `json:"savings"`
+	SpecialDeposit   int64           `json:"special_deposit"`
+	ShareUnits       string          `json:"share_units"`
+	ShareUnitPrice   int64           `json:"share_unit_price"`
+	ShareValue       int64           `json:"share_value"`
+	UnpaidFines      int64           `json:"unpaid_fines"`
+	OutstandingLoans int64           `json:"outstanding_loans"`
+	NetAmount        int64           `json:"net_amount"`
+	SettledBy        uuid.UUID       `json:"settled_by"`
+	CreatedAt        time.Time       `json:"created_at"`
+	Beneficiaries    json.RawMessage `json:"beneficiaries"`
+}
+
+type MemberStatusChange struct {
+	ID         uuid.UUID     `json:"id"`
+	MemberID   uuid.UUID     `json:"member_id"`
+	FromStatus string        `json:"from_status"`
+	ToStatus   string        `json:"to_status"`
+	Reason     string        `json:"reason"`
+	ChangedBy  uuid.NullUUID `json:"changed_by"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+type MfaRecoveryCode struct {
+	ID        uuid.UUID    `json:"id"`
+	UserID    uuid.UUID    `json:"user_id"`
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type Notification struct {
+	ID            uuid.UUID      `json:"id"`
+	UserID        uuid.UUID      `json:"user_id"`
+	Event         string         `json:"event"`
+	Title         string         `json:"title"`
+	Body          string         `json:"body"`
+	ReferenceType sql.NullString `json:"reference_type"`
+	ReferenceID   uuid.NullUUID  `json:"reference_id"`
+	ReadAt        sql.NullTime   `json:"read_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+}
+
+type NotificationPreference struct {
+	UserID    uuid.UUID `json:"user_id"`
+	Event     string    `json:"event"`
+	Channel   string    `json:"channel"`
+	Enabled   bool      `json:"enabled"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 type Permission struct {
@@ -153,6 +315,24 @@ type Share struct {
 	CreatedAt     time.Time `json:"created_at"`
 }
 
+type SmsOutbox struct {
+	ID            uuid.UUID      `json:"id"`
+	Recipient     string         `json:"recipient"`
+	Body          string         `json:"body"`
+	Status        string         `json:"status"`
+	Attempts      int32          `json:"attempts"`
+	MaxAttempts   int32          `json:"max_attempts"`
+	NextAttemptAt time.Time      `json:"next_attempt_at"`
+	LastError     sql.NullString `json:"last_error"`
+	ReferenceType sql.NullString `json:"reference_type"`
+	ReferenceID   uuid.NullUUID  `json:"reference_id"`
+	SentAt        sql.NullTime   `json:"sent_at"`
+	UpdatedAt     sql.NullTime   `json:"updated_at"`
+	CreatedAt     time.Time      `json:"created_at"`
+	Sensitive     bool           `json:"sensitive"`
+	RedactedAt    sql.NullTime   `json:"redacted_at"`
+}
+
 type Token struct {
 	ID        uuid.UUID    `json:"id"`
 	UserID    uuid.UUID    `json:"user_id"`
@@ -166,15 +346,16 @@ type Token struct {
 }
 
 type Transaction struct {
-	ID          uuid.UUID       `json:"id"`
-	MemberID    uuid.UUID       `json:"member_id"`
-	Description string          `json:"description"`
-	Reference   string          `json:"reference"`
-	Amount      int64           `json:"amount"`
-	Type        TransactionType `json:"type"`
-	Ledger      LedgerType      `json:"ledger"`
-	CreatedAt   sql.NullTime    `json:"created_at"`
-	UpdatedAt   sql.NullTime    `json:"updated_at"`
+	ID             uuid.UUID       `json:"id"`
+	MemberID       uuid.UUID       `json:"member_id"`
+	Description    string          `json:"description"`
+	Reference      string          `json:"reference"`
+	Amount         int64           `json:"amount"`
+	Type           TransactionType `json:"type"`
+	Ledger         LedgerType      `json:"ledger"`
+	CreatedAt      sql.NullTime    `json:"created_at"`
+	UpdatedAt      sql.NullTime    `json:"updated_at"`
+	OpeningBalance bool            `json:"opening_balance"`
 }
 
 type TransactionStatus struct {
@@ -195,6 +376,16 @@ type User struct {
 	DeletedAt        sql.NullTime   `json:"deleted_at"`
 }
 
+type UserMfa struct {
+	ID              uuid.UUID    `json:"id"`
+	UserID          uuid.UUID    `json:"user_id"`
+	SecretEncrypted string       `json:"secret_encrypted"`
+	EnabledAt       sql.NullTime `json:"enabled_at"`
+	LastUsedStep    int64        `json:"last_used_step"`
+	UpdatedAt       sql.NullTime `json:"updated_at"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
 type UserPermission struct {
 	ID           uuid.UUID `json:"id"`
 	UserID       uuid.UUID `json:"user_id"`
@@ -208,3 +399,32 @@ type UserRole struct {
 	RoleID    uuid.UUID `json:"role_id"`
 	CreatedAt time.Time `json:"created_at"`
 }
+
+type WebhookDelivery struct {
+	ID             uuid.UUID       `json:"id"`
+	EndpointID     uuid.UUID       `json:"endpoint_id"`
+	EventID        uuid.UUID       `json:"event_id"`
+	EventType      string          `json:"event_type"`
+	Payload        json.RawMessage `json:"payload"`
+	Status         string          `json:"status"`
+	Attempts       int32           `json:"attempts"`
+	MaxAttempts    int32           `json:"max_attempts"`
+	NextAttemptAt  time.Time       `json:"next_attempt_at"`
+	ResponseStatus sql.NullInt32   `json:"response_status"`
+	LastError      sql.NullString  `json:"last_error"`
+	DeliveredAt    sql.NullTime    `json:"delivered_at"`
+	UpdatedAt      sql.NullTime    `json:"updated_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+}
+
+type WebhookEndpoint struct {
+	ID              uuid.UUID      `json:"id"`
+	Url             string         `json:"url"`
+	Description     sql.NullString `json:"description"`
+	SecretEncrypted string         `json:"secret_encrypted"`
+	EventTypes      pq.StringArray `json:"event_types"`
+	IsActive        bool           `json:"is_active"`
+	CreatedBy       uuid.UUID      `json:"created_by"`
+	UpdatedAt       sql.NullTime   `json:"updated_at"`
+	CreatedAt       time.Time      `json:"created_at"`
+}
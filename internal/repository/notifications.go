@@ -0,0 +1,218 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+type NotificationRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewNotificationRepository is the constructor for NotificationRepository.
+func NewNotificationRepository(db *sqlx.DB) *NotificationRepository {
+	return &NotificationRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+type NotificationRepositoryFilter struct {
+	ID     *uuid.UUID
+	UserID *uuid.UUID
+	Unread *bool
+}
+
+func (nr *NotificationRepository) applyFilter(builder sq.SelectBuilder, filter NotificationRepositoryFilter) sq.SelectBuilder {
+	if filter.ID != nil {
+		builder = builder.Where(sq.Eq{"id": *filter.ID})
+	}
+	if filter.UserID != nil {
+		builder = builder.Where(sq.Eq{"user_id": *filter.UserID})
+	}
+	if filter.Unread != nil {
+		if *filter.Unread {
+			builder = builder.Where(sq.Eq{"read_at": nil})
+		} else {
+			builder = builder.Where(sq.NotEq{"read_at": nil})
+		}
+	}
+
+	return builder
+}
+
+// Create adds a notification to the user's inbox.
+func (nr *NotificationRepository) Create(ctx context.Context, notification *Notification, tx *sqlx.Tx) (*Notification, error) {
+	query, args, err := nr.psql.Insert("notifications").
+		Columns("user_id", "event", "title", "body", "reference_type", "reference_id", "created_at").
+		Values(notification.UserID, notification.Event, notification.Title, notification.Body, notification.ReferenceType, notification.ReferenceID, time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created Notification
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = nr.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+// ListPreferences returns the preferences the user has set. Event and channel
+// pairs without a row are enabled.
+func (nr *NotificationRepository) ListPreferences(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) ([]NotificationPreference, error) {
+	query, args, err := nr.psql.Select("*").
+		From("notification_preferences").
+		Where(sq.Eq{"user_id": userID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var preferences []NotificationPreference
+	if tx != nil {
+		err = tx.SelectContext(ctx, &preferences, query, args...)
+		return preferences, err
+	}
+
+	err = nr.db.SelectContext(ctx, &preferences, query, args...)
+	return preferences, err
+}
+
+func (nr *NotificationRepository) UpsertPreference(ctx context.Context, preference *NotificationPreference, tx *sqlx.Tx) error {
+	query, args, err := nr.psql.Insert("notification_preferences").
+		Columns("user_id", "event", "channel", "enabled", "updated_at").
+		Values(preference.UserID, preference.Event, preference.Channel, preference.Enabled, time.Now()).
+		Suffix("ON CONFLICT (user_id, event, channel) DO UPDATE SET enabled = EXCLUDED.enabled, updated_at = EXCLUDED.updated_at").
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	_, err = nr.db.ExecContext(ctx, query, args...)
+	return err
+}
+
+func (nr *NotificationRepository) List(ctx context.Context, filter NotificationRepositoryFilter, opts QueryOptions) (*ListResult[Notification], error) {
+	builder := nr.applyFilter(nr.psql.Select("*").From("notifications"), filter)
+	builder, err := ApplyPagination(builder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var notifications []Notification
+	if err := nr.db.SelectContext(ctx, &notifications, query, args...); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(notifications, func(n Notification, _ int) *Notification { return &n })
+	listResult := ListResult[Notification]{
+		Items: lo.Slice(items, 0, min(len(items), int(opts.Limit))),
+	}
+
+	if len(items) > int(opts.Limit) {
+		lastItem := lo.LastOr(items, nil)
+		if lastItem != nil {
+			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			listResult.NextCursor = &nextCursor
+		}
+	}
+
+	return &listResult, nil
+}
+
+func (nr *NotificationRepository) Count(ctx context.Context, filter NotificationRepositoryFilter) (int64, error) {
+	query, args, err := nr.applyFilter(nr.psql.Select("COUNT(*)").From("notifications"), filter).ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	if err := nr.db.GetContext(ctx, &count, query, args...); err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// MarkRead marks one of the user's notifications as read. Reading it again
+// keeps the first read time.
+func (nr *NotificationRepository) MarkRead(ctx context.Context, id, userID uuid.UUID) (*Notification, error) {
+	query, args, err := nr.psql.Update("notifications").
+		Set("read_at", sq.Expr("COALESCE(read_at, ?)", time.Now())).
+		Where(sq.Eq{"id": id, "user_id": userID}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var notification Notification
+	if err := nr.db.GetContext(ctx, &notification, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &notification, nil
+}
+
+// MarkAllRead marks every unread notification of the user as read and
+// returns how many there were.
+func (nr *NotificationRepository) MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error) {
+	query, args, err := nr.psql.Update("notifications").
+		Set("read_at", time.Now()).
+		Where(sq.Eq{"user_id": userID, "read_at": nil}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := nr.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
+
+func (nr *NotificationRepository) MapRepositoryToDTOModel(notification *Notification) *dto.Notification {
+	out := &dto.Notification{
+		ID:        notification.ID,
+		Event:     notification.Event,
+		Title:     notification.Title,
+		Body:      notification.Body,
+		Read:      notification.ReadAt.Valid,
+		CreatedAt: notification.CreatedAt,
+	}
+	if notification.ReferenceType.Valid {
+		out.ReferenceType = &notification.ReferenceType.String
+	}
+	if notification.ReferenceID.Valid {
+		out.ReferenceID = &notification.ReferenceID.UUID
+	}
+	if notification.ReadAt.Valid {
+		out.ReadAt = &notification.ReadAt.Time
+	}
+
+	return out
+}
@@ -0,0 +1,95 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"strconv"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+// SettlementRepository stores the statements members were paid out on when
+// they left the cooperative.
+type SettlementRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+func NewSettlementRepository(db *sqlx.DB) *SettlementRepository {
+	return &SettlementRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+func (s *SettlementRepository) Create(ctx context.Context, settlement *MemberSettlement, tx *sqlx.Tx) (*MemberSettlement, error) {
+	beneficiaries := settlement.Beneficiaries
+	if len(beneficiaries) == 0 {
+		beneficiaries = json.RawMessage("[]")
+	}
+
+	query, args, err := s.psql.Insert("member_settlements").
+		Columns("member_id", "savings", "special_deposit", "share_units", "share_unit_price", "share_value",
+			"unpaid_fines", "outstanding_loans", "net_amount", "settled_by", "beneficiaries", "created_at").
+		Values(settlement.MemberID, settlement.Savings, settlement.SpecialDeposit, settlement.ShareUnits, settlement.ShareUnitPrice,
+			settlement.ShareValue, settlement.UnpaidFines, settlement.OutstandingLoans, settlement.NetAmount, settlement.SettledBy,
+			string(beneficiaries), time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created MemberSettlement
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = s.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+func (s *SettlementRepository) GetByMember(ctx context.Context, memberID uuid.UUID) (*MemberSettlement, error) {
+	query, args, err := s.psql.Select("*").
+		From("member_settlements").
+		Where(sq.Eq{"member_id": memberID}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var settlement MemberSettlement
+	if err := s.db.GetContext(ctx, &settlement, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &settlement, nil
+}
+
+// MapRepositoryToDTOModel maps a posted settlement. Statements that have not
+// been posted are built in the service, which also decodes Beneficiaries.
+func (s *SettlementRepository) MapRepositoryToDTOModel(settlement *MemberSettlement) *dto.MemberSettlement {
+	units, _ := strconv.ParseFloat(settlement.ShareUnits, 64)
+
+	out := &dto.MemberSettlement{
+		MemberID:         settlement.MemberID,
+		Savings:          settlement.Savings,
+		SpecialDeposit:   settlement.SpecialDeposit,
+		ShareUnits:       units,
+		ShareUnitPrice:   settlement.ShareUnitPrice,
+		ShareValue:       settlement.ShareValue,
+		UnpaidFines:      settlement.UnpaidFines,
+		OutstandingLoans: settlement.OutstandingLoans,
+		NetAmount:        settlement.NetAmount,
+		Settled:          true,
+		SettledBy:        &settlement.SettledBy,
+		SettledAt:        &settlement.CreatedAt,
+	}
+
+	return out
+}
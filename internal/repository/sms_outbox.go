@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	SMSOutboxStatusPending = "PENDING"
+	SMSOutboxStatusSent    = "SENT"
+	// SMSOutboxStatusDead marks messages that ran out of attempts.
+	SMSOutboxStatusDead = "DEAD"
+)
+
+type SMSOutboxRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewSMSOutboxRepository is the constructor for SMSOutboxRepository.
+func NewSMSOutboxRepository(db *sqlx.DB) *SMSOutboxRepository {
+	return &SMSOutboxRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+// Create queues a message. Pass the transaction of the business change so the
+// message is only sent if that change commits.
+func (sr *SMSOutboxRepository) Create(ctx context.Context, msg *SmsOutbox, tx *sqlx.Tx) (*SmsOutbox, error) {
+	query, args, err := sr.psql.Insert("sms_outbox").
+		Columns("recipient", "body", "sensitive", "status", "max_attempts", "next_attempt_at", "reference_type", "reference_id", "created_at").
+		Values(msg.Recipient, msg.Body, msg.Sensitive, SMSOutboxStatusPending, msg.MaxAttempts, time.Now(), msg.ReferenceType, msg.ReferenceID, time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created SmsOutbox
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = sr.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+// ClaimDue leases up to limit due messages, see EmailOutboxRepository.ClaimDue.
+func (sr *SMSOutboxRepository) ClaimDue(ctx context.Context, limit uint64, lease time.Duration) ([]SmsOutbox, error) {
+	now := time.Now()
+	query, args, err := sr.psql.Update("sms_outbox").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", now.Add(lease)).
+		Set("updated_at", now).
+		Where(
+			"id IN (SELECT id FROM sms_outbox WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED)",
+			SMSOutboxStatusPending, now, limit,
+		).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []SmsOutbox
+	if err := sr.db.SelectContext(ctx, &claimed, query, args...); err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+// MarkSent records the delivery and wipes the body of a sensitive message.
+func (sr *SMSOutboxRepository) MarkSent(ctx context.Context, id uuid.UUID) error {
+	return sr.exec(ctx, redactSensitiveSMS(sr.psql.Update("sms_outbox").
+		Set("status", SMSOutboxStatusSent).
+		Set("sent_at", time.Now()).
+		Set("last_error", nil).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})))
+}
+
+// MarkFailed records a failed attempt. A zero nextAttemptAt dead-letters the
+// message, wiping its body when it is sensitive.
+func (sr *SMSOutboxRepository) MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error {
+	builder := sr.psql.Update("sms_outbox").
+		Set("last_error", lastError).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})
+
+	if nextAttemptAt.IsZero() {
+		builder = redactSensitiveSMS(builder.Set("status", SMSOutboxStatusDead))
+	} else {
+		builder = builder.Set("next_attempt_at", nextAttemptAt)
+	}
+
+	return sr.exec(ctx, builder)
+}
+
+// redactSensitiveSMS wipes the body of a sensitive text, which holds a
+// one-time code, once it is no longer needed for sending.
+func redactSensitiveSMS(builder sq.UpdateBuilder) sq.UpdateBuilder {
+	return builder.
+		Set("body", sq.Expr("CASE WHEN sensitive THEN '' ELSE body END")).
+		Set("redacted_at", sq.Expr("CASE WHEN sensitive THEN NOW() ELSE redacted_at END"))
+}
+
+func (sr *SMSOutboxRepository) exec(ctx context.Context, builder sq.Sqlizer) error {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = sr.db.ExecContext(ctx, query, args...)
+	return err
+}
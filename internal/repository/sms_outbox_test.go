@@ -0,0 +1,68 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSMSOutboxRedaction(t *testing.T) {
+	db := testDB(t)
+	repo := NewSMSOutboxRepository(db)
+	ctx := context.Background()
+
+	queue := func(sensitive bool) *SmsOutbox {
+		t.Helper()
+		msg, err := repo.Create(ctx, &SmsOutbox{
+			Recipient:   "+2348012345678",
+			Body:        "ARA Coop: your code is 482913",
+			Sensitive:   sensitive,
+			MaxAttempts: 1,
+		}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { db.Exec("DELETE FROM sms_outbox WHERE id = $1", msg.ID) })
+		return msg
+	}
+
+	reload := func(msg *SmsOutbox) *SmsOutbox {
+		t.Helper()
+		var got SmsOutbox
+		if err := db.GetContext(ctx, &got, "SELECT * FROM sms_outbox WHERE id = $1", msg.ID); err != nil {
+			t.Fatal(err)
+		}
+		return &got
+	}
+
+	tests := []struct {
+		name      string
+		sensitive bool
+		finish    func(*SmsOutbox) error
+	}{
+		{"sent sensitive", true, func(msg *SmsOutbox) error { return repo.MarkSent(ctx, msg.ID) }},
+		{"dead sensitive", true, func(msg *SmsOutbox) error { return repo.MarkFailed(ctx, msg.ID, "refused", time.Time{}) }},
+		{"sent plain", false, func(msg *SmsOutbox) error { return repo.MarkSent(ctx, msg.ID) }},
+		{"dead plain", false, func(msg *SmsOutbox) error { return repo.MarkFailed(ctx, msg.ID, "refused", time.Time{}) }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			msg := queue(tt.sensitive)
+			if err := tt.finish(msg); err != nil {
+				t.Fatal(err)
+			}
+
+			got := reload(msg)
+			if tt.sensitive {
+				if got.Body != "" || !got.RedactedAt.Valid {
+					t.Errorf("sensitive text kept body %q, redacted %v", got.Body, got.RedactedAt.Valid)
+				}
+				return
+			}
+			if got.Body != msg.Body || got.RedactedAt.Valid {
+				t.Errorf("plain text was redacted: body %q", got.Body)
+			}
+		})
+	}
+}
@@ -80,9 +80,11 @@ func (tr *TokenRepository) buildQuery(filter *TokenRepositoryFilter, queryType Q
 	return builder, nil
 }
 
+// Update sets is_valid, and deleted_at when given, on the tokens matching the
+// ID, token, user and token type set on token. Fields left empty do not
+// filter, so callers must set enough of them to single out the rows they mean.
 func (tr *TokenRepository) Update(ctx context.Context, token *Token, tx *sqlx.Tx) error {
 	builder := tr.psql.Update("tokens").
-		Set("token_type", token.TokenType).
 		Set("is_valid", token.IsValid).
 		Set("updated_at", time.Now())
 
@@ -102,6 +104,10 @@ func (tr *TokenRepository) Update(ctx context.Context, token *Token, tx *sqlx.Tx
 		builder = builder.Where(sq.Eq{"user_id": token.UserID})
 	}
 
+	if token.TokenType != "" {
+		builder = builder.Where(sq.Eq{"token_type": token.TokenType})
+	}
+
 	query, args, err := builder.ToSql()
 	if err != nil {
 		return err
@@ -116,6 +122,27 @@ func (tr *TokenRepository) Update(ctx context.Context, token *Token, tx *sqlx.Tx
 	return err
 }
 
+// Invalidate marks the user's token of tokenType invalid, leaving their
+// other tokens alone.
+func (tr *TokenRepository) Invalidate(ctx context.Context, userID uuid.UUID, tokenType string, tx *sqlx.Tx) error {
+	query, args, err := tr.psql.Update("tokens").
+		Set("is_valid", false).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"user_id": userID, "token_type": tokenType}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	_, err = tr.db.ExecContext(ctx, query, args...)
+	return err
+}
+
 // Create invalidates existing refresh tokens for a user and stores a new one.
 func (tr *TokenRepository) Create(ctx context.Context, token *Token, tx *sqlx.Tx) (*Token, error) {
 	// Insert new refresh token.
@@ -180,3 +207,24 @@ func (tr *TokenRepository) Get(ctx context.Context, filter *TokenRepositoryFilte
 
 	return &token, nil
 }
+
+// ExpireUnused invalidates valid tokens of tokenType whose expiry has passed
+// and returns how many it invalidated.
+func (tr *TokenRepository) ExpireUnused(ctx context.Context, tokenType string) (int64, error) {
+	query, args, err := tr.psql.Update("tokens").
+		Set("is_valid", false).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"token_type": tokenType, "is_valid": true, "deleted_at": nil}).
+		Where(sq.Lt{"expires_at": time.Now()}).
+		ToSql()
+	if err != nil {
+		return 0, err
+	}
+
+	result, err := tr.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	return result.RowsAffected()
+}
@@ -26,13 +26,14 @@ func NewTransactionRepository(db *sqlx.DB) *TransactionRepository {
 }
 
 type TransactionRepositoryFilter struct {
-	ID         *uuid.UUID
-	MemberID   *uuid.UUID
-	StatusID   *uuid.UUID
-	Confirmed  *bool
-	Rejected   *bool
-	Type       *TransactionType
-	LedgerType *LedgerType
+	ID             *uuid.UUID
+	MemberID       *uuid.UUID
+	StatusID       *uuid.UUID
+	Confirmed      *bool
+	Rejected       *bool
+	Type           *TransactionType
+	LedgerType     *LedgerType
+	OpeningBalance *bool
 }
 
 type PopulatedTransaction struct {
@@ -43,17 +44,18 @@ type PopulatedTransaction struct {
 
 type populateTransactionFlat struct {
 	// transaction fields
-	TrID          uuid.UUID       `json:"tr_id"`
-	TrMemberID    uuid.UUID       `json:"tr_member_id"`
-	TrDescription string          `json:"tr_description"`
-	Tr_Reference  string          `json:"tr_reference"`
-	TrAmount      int64           `json:"tr_amount"`
-	TrType        TransactionType `json:"tr_type"`
-	TrLedgerType  LedgerType      `json:"tr_ledger_type"`
-	StatusID      uuid.UUID       `json:"tr_status_id"`
-	TrCreatedAt   *time.Time      `json:"tr_created_at"`
-	TrConfirmedAt *time.Time      `json:"tr_confirmed_at"`
-	TrRejectedAt  *time.Time      `json:"tr_rejected_at"`
+	TrID             uuid.UUID       `json:"tr_id"`
+	TrMemberID       uuid.UUID       `json:"tr_member_id"`
+	TrDescription    string          `json:"tr_description"`
+	Tr_Reference     string          `json:"tr_reference"`
+	TrAmount         int64           `json:"tr_amount"`
+	TrType           TransactionType `json:"tr_type"`
+	TrLedgerType     LedgerType      `json:"tr_ledger_type"`
+	StatusID         uuid.UUID       `json:"tr_status_id"`
+	TrCreatedAt      *time.Time      `json:"tr_created_at"`
+	TrOpeningBalance bool            `json:"tr_opening_balance"`
+	TrConfirmedAt    *time.Time      `json:"tr_confirmed_at"`
+	TrRejectedAt     *time.Time      `json:"tr_rejected_at"`
 
 	//status fields
 	TsID            uuid.UUID  `json:"ts_id"`
@@ -116,6 +118,10 @@ func (s *TransactionRepository) applyFilter(builder sq.SelectBuilder, filter Tra
 		builder = builder.Where(sq.Eq{"tr.ledger": *filter.LedgerType})
 	}
 
+	if filter.OpeningBalance != nil {
+		builder = builder.Where(sq.Eq{"tr.opening_balance": *filter.OpeningBalance})
+	}
+
 	return builder
 }
 
@@ -136,6 +142,7 @@ func (s *TransactionRepository) buildQuery(filter TransactionRepositoryFilter, o
 			"tr.type AS tr_type",
 			"tr.ledger AS tr_ledger_type",
 			"tr.created_at AS tr_created_at",
+			"tr.opening_balance AS tr_opening_balance",
 
 			// transaction status fields
 			"ts.id AS ts_id",
@@ -185,9 +192,17 @@ func (s *TransactionRepository) buildQuery(filter TransactionRepositoryFilter, o
 }
 
 func (t TransactionRepository) Create(ctx context.Context, transaction Transaction, tx *sqlx.Tx) (*Transaction, error) {
+	columns := []string{"member_id", "description", "reference", "amount", "type", "ledger", "opening_balance"}
+	values := []any{transaction.MemberID, transaction.Description, transaction.Reference, transaction.Amount, transaction.Type, transaction.Ledger, transaction.OpeningBalance}
+	// Backdated transactions, such as opening balances, set their own date
+	if transaction.CreatedAt.Valid {
+		columns = append(columns, "created_at")
+		values = append(values, transaction.CreatedAt.Time)
+	}
+
 	builder := t.psql.Insert("transactions").
-		Columns("member_id", "description", "reference", "amount", "type", "ledger").
-		Values(transaction.MemberID, transaction.Description, transaction.Reference, transaction.Amount, transaction.Type, transaction.Ledger).
+		Columns(columns...).
+		Values(values...).
 		Suffix("RETURNING *")
 
 	query, args, err := builder.ToSql()
@@ -324,6 +339,40 @@ func (s *TransactionRepository) GetBalance(ctx context.Context, filter Transacti
 	return balance, nil
 }
 
+// LedgerTotal is a member's confirmed balance on one ledger. Units is the net
+// number of shares held, zero on other ledgers.
+type LedgerTotal struct {
+	Ledger  LedgerType `json:"ledger"`
+	Balance int64      `json:"balance"`
+	Units   string     `json:"units"`
+}
+
+// LedgerTotals returns the member's confirmed balance on every ledger they
+// have transactions on, deposits less withdrawals, in a single query.
+func (s *TransactionRepository) LedgerTotals(ctx context.Context, memberID uuid.UUID) ([]LedgerTotal, error) {
+	query, args, err := s.psql.Select(
+		"tr.ledger",
+		"COALESCE(SUM(CASE WHEN tr.type = 'WITHDRAWAL' THEN -tr.amount ELSE tr.amount END), 0) AS balance",
+		"COALESCE(SUM(CASE WHEN tr.type = 'WITHDRAWAL' THEN -s.units ELSE s.units END), 0)::TEXT AS units",
+	).From("transactions tr").
+		Join("transaction_status ts ON tr.id = ts.transaction_id").
+		LeftJoin("shares s ON tr.id = s.transaction_id").
+		Where(sq.Eq{"tr.member_id": memberID}).
+		Where("ts.confirmed_at IS NOT NULL").
+		GroupBy("tr.ledger").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var totals []LedgerTotal
+	if err := s.db.SelectContext(ctx, &totals, query, args...); err != nil {
+		return nil, err
+	}
+
+	return totals, nil
+}
+
 func (s *TransactionRepository) UpdateStatus(ctx context.Context, transactionStatus TransactionStatus, tx *sqlx.Tx) (*TransactionStatus, error) {
 	builder := s.psql.Update("transaction_status").
 		Set("confirmed_at", transactionStatus.ConfirmedAt).
@@ -409,14 +458,15 @@ func (t *TransactionRepository) mapStatusToDTOModel(status *TransactionStatus) *
 
 func (s *TransactionRepository) mapFlatToPopulated(flat *populateTransactionFlat) *PopulatedTransaction {
 	transaction := Transaction{
-		ID:          flat.TrID,
-		MemberID:    flat.TrMemberID,
-		Description: flat.TrDescription,
-		Reference:   flat.Tr_Reference,
-		Amount:      flat.TrAmount,
-		Type:        flat.TrType,
-		Ledger:      flat.TrLedgerType,
-		CreatedAt:   ToNullTime(flat.TrCreatedAt),
+		ID:             flat.TrID,
+		MemberID:       flat.TrMemberID,
+		Description:    flat.TrDescription,
+		Reference:      flat.Tr_Reference,
+		Amount:         flat.TrAmount,
+		Type:           flat.TrType,
+		Ledger:         flat.TrLedgerType,
+		CreatedAt:      ToNullTime(flat.TrCreatedAt),
+		OpeningBalance: flat.TrOpeningBalance,
 	}
 
 	status := TransactionStatus{
@@ -453,14 +503,15 @@ func (s *TransactionRepository) mapFlatToPopulated(flat *populateTransactionFlat
 func (t *TransactionRepository) MapRepositoryToDTOModel(txn *PopulatedTransaction) *dto.Transactions {
 	if txn != nil {
 		return &dto.Transactions{
-			ID:          txn.Transaction.ID,
-			Description: txn.Transaction.Description,
-			Reference:   txn.Transaction.Reference,
-			Amount:      txn.Transaction.Amount,
-			Type:        *t.mapTypeToDTOModel(&txn.Transaction.Type),
-			LedgerType:  *t.mapLedgerToDTOModel(&txn.Transaction.Ledger),
-			Status:      *t.mapStatusToDTOModel(&txn.Status),
-			Member:      *t.memberRepository.MapRepositoryToDTOModel(&txn.Member),
+			ID:             txn.Transaction.ID,
+			Description:    txn.Transaction.Description,
+			Reference:      txn.Transaction.Reference,
+			Amount:         txn.Transaction.Amount,
+			Type:           *t.mapTypeToDTOModel(&txn.Transaction.Type),
+			LedgerType:     *t.mapLedgerToDTOModel(&txn.Transaction.Ledger),
+			Status:         *t.mapStatusToDTOModel(&txn.Status),
+			Member:         *t.memberRepository.MapRepositoryToDTOModel(&txn.Member),
+			OpeningBalance: txn.Transaction.OpeningBalance,
 		}
 	}
 
@@ -2,10 +2,13 @@ package repository
 
 import (
 	"context"
+	"strings"
+	"time"
 
 	sq "github.com/Masterminds/squirrel"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
 )
 
 type UserRepository struct {
@@ -22,7 +25,10 @@ func NewUserRepository(db *sqlx.DB) *UserRepository {
 
 type UserRepositoryFilter struct {
 	ID    *uuid.UUID
+	IDs   []uuid.UUID
 	Email *string
+	// Emails matches users with any of the addresses, ignoring case.
+	Emails []string
 }
 
 func (uq *UserRepository) buildQuery(filter UserRepositoryFilter, queryType QueryType) (string, []any, error) {
@@ -40,9 +46,17 @@ func (uq *UserRepository) buildQuery(filter UserRepositoryFilter, queryType Quer
 	if filter.ID != nil {
 		builder = builder.Where(sq.Eq{"id": *filter.ID})
 	}
+	if filter.IDs != nil {
+		builder = builder.Where(sq.Eq{"id": filter.IDs})
+	}
 	if filter.Email != nil {
 		builder = builder.Where(sq.Eq{"email": *filter.Email})
 	}
+	if filter.Emails != nil {
+		builder = builder.Where(sq.Eq{"LOWER(email)": lo.Map(filter.Emails, func(email string, _ int) string {
+			return strings.ToLower(email)
+		})})
+	}
 
 	return builder.ToSql()
 }
@@ -59,6 +73,45 @@ func (uq *UserRepository) Get(ctx context.Context, filter UserRepositoryFilter)
 	return &user, nil
 }
 
+func (uq *UserRepository) List(ctx context.Context, filter UserRepositoryFilter) ([]User, error) {
+	query, args, err := uq.buildQuery(filter, QueryTypeSelect)
+	if err != nil {
+		return nil, err
+	}
+
+	var users []User
+	if err := uq.db.SelectContext(ctx, &users, query, args...); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// ExistingEmails returns which of emails, compared without case, already
+// belong to a user, including soft-deleted users, whose addresses stay
+// reserved by the unique index on email. The addresses come back lowercased.
+func (uq *UserRepository) ExistingEmails(ctx context.Context, emails []string) ([]string, error) {
+	if len(emails) == 0 {
+		return nil, nil
+	}
+
+	query, args, err := uq.psql.Select("LOWER(email)").
+		From("users").
+		Where(sq.Eq{"LOWER(email)": lo.Map(emails, func(email string, _ int) string {
+			return strings.ToLower(email)
+		})}).
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var existing []string
+	if err := uq.db.SelectContext(ctx, &existing, query, args...); err != nil {
+		return nil, err
+	}
+
+	return existing, nil
+}
+
 func (uq *UserRepository) Exists(ctx context.Context, filter UserRepositoryFilter) (bool, error) {
 	query, args, err := uq.buildQuery(filter, QueryTypeCount)
 	if err != nil {
@@ -113,3 +166,22 @@ func (uq *UserRepository) Upsert(ctx context.Context, user *User, tx *sqlx.Tx) (
 	err = uq.db.GetContext(ctx, &upsertedUser, query, args...)
 	return &upsertedUser, err
 }
+
+func (uq *UserRepository) UpdateEmail(ctx context.Context, id uuid.UUID, email string, tx *sqlx.Tx) error {
+	query, args, err := uq.psql.Update("users").
+		Set("email", email).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return err
+	}
+
+	if tx != nil {
+		_, err = tx.ExecContext(ctx, query, args...)
+		return err
+	}
+
+	_, err = uq.db.ExecContext(ctx, query, args...)
+	return err
+}
@@ -0,0 +1,345 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	sq "github.com/Masterminds/squirrel"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+const (
+	WebhookDeliveryStatusPending   = "PENDING"
+	WebhookDeliveryStatusDelivered = "DELIVERED"
+	// WebhookDeliveryStatusDead marks deliveries that ran out of attempts.
+	// They are only sent again when an admin redelivers them.
+	WebhookDeliveryStatusDead = "DEAD"
+)
+
+type WebhookRepository struct {
+	db   *sqlx.DB
+	psql sq.StatementBuilderType
+}
+
+// NewWebhookRepository is the constructor for WebhookRepository.
+func NewWebhookRepository(db *sqlx.DB) *WebhookRepository {
+	return &WebhookRepository{
+		db:   db,
+		psql: sq.StatementBuilder.PlaceholderFormat(sq.Dollar),
+	}
+}
+
+type WebhookEndpointRepositoryFilter struct {
+	ID        *uuid.UUID
+	IsActive  *bool
+	EventType *string
+}
+
+func (wr *WebhookRepository) applyEndpointFilter(builder sq.SelectBuilder, filter WebhookEndpointRepositoryFilter) sq.SelectBuilder {
+	if filter.ID != nil {
+		builder = builder.Where(sq.Eq{"id": *filter.ID})
+	}
+	if filter.IsActive != nil {
+		builder = builder.Where(sq.Eq{"is_active": *filter.IsActive})
+	}
+	if filter.EventType != nil {
+		builder = builder.Where("? = ANY(event_types)", *filter.EventType)
+	}
+
+	return builder
+}
+
+type WebhookDeliveryRepositoryFilter struct {
+	ID         *uuid.UUID
+	EndpointID *uuid.UUID
+	Status     *string
+}
+
+func (wr *WebhookRepository) applyDeliveryFilter(builder sq.SelectBuilder, filter WebhookDeliveryRepositoryFilter) sq.SelectBuilder {
+	if filter.ID != nil {
+		builder = builder.Where(sq.Eq{"id": *filter.ID})
+	}
+	if filter.EndpointID != nil {
+		builder = builder.Where(sq.Eq{"endpoint_id": *filter.EndpointID})
+	}
+	if filter.Status != nil {
+		builder = builder.Where(sq.Eq{"status": *filter.Status})
+	}
+
+	return builder
+}
+
+func (wr *WebhookRepository) CreateEndpoint(ctx context.Context, endpoint *WebhookEndpoint) (*WebhookEndpoint, error) {
+	query, args, err := wr.psql.Insert("webhook_endpoints").
+		Columns("url", "description", "secret_encrypted", "event_types", "is_active", "created_by", "created_at").
+		Values(endpoint.Url, endpoint.Description, endpoint.SecretEncrypted, endpoint.EventTypes, endpoint.IsActive, endpoint.CreatedBy, time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created WebhookEndpoint
+	if err := wr.db.GetContext(ctx, &created, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &created, nil
+}
+
+func (wr *WebhookRepository) UpdateEndpoint(ctx context.Context, endpoint *WebhookEndpoint) (*WebhookEndpoint, error) {
+	query, args, err := wr.psql.Update("webhook_endpoints").
+		Set("url", endpoint.Url).
+		Set("description", endpoint.Description).
+		Set("secret_encrypted", endpoint.SecretEncrypted).
+		Set("event_types", endpoint.EventTypes).
+		Set("is_active", endpoint.IsActive).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": endpoint.ID}).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var updated WebhookEndpoint
+	if err := wr.db.GetContext(ctx, &updated, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// DeleteEndpoint removes the endpoint together with its delivery log.
+func (wr *WebhookRepository) DeleteEndpoint(ctx context.Context, id uuid.UUID) (bool, error) {
+	query, args, err := wr.psql.Delete("webhook_endpoints").
+		Where(sq.Eq{"id": id}).
+		ToSql()
+	if err != nil {
+		return false, err
+	}
+
+	result, err := wr.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+func (wr *WebhookRepository) GetEndpoint(ctx context.Context, filter WebhookEndpointRepositoryFilter) (*WebhookEndpoint, error) {
+	query, args, err := wr.applyEndpointFilter(wr.psql.Select("*").From("webhook_endpoints"), filter).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoint WebhookEndpoint
+	if err := wr.db.GetContext(ctx, &endpoint, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &endpoint, nil
+}
+
+// ListEndpoints returns every endpoint matching filter. There are only ever a
+// handful, so the list is not paginated.
+func (wr *WebhookRepository) ListEndpoints(ctx context.Context, filter WebhookEndpointRepositoryFilter, tx *sqlx.Tx) ([]WebhookEndpoint, error) {
+	query, args, err := wr.applyEndpointFilter(wr.psql.Select("*").From("webhook_endpoints"), filter).
+		OrderBy("created_at").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var endpoints []WebhookEndpoint
+	if tx != nil {
+		err = tx.SelectContext(ctx, &endpoints, query, args...)
+		return endpoints, err
+	}
+
+	err = wr.db.SelectContext(ctx, &endpoints, query, args...)
+	return endpoints, err
+}
+
+// CreateDelivery queues a delivery. Pass the transaction of the business
+// change so the event is only sent if that change commits.
+func (wr *WebhookRepository) CreateDelivery(ctx context.Context, delivery *WebhookDelivery, tx *sqlx.Tx) (*WebhookDelivery, error) {
+	query, args, err := wr.psql.Insert("webhook_deliveries").
+		Columns("endpoint_id", "event_id", "event_type", "payload", "status", "max_attempts", "next_attempt_at", "created_at").
+		Values(delivery.EndpointID, delivery.EventID, delivery.EventType, string(delivery.Payload), WebhookDeliveryStatusPending, delivery.MaxAttempts, time.Now(), time.Now()).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var created WebhookDelivery
+	if tx != nil {
+		err = tx.GetContext(ctx, &created, query, args...)
+		return &created, err
+	}
+
+	err = wr.db.GetContext(ctx, &created, query, args...)
+	return &created, err
+}
+
+// ClaimDueDeliveries leases up to limit due deliveries, see EmailOutboxRepository.ClaimDue.
+func (wr *WebhookRepository) ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]WebhookDelivery, error) {
+	now := time.Now()
+	query, args, err := wr.psql.Update("webhook_deliveries").
+		Set("attempts", sq.Expr("attempts + 1")).
+		Set("next_attempt_at", now.Add(lease)).
+		Set("updated_at", now).
+		Where(
+			"id IN (SELECT id FROM webhook_deliveries WHERE status = ? AND next_attempt_at <= ? ORDER BY next_attempt_at LIMIT ? FOR UPDATE SKIP LOCKED)",
+			WebhookDeliveryStatusPending, now, limit,
+		).
+		Suffix("RETURNING *").
+		ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var claimed []WebhookDelivery
+	if err := wr.db.SelectContext(ctx, &claimed, query, args...); err != nil {
+		return nil, err
+	}
+
+	return claimed, nil
+}
+
+func (wr *WebhookRepository) MarkDelivered(ctx context.Context, id uuid.UUID, responseStatus int) error {
+	return wr.exec(ctx, wr.psql.Update("webhook_deliveries").
+		Set("status", WebhookDeliveryStatusDelivered).
+		Set("response_status", responseStatus).
+		Set("delivered_at", time.Now()).
+		Set("last_error", nil).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id}))
+}
+
+// MarkDeliveryFailed records a failed attempt. responseStatus is zero when no
+// response was received. A zero nextAttemptAt dead-letters the delivery.
+func (wr *WebhookRepository) MarkDeliveryFailed(ctx context.Context, id uuid.UUID, responseStatus int, lastError string, nextAttemptAt time.Time) error {
+	builder := wr.psql.Update("webhook_deliveries").
+		Set("response_status", sq.Expr("NULLIF(?, 0)", responseStatus)).
+		Set("last_error", lastError).
+		Set("updated_at", time.Now()).
+		Where(sq.Eq{"id": id})
+
+	if nextAttemptAt.IsZero() {
+		builder = builder.Set("status", WebhookDeliveryStatusDead)
+	} else {
+		builder = builder.Set("next_attempt_at", nextAttemptAt)
+	}
+
+	return wr.exec(ctx, builder)
+}
+
+func (wr *WebhookRepository) GetDelivery(ctx context.Context, filter WebhookDeliveryRepositoryFilter) (*WebhookDelivery, error) {
+	query, args, err := wr.applyDeliveryFilter(wr.psql.Select("*").From("webhook_deliveries"), filter).ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var delivery WebhookDelivery
+	if err := wr.db.GetContext(ctx, &delivery, query, args...); err != nil {
+		return nil, err
+	}
+
+	return &delivery, nil
+}
+
+func (wr *WebhookRepository) ListDeliveries(ctx context.Context, filter WebhookDeliveryRepositoryFilter, opts QueryOptions) (*ListResult[WebhookDelivery], error) {
+	builder := wr.applyDeliveryFilter(wr.psql.Select("*").From("webhook_deliveries"), filter)
+	builder, err := ApplyPagination(builder, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return nil, err
+	}
+
+	var deliveries []WebhookDelivery
+	if err := wr.db.SelectContext(ctx, &deliveries, query, args...); err != nil {
+		return nil, err
+	}
+
+	items := lo.Map(deliveries, func(d WebhookDelivery, _ int) *WebhookDelivery { return &d })
+	listResult := ListResult[WebhookDelivery]{
+		Items: lo.Slice(items, 0, min(len(items), int(opts.Limit))),
+	}
+
+	if len(items) > int(opts.Limit) {
+		lastItem := lo.LastOr(items, nil)
+		if lastItem != nil {
+			nextCursor := EncodeCursor(lastItem.CreatedAt, lastItem.ID)
+			listResult.NextCursor = &nextCursor
+		}
+	}
+
+	return &listResult, nil
+}
+
+// MapEndpointToDTOModel leaves out the signing secret, which is only shown
+// when it is generated.
+func (wr *WebhookRepository) MapEndpointToDTOModel(endpoint *WebhookEndpoint) *dto.WebhookEndpoint {
+	out := &dto.WebhookEndpoint{
+		ID:         endpoint.ID,
+		URL:        endpoint.Url,
+		EventTypes: endpoint.EventTypes,
+		IsActive:   endpoint.IsActive,
+		CreatedBy:  endpoint.CreatedBy,
+		CreatedAt:  endpoint.CreatedAt,
+	}
+	if endpoint.Description.Valid {
+		out.Description = &endpoint.Description.String
+	}
+	if endpoint.UpdatedAt.Valid {
+		out.UpdatedAt = &endpoint.UpdatedAt.Time
+	}
+
+	return out
+}
+
+func (wr *WebhookRepository) MapDeliveryToDTOModel(delivery *WebhookDelivery) *dto.WebhookDelivery {
+	out := &dto.WebhookDelivery{
+		ID:            delivery.ID,
+		EndpointID:    delivery.EndpointID,
+		EventID:       delivery.EventID,
+		EventType:     delivery.EventType,
+		Payload:       delivery.Payload,
+		Status:        delivery.Status,
+		Attempts:      delivery.Attempts,
+		MaxAttempts:   delivery.MaxAttempts,
+		NextAttemptAt: delivery.NextAttemptAt,
+		CreatedAt:     delivery.CreatedAt,
+	}
+	if delivery.ResponseStatus.Valid {
+		out.ResponseStatus = lo.ToPtr(int(delivery.ResponseStatus.Int32))
+	}
+	if delivery.LastError.Valid {
+		out.LastError = &delivery.LastError.String
+	}
+	if delivery.DeliveredAt.Valid {
+		out.DeliveredAt = &delivery.DeliveredAt.Time
+	}
+
+	return out
+}
+
+func (wr *WebhookRepository) exec(ctx context.Context, builder sq.Sqlizer) error {
+	query, args, err := builder.ToSql()
+	if err != nil {
+		return err
+	}
+
+	_, err = wr.db.ExecContext(ctx, query, args...)
+	return err
+}
@@ -3,6 +3,7 @@ package services
 import (
 	"net/http"
 	"strings"
+	"time"
 
 	"github.com/Jidetireni/ara-cooperative/internal/constants"
 )
@@ -11,6 +12,8 @@ type APIError struct {
 	Status  int    `json:"status"`
 	Message string `json:"message"`
 	Errors  any    `json:"errors,omitempty"`
+	// RetryAfter is sent as the Retry-After header when set.
+	RetryAfter time.Duration `json:"-"`
 }
 
 func (a *APIError) Error() string {
@@ -42,3 +45,11 @@ func ErrNotFound() *APIError {
 		Message: "not found",
 	}
 }
+
+func TooManyRequestsError(message string, retryAfter time.Duration) *APIError {
+	return &APIError{
+		Status:     http.StatusTooManyRequests,
+		Message:    message,
+		RetryAfter: retryAfter,
+	}
+}
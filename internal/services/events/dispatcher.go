@@ -0,0 +1,223 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	PollInterval = time.Second * 5
+
+	batchSize = 50
+	// dispatchLease must comfortably exceed the time one batch takes to handle.
+	dispatchLease = time.Minute * 5
+
+	defaultMaxAttempts = 10
+	baseBackoff        = time.Second * 10
+	maxBackoff         = time.Hour
+)
+
+var (
+	_ DomainEventRepository = (*repository.DomainEventRepository)(nil)
+)
+
+type DomainEventRepository interface {
+	Create(ctx context.Context, event *repository.DomainEvent, tx *sqlx.Tx) (*repository.DomainEvent, error)
+	ClaimDue(ctx context.Context, limit uint64, lease time.Duration) ([]repository.DomainEvent, error)
+	MarkHandled(ctx context.Context, id uuid.UUID, handler string) error
+	MarkProcessed(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+}
+
+// HandlerFunc handles the JSON payload of an event, see On for typed handlers.
+// A handler runs at least once per event and must be idempotent: it is
+// retried when it fails and may run again if recording its success fails.
+type HandlerFunc func(ctx context.Context, payload json.RawMessage) error
+
+type handler struct {
+	name   string
+	handle HandlerFunc
+}
+
+// Dispatcher is the in-process event bus. Publish records events in the
+// domain event outbox and Run delivers them to the registered handlers.
+type Dispatcher struct {
+	EventRepo DomainEventRepository
+	Logger    *logger.Logger
+
+	handlers map[string][]handler
+	wake     chan struct{}
+}
+
+func New(eventRepo DomainEventRepository, logger *logger.Logger) *Dispatcher {
+	return &Dispatcher{
+		EventRepo: eventRepo,
+		Logger:    logger,
+		handlers:  map[string][]handler{},
+		wake:      make(chan struct{}, 1),
+	}
+}
+
+// Register adds a handler for events named eventName. name identifies the
+// handler in the outbox and must stay stable across releases. Register
+// handlers before calling Run.
+func (d *Dispatcher) Register(eventName, name string, handle HandlerFunc) {
+	if slices.ContainsFunc(d.handlers[eventName], func(h handler) bool { return h.name == name }) {
+		panic(fmt.Sprintf("events: handler %q registered twice for %q", name, eventName))
+	}
+
+	d.handlers[eventName] = append(d.handlers[eventName], handler{name: name, handle: handle})
+}
+
+// On registers a handler for events of type T.
+func On[T Event](d *Dispatcher, name string, handle func(ctx context.Context, event T) error) {
+	var zero T
+	d.Register(zero.EventName(), name, func(ctx context.Context, payload json.RawMessage) error {
+		var event T
+		if err := json.Unmarshal(payload, &event); err != nil {
+			return err
+		}
+
+		return handle(ctx, event)
+	})
+}
+
+// Publish records event as part of tx. Handlers only see it once tx commits;
+// call Wake afterwards to have it handled without waiting for the next poll.
+func (d *Dispatcher) Publish(ctx context.Context, event Event, tx *sqlx.Tx) error {
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.EventRepo.Create(ctx, &repository.DomainEvent{
+		EventType:   event.EventName(),
+		Payload:     payload,
+		MaxAttempts: defaultMaxAttempts,
+	}, tx)
+	return err
+}
+
+// Wake makes Run dispatch right away instead of at its next poll.
+func (d *Dispatcher) Wake() {
+	select {
+	case d.wake <- struct{}{}:
+	default:
+	}
+}
+
+// Run dispatches recorded events until ctx is cancelled.
+func (d *Dispatcher) Run(ctx context.Context) {
+	d.Logger.Info().Msg("domain event dispatcher started")
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := d.DispatchDue(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			d.Logger.Error().Err(err).Msg("failed to dispatch domain events")
+		}
+
+		select {
+		case <-ctx.Done():
+			d.Logger.Info().Msg("domain event dispatcher stopped")
+			return
+		case <-ticker.C:
+		case <-d.wake:
+		}
+	}
+}
+
+// DispatchDue hands every due event to its handlers.
+func (d *Dispatcher) DispatchDue(ctx context.Context) error {
+	for {
+		claimed, err := d.EventRepo.ClaimDue(ctx, batchSize, dispatchLease)
+		if err != nil {
+			return err
+		}
+
+		for i := range claimed {
+			d.dispatch(ctx, &claimed[i])
+		}
+
+		if len(claimed) < batchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (d *Dispatcher) dispatch(ctx context.Context, event *repository.DomainEvent) {
+	log := d.Logger.With().
+		Str("event_id", event.ID.String()).
+		Str("event_type", event.EventType).
+		Int32("attempt", event.Attempts).
+		Logger()
+
+	var failures []string
+	for _, h := range d.handlers[event.EventType] {
+		if slices.Contains(event.HandledBy, h.name) {
+			continue
+		}
+
+		if err := d.run(ctx, h, event.Payload); err != nil {
+			log.Warn().Err(err).Str("handler", h.name).Msg("domain event handler failed")
+			failures = append(failures, h.name+": "+err.Error())
+			continue
+		}
+
+		if err := d.EventRepo.MarkHandled(ctx, event.ID, h.name); err != nil {
+			log.Error().Err(err).Str("handler", h.name).Msg("domain event handled but not marked as handled")
+			failures = append(failures, h.name+": "+err.Error())
+		}
+	}
+
+	if len(failures) == 0 {
+		if err := d.EventRepo.MarkProcessed(ctx, event.ID); err != nil {
+			log.Error().Err(err).Msg("domain event handled but not marked as processed")
+		}
+		return
+	}
+
+	var next time.Time
+	if event.Attempts < event.MaxAttempts {
+		next = time.Now().Add(backoff(event.Attempts))
+	} else {
+		log.Error().Strs("failures", failures).Msg("domain event handlers kept failing, giving up")
+	}
+
+	if err := d.EventRepo.MarkFailed(ctx, event.ID, strings.Join(failures, "; "), next); err != nil {
+		log.Error().Err(err).Msg("failed to record domain event failure")
+	}
+}
+
+// run calls h, turning a panic into an error so one bad handler cannot stop
+// the dispatcher.
+func (d *Dispatcher) run(ctx context.Context, h handler, payload json.RawMessage) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	return h.handle(ctx, payload)
+}
+
+// backoff doubles the delay after every attempt, starting at baseBackoff.
+func backoff(attempts int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
@@ -0,0 +1,41 @@
+package events
+
+import (
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/google/uuid"
+)
+
+// Event is a fact about the domain, recorded in the same transaction as the
+// change it describes and handed to every handler registered for its name.
+// Events are stored as JSON, so only add fields: old events stay in the table
+// until they are processed.
+type Event interface {
+	EventName() string
+}
+
+// TransactionConfirmed is recorded when an admin confirms a transaction.
+// Handlers pick the ledgers they care about.
+type TransactionConfirmed struct {
+	TransactionID uuid.UUID                  `json:"transaction_id"`
+	MemberID      uuid.UUID                  `json:"member_id"`
+	Ledger        repository.LedgerType      `json:"ledger"`
+	Type          repository.TransactionType `json:"type"`
+	Amount        int64                      `json:"amount"`
+}
+
+func (TransactionConfirmed) EventName() string {
+	return "transaction.confirmed"
+}
+
+// TransactionRejected is recorded when an admin rejects a transaction.
+type TransactionRejected struct {
+	TransactionID uuid.UUID                  `json:"transaction_id"`
+	MemberID      uuid.UUID                  `json:"member_id"`
+	Ledger        repository.LedgerType      `json:"ledger"`
+	Type          repository.TransactionType `json:"type"`
+	Amount        int64                      `json:"amount"`
+}
+
+func (TransactionRejected) EventName() string {
+	return "transaction.rejected"
+}
@@ -0,0 +1,106 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/samber/lo"
+)
+
+// List returns every registered job with its next scheduled run and the
+// latest recorded run.
+func (s *Scheduler) List(ctx context.Context) ([]dto.Job, error) {
+	latest, err := s.JobRunRepo.Latest(ctx)
+	if err != nil {
+		return nil, err
+	}
+	lastRuns := lo.SliceToMap(latest, func(run repository.JobRun) (string, repository.JobRun) {
+		return run.JobName, run
+	})
+
+	now := time.Now().UTC()
+	return lo.Map(s.jobs, func(job *Job, _ int) dto.Job {
+		out := dto.Job{
+			Name:        job.Name,
+			Description: job.Description,
+			Schedule:    job.Schedule,
+		}
+		if next := job.schedule.Next(now); !next.IsZero() {
+			out.NextRunAt = &next
+		}
+		if run, ok := lastRuns[job.Name]; ok {
+			out.LastRun = s.JobRunRepo.MapRepositoryToDTOModel(&run)
+		}
+
+		return out
+	}), nil
+}
+
+// Trigger queues a run of the job outside its schedule. A worker picks it up
+// within PollInterval.
+func (s *Scheduler) Trigger(ctx context.Context, name string) (*dto.JobRun, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	if _, ok := s.job(name); !ok {
+		return nil, svc.ErrNotFound()
+	}
+
+	_, err := s.JobRunRepo.Get(ctx, repository.JobRunRepositoryFilter{
+		JobName: &name,
+		Status:  lo.ToPtr(repository.JobRunStatusQueued),
+	})
+	switch {
+	case err == nil:
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "a run of this job is already queued",
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	run, err := s.JobRunRepo.CreateQueued(ctx, name, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.JobRunRepo.MapRepositoryToDTOModel(run), nil
+}
+
+// ListRuns returns the run history, newest first by default.
+func (s *Scheduler) ListRuns(ctx context.Context, jobName *string, status *string, options *dto.QueryOptions) (*dto.ListResponse[dto.JobRun], error) {
+	if jobName != nil {
+		if _, ok := s.job(*jobName); !ok {
+			return nil, svc.ErrNotFound()
+		}
+	}
+
+	result, err := s.JobRunRepo.List(ctx, repository.JobRunRepositoryFilter{
+		JobName: jobName,
+		Status:  status,
+	}, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.JobRun]{
+		Items: lo.Map(result.Items, func(item *repository.JobRun, _ int) dto.JobRun {
+			return *s.JobRunRepo.MapRepositoryToDTOModel(item)
+		}),
+		NextCursor: result.NextCursor,
+	}, nil
+}
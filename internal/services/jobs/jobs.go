@@ -0,0 +1,103 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/pkg/cron"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+var (
+	_ JobRunRepository = (*repository.JobRunRepository)(nil)
+)
+
+type JobRunRepository interface {
+	CreateScheduled(ctx context.Context, jobName string, scheduledFor time.Time) (*repository.JobRun, error)
+	CreateQueued(ctx context.Context, jobName string, triggeredBy uuid.UUID) (*repository.JobRun, error)
+	ClaimQueued(ctx context.Context, jobNames []string, limit uint64) ([]repository.JobRun, error)
+	Finish(ctx context.Context, id uuid.UUID, status string, lastError string) error
+	Get(ctx context.Context, filter repository.JobRunRepositoryFilter) (*repository.JobRun, error)
+	Latest(ctx context.Context) ([]repository.JobRun, error)
+	List(ctx context.Context, filter repository.JobRunRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.JobRun], error)
+	MapRepositoryToDTOModel(run *repository.JobRun) *dto.JobRun
+}
+
+// Job is a piece of periodic work. Run must be safe to call again after a
+// failed or interrupted run.
+type Job struct {
+	// Name identifies the job in the run history and the admin API and must
+	// stay stable across releases.
+	Name        string
+	Description string
+	// Schedule is a cron expression evaluated in UTC, see cron.Parse.
+	Schedule string
+	// Timeout bounds a single run, defaultTimeout when zero.
+	Timeout time.Duration
+	Run     func(ctx context.Context) error
+
+	schedule *cron.Schedule
+}
+
+// Scheduler runs the registered jobs on their schedules in the worker and
+// lets admins inspect and trigger them from the API.
+type Scheduler struct {
+	DB         *sqlx.DB
+	JobRunRepo JobRunRepository
+	Logger     *logger.Logger
+
+	jobs []*Job
+}
+
+func New(db *sqlx.DB, jobRunRepo JobRunRepository, logger *logger.Logger) *Scheduler {
+	return &Scheduler{
+		DB:         db,
+		JobRunRepo: jobRunRepo,
+		Logger:     logger,
+	}
+}
+
+// Register adds job to the scheduler. Register jobs before calling Run.
+func (s *Scheduler) Register(job Job) {
+	if _, ok := s.job(job.Name); ok {
+		panic(fmt.Sprintf("jobs: job %q registered twice", job.Name))
+	}
+	if job.Run == nil {
+		panic(fmt.Sprintf("jobs: job %q has no Run func", job.Name))
+	}
+
+	schedule, err := cron.Parse(job.Schedule)
+	if err != nil {
+		panic(fmt.Sprintf("jobs: job %q: %v", job.Name, err))
+	}
+	job.schedule = schedule
+	if job.Timeout == 0 {
+		job.Timeout = defaultTimeout
+	}
+
+	s.jobs = append(s.jobs, &job)
+}
+
+func (s *Scheduler) job(name string) (*Job, bool) {
+	i := slices.IndexFunc(s.jobs, func(j *Job) bool { return j.Name == name })
+	if i < 0 {
+		return nil, false
+	}
+
+	return s.jobs[i], true
+}
+
+func (s *Scheduler) names() []string {
+	names := make([]string, len(s.jobs))
+	for i, job := range s.jobs {
+		names[i] = job.Name
+	}
+
+	return names
+}
@@ -0,0 +1,179 @@
+package jobs
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+)
+
+const (
+	PollInterval = time.Second * 5
+
+	defaultTimeout = time.Minute * 10
+	claimBatchSize = 10
+)
+
+// errAlreadyRunning is recorded on runs skipped because another run of the
+// same job held its lock.
+var errAlreadyRunning = errors.New("job is already running")
+
+// Run fires jobs on their schedules and runs manually triggered ones until
+// ctx is cancelled, then waits for running jobs to return.
+//
+// Every worker replica can call Run. Each scheduled occurrence is claimed in
+// job_runs by one replica only, and a Postgres advisory lock per job keeps
+// two runs of the same job from overlapping, whichever replica they are on.
+func (s *Scheduler) Run(ctx context.Context) {
+	s.Logger.Info().Int("jobs", len(s.jobs)).Msg("job scheduler started")
+
+	next := make(map[string]time.Time, len(s.jobs))
+	for _, job := range s.jobs {
+		next[job.Name] = job.schedule.Next(time.Now().UTC())
+	}
+
+	var wg sync.WaitGroup
+	start := func(fn func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			fn()
+		}()
+	}
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		now := time.Now().UTC()
+		for _, job := range s.jobs {
+			due := next[job.Name]
+			if due.IsZero() || now.Before(due) {
+				continue
+			}
+
+			next[job.Name] = job.schedule.Next(now)
+			start(func() { s.runScheduled(ctx, job, due) })
+		}
+
+		if len(s.jobs) > 0 {
+			runs, err := s.JobRunRepo.ClaimQueued(ctx, s.names(), claimBatchSize)
+			if err != nil && !errors.Is(err, context.Canceled) {
+				s.Logger.Error().Err(err).Msg("failed to claim queued job runs")
+			}
+			for i := range runs {
+				run := &runs[i]
+				job, _ := s.job(run.JobName)
+				start(func() { s.execute(ctx, job, run) })
+			}
+		}
+
+		select {
+		case <-ctx.Done():
+			wg.Wait()
+			s.Logger.Info().Msg("job scheduler stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Scheduler) runScheduled(ctx context.Context, job *Job, scheduledFor time.Time) {
+	run, err := s.JobRunRepo.CreateScheduled(ctx, job.Name, scheduledFor)
+	if err != nil {
+		// Another replica claimed this occurrence.
+		if errors.Is(err, sql.ErrNoRows) {
+			return
+		}
+		s.Logger.Error().Err(err).Str("job", job.Name).Msg("failed to record scheduled job run")
+		return
+	}
+
+	s.execute(ctx, job, run)
+}
+
+// execute runs job under its advisory lock and records the outcome of run.
+func (s *Scheduler) execute(ctx context.Context, job *Job, run *repository.JobRun) {
+	log := s.Logger.With().
+		Str("job", job.Name).
+		Str("run_id", run.ID.String()).
+		Str("source", run.Source).
+		Logger()
+
+	// The outcome is recorded even when the worker is shutting down.
+	finish := func(status string, runErr error) {
+		var msg string
+		if runErr != nil {
+			msg = runErr.Error()
+		}
+		if err := s.JobRunRepo.Finish(context.WithoutCancel(ctx), run.ID, status, msg); err != nil {
+			log.Error().Err(err).Str("status", status).Msg("failed to record job run outcome")
+		}
+	}
+
+	// Advisory locks belong to a session, so the lock is taken and released
+	// on a dedicated connection that stays open for the whole run.
+	conn, err := s.DB.Connx(ctx)
+	if err != nil {
+		finish(repository.JobRunStatusFailed, err)
+		return
+	}
+	defer conn.Close()
+
+	key := lockKey(job.Name)
+	var locked bool
+	if err := conn.GetContext(ctx, &locked, "SELECT pg_try_advisory_lock($1)", key); err != nil {
+		finish(repository.JobRunStatusFailed, err)
+		return
+	}
+	if !locked {
+		log.Info().Msg("job is already running, skipping")
+		finish(repository.JobRunStatusSkipped, errAlreadyRunning)
+		return
+	}
+	defer func() {
+		if _, err := conn.ExecContext(context.WithoutCancel(ctx), "SELECT pg_advisory_unlock($1)", key); err != nil {
+			log.Error().Err(err).Msg("failed to release job lock")
+		}
+	}()
+
+	log.Info().Msg("job started")
+	started := time.Now()
+
+	if err := s.call(ctx, job); err != nil {
+		log.Error().Err(err).Dur("duration", time.Since(started)).Msg("job failed")
+		finish(repository.JobRunStatusFailed, err)
+		return
+	}
+
+	log.Info().Dur("duration", time.Since(started)).Msg("job finished")
+	finish(repository.JobRunStatusSucceeded, nil)
+}
+
+// call runs job within its timeout, turning a panic into an error so one bad
+// job cannot stop the scheduler.
+func (s *Scheduler) call(ctx context.Context, job *Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(ctx, job.Timeout)
+	defer cancel()
+
+	return job.Run(ctx)
+}
+
+// lockKey maps a job name to its advisory lock key.
+func lockKey(name string) int64 {
+	h := fnv.New64a()
+	h.Write([]byte("jobs:" + name))
+
+	return int64(h.Sum64())
+}
@@ -0,0 +1,154 @@
+package jobs
+
+import (
+	"context"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	_ "github.com/lib/pq"
+	"github.com/rs/zerolog"
+)
+
+// newReplica returns a scheduler with its own connection pool to the
+// migrated database in TEST_DB_URL, like a separate worker process, and skips
+// the test when it is not set.
+func newReplica(t *testing.T, job Job) *Scheduler {
+	t.Helper()
+
+	url := os.Getenv("TEST_DB_URL")
+	if url == "" {
+		t.Skip("TEST_DB_URL is not set")
+	}
+
+	db, err := sqlx.Connect("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		db.Exec("DELETE FROM job_runs WHERE job_name = $1", job.Name)
+		db.Close()
+	})
+
+	nop := zerolog.Nop()
+	s := New(db, repository.NewJobRunRepository(db), &logger.Logger{Logger: &nop})
+	s.Register(job)
+
+	return s
+}
+
+func testJobName() string {
+	return "test-" + uuid.NewString()
+}
+
+func TestScheduledOccurrenceRunsOnce(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	job := Job{
+		Name:     testJobName(),
+		Schedule: "@hourly",
+		Run: func(context.Context) error {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			return nil
+		},
+	}
+
+	replicas := []*Scheduler{newReplica(t, job), newReplica(t, job), newReplica(t, job)}
+	due := time.Now().UTC().Truncate(time.Hour)
+
+	var wg sync.WaitGroup
+	for _, s := range replicas {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.runScheduled(context.Background(), s.jobs[0], due)
+		}()
+	}
+	wg.Wait()
+
+	if calls != 1 {
+		t.Errorf("occurrence ran %d times, want 1", calls)
+	}
+
+	runs, err := replicas[0].JobRunRepo.List(context.Background(), repository.JobRunRepositoryFilter{JobName: &job.Name}, repository.QueryOptions{Limit: 10})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(runs.Items) != 1 || runs.Items[0].Status != repository.JobRunStatusSucceeded {
+		t.Errorf("recorded %d runs, want 1 succeeded run", len(runs.Items))
+	}
+}
+
+func TestOverlappingRunIsSkipped(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	var once sync.Once
+	job := Job{
+		Name:     testJobName(),
+		Schedule: "@hourly",
+		Run: func(ctx context.Context) error {
+			once.Do(func() { close(started) })
+			<-release
+			return nil
+		},
+	}
+
+	first, second := newReplica(t, job), newReplica(t, job)
+	ctx := context.Background()
+	due := time.Now().UTC().Truncate(time.Hour)
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		first.runScheduled(ctx, first.jobs[0], due.Add(-time.Hour))
+	}()
+	<-started
+
+	// A manual run on another replica while the scheduled one holds the lock.
+	manual := runManually(t, second)
+	if manual.Status != repository.JobRunStatusSkipped || manual.Error.String != errAlreadyRunning.Error() {
+		t.Errorf("overlapping run finished %s (%q), want %s", manual.Status, manual.Error.String, repository.JobRunStatusSkipped)
+	}
+
+	close(release)
+	<-done
+
+	// The lock is released once the first run returns.
+	if manual := runManually(t, second); manual.Status != repository.JobRunStatusSucceeded {
+		t.Errorf("run after the lock was released finished %s (%q), want %s", manual.Status, manual.Error.String, repository.JobRunStatusSucceeded)
+	}
+}
+
+// runManually queues a manual run of the replica's job, executes it on the
+// replica and returns the recorded outcome.
+func runManually(t *testing.T, s *Scheduler) *repository.JobRun {
+	t.Helper()
+	ctx := context.Background()
+
+	run, err := s.JobRunRepo.CreateQueued(ctx, s.jobs[0].Name, uuid.Nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claimed, err := s.JobRunRepo.ClaimQueued(ctx, s.names(), 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claimed) != 1 || claimed[0].ID != run.ID {
+		t.Fatalf("claimed %d runs, want the manual run", len(claimed))
+	}
+	s.execute(ctx, s.jobs[0], &claimed[0])
+
+	finished, err := s.JobRunRepo.Get(ctx, repository.JobRunRepositoryFilter{ID: &run.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return finished
+}
@@ -0,0 +1,294 @@
+package mailer
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/pkg/email"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+const (
+	PollInterval = time.Second * 5
+
+	batchSize = 20
+	// sendLease must comfortably exceed the time one batch takes to send.
+	sendLease = time.Minute * 5
+
+	defaultMaxAttempts = 8
+	baseBackoff        = time.Second * 30
+	maxBackoff         = time.Hour
+)
+
+var (
+	_ OutboxRepository = (*repository.EmailOutboxRepository)(nil)
+	_ EmailPkg         = (*email.Email)(nil)
+)
+
+// sensitiveTemplates render one-time codes or password reset links. Their
+// bodies are wiped from the outbox once the message is sent or dead-lettered.
+var sensitiveTemplates = map[email.EmailTemplateType]bool{
+	email.EmailTemplateTypeWelcome:       true,
+	email.EmailTemplateTypePasswordReset: true,
+}
+
+type OutboxRepository interface {
+	Create(ctx context.Context, msg *repository.EmailOutbox, tx *sqlx.Tx) (*repository.EmailOutbox, error)
+	ClaimDue(ctx context.Context, limit uint64, lease time.Duration) ([]repository.EmailOutbox, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+	Requeue(ctx context.Context, id uuid.UUID) (*repository.EmailOutbox, error)
+	Get(ctx context.Context, filter repository.EmailOutboxRepositoryFilter) (*repository.EmailOutbox, error)
+	List(ctx context.Context, filter repository.EmailOutboxRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.EmailOutbox], error)
+	MapRepositoryToDTOModel(msg *repository.EmailOutbox) *dto.EmailOutboxMessage
+}
+
+type EmailPkg interface {
+	Send(ctx context.Context, input *email.SendEmailInput) error
+	Render(name email.EmailTemplateType, data any) (*email.Rendered, error)
+}
+
+// Message is an email to queue. It is rendered from Template when queued, so
+// a template change never alters messages already waiting in the outbox.
+// ReferenceType and ReferenceID optionally tie it to a domain object whose
+// owner wants to hear about the final outcome, see OnResult.
+type Message struct {
+	To            string
+	Template      email.EmailTemplateType
+	Data          any
+	ReferenceType string
+	ReferenceID   uuid.UUID
+}
+
+// ResultHandler is told whether a referenced message was delivered or dead-lettered.
+type ResultHandler func(ctx context.Context, referenceID uuid.UUID, delivered bool) error
+
+type Mailer struct {
+	OutboxRepo OutboxRepository
+	Email      EmailPkg
+	Logger     *logger.Logger
+
+	handlers map[string]ResultHandler
+}
+
+func New(outboxRepo OutboxRepository, emailPkg EmailPkg, logger *logger.Logger) *Mailer {
+	return &Mailer{
+		OutboxRepo: outboxRepo,
+		Email:      emailPkg,
+		Logger:     logger,
+		handlers:   map[string]ResultHandler{},
+	}
+}
+
+// OnResult registers the handler for messages queued with referenceType.
+// Register handlers before calling Run.
+func (m *Mailer) OnResult(referenceType string, handler ResultHandler) {
+	m.handlers[referenceType] = handler
+}
+
+// Enqueue stores msg in the outbox as part of tx. Nothing is sent if tx rolls back.
+func (m *Mailer) Enqueue(ctx context.Context, msg *Message, tx *sqlx.Tx) error {
+	rendered, err := m.Email.Render(msg.Template, msg.Data)
+	if err != nil {
+		return err
+	}
+
+	row := &repository.EmailOutbox{
+		Recipient:   msg.To,
+		Subject:     rendered.Subject,
+		Body:        rendered.HTML,
+		TextBody:    sql.NullString{String: rendered.Text, Valid: true},
+		Template:    sql.NullString{String: string(msg.Template), Valid: true},
+		Sensitive:   sensitiveTemplates[msg.Template],
+		MaxAttempts: defaultMaxAttempts,
+	}
+	if msg.ReferenceType != "" {
+		row.ReferenceType = sql.NullString{String: msg.ReferenceType, Valid: true}
+		row.ReferenceID = repository.ToNullUUID(msg.ReferenceID)
+	}
+
+	_, err = m.OutboxRepo.Create(ctx, row, tx)
+	return err
+}
+
+// Run delivers queued messages until ctx is cancelled.
+func (m *Mailer) Run(ctx context.Context) {
+	m.Logger.Info().Msg("email outbox worker started")
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := m.DeliverDue(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			m.Logger.Error().Err(err).Msg("failed to deliver queued emails")
+		}
+
+		select {
+		case <-ctx.Done():
+			m.Logger.Info().Msg("email outbox worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends every message that is currently due.
+func (m *Mailer) DeliverDue(ctx context.Context) error {
+	for {
+		messages, err := m.OutboxRepo.ClaimDue(ctx, batchSize, sendLease)
+		if err != nil {
+			return err
+		}
+
+		for i := range messages {
+			m.deliver(ctx, &messages[i])
+		}
+
+		if len(messages) < batchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (m *Mailer) deliver(ctx context.Context, msg *repository.EmailOutbox) {
+	log := m.Logger.With().Str("email_id", msg.ID.String()).Int32("attempt", msg.Attempts).Logger()
+
+	sendErr := m.Email.Send(ctx, &email.SendEmailInput{
+		To:       msg.Recipient,
+		Subject:  msg.Subject,
+		Body:     msg.Body,
+		TextBody: msg.TextBody.String,
+	})
+	if sendErr == nil {
+		if err := m.OutboxRepo.MarkSent(ctx, msg.ID); err != nil {
+			log.Error().Err(err).Msg("email sent but not marked as sent")
+			return
+		}
+		m.notify(ctx, msg, true)
+		return
+	}
+
+	var next time.Time
+	if msg.Attempts < msg.MaxAttempts {
+		next = time.Now().Add(backoff(msg.Attempts))
+		log.Warn().Err(sendErr).Time("next_attempt_at", next).Msg("email delivery failed, will retry")
+	} else {
+		log.Error().Err(sendErr).Msg("email delivery failed, giving up")
+	}
+
+	if err := m.OutboxRepo.MarkFailed(ctx, msg.ID, sendErr.Error(), next); err != nil {
+		log.Error().Err(err).Msg("failed to record email delivery failure")
+		return
+	}
+
+	if next.IsZero() {
+		m.notify(ctx, msg, false)
+	}
+}
+
+func (m *Mailer) notify(ctx context.Context, msg *repository.EmailOutbox, delivered bool) {
+	if !msg.ReferenceType.Valid || !msg.ReferenceID.Valid {
+		return
+	}
+
+	handler, ok := m.handlers[msg.ReferenceType.String]
+	if !ok {
+		return
+	}
+
+	if err := handler(ctx, msg.ReferenceID.UUID, delivered); err != nil {
+		m.Logger.Error().Err(err).Str("email_id", msg.ID.String()).Msg("email result handler failed")
+	}
+}
+
+// backoff doubles the delay after every attempt, starting at baseBackoff.
+func backoff(attempts int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
+
+func (m *Mailer) List(ctx context.Context, status *string, options *dto.QueryOptions) (*dto.ListResponse[dto.EmailOutboxMessage], error) {
+	result, err := m.OutboxRepo.List(ctx, repository.EmailOutboxRepositoryFilter{
+		Status: status,
+	}, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.EmailOutboxMessage]{
+		Items: lo.Map(result.Items, func(item *repository.EmailOutbox, _ int) dto.EmailOutboxMessage {
+			return *m.OutboxRepo.MapRepositoryToDTOModel(item)
+		}),
+		NextCursor: result.NextCursor,
+	}, nil
+}
+
+// Retry puts a failed or dead-lettered message back in the queue.
+func (m *Mailer) Retry(ctx context.Context, id uuid.UUID) (*dto.EmailOutboxMessage, error) {
+	msg, err := m.OutboxRepo.Get(ctx, repository.EmailOutboxRepositoryFilter{
+		ID: &id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	if msg.Status == repository.EmailOutboxStatusSent {
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "email has already been sent",
+		}
+	}
+	if msg.RedactedAt.Valid {
+		return nil, redactedEmailError()
+	}
+
+	requeued, err := m.OutboxRepo.Requeue(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &svc.APIError{
+				Status:  http.StatusConflict,
+				Message: "email has already been sent",
+			}
+		}
+		return nil, err
+	}
+
+	return m.OutboxRepo.MapRepositoryToDTOModel(requeued), nil
+}
+
+func redactedEmailError() *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusConflict,
+		Message: "email held a one-time code or link and was discarded, the user has to request a new one",
+	}
+}
+
+// Preview renders a template with its sample data.
+func (m *Mailer) Preview(name email.EmailTemplateType) (*email.Rendered, error) {
+	sample, ok := email.TemplateSamples[name]
+	if !ok {
+		return nil, svc.ErrNotFound()
+	}
+
+	return m.Email.Render(name, sample)
+}
@@ -0,0 +1,120 @@
+package mailer
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/pkg/email"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+// memoryOutbox is an OutboxRepository that keeps messages in memory. The
+// redaction of sent messages is the repository's job and is tested there.
+type memoryOutbox struct {
+	messages []*repository.EmailOutbox
+	sent     []uuid.UUID
+}
+
+func (o *memoryOutbox) Create(_ context.Context, msg *repository.EmailOutbox, _ *sqlx.Tx) (*repository.EmailOutbox, error) {
+	msg.ID = uuid.New()
+	msg.Status = repository.EmailOutboxStatusPending
+	o.messages = append(o.messages, msg)
+	return msg, nil
+}
+
+func (o *memoryOutbox) ClaimDue(_ context.Context, limit uint64, _ time.Duration) ([]repository.EmailOutbox, error) {
+	var due []repository.EmailOutbox
+	for _, msg := range o.messages {
+		if msg.Status == repository.EmailOutboxStatusPending && uint64(len(due)) < limit {
+			msg.Attempts++
+			due = append(due, *msg)
+		}
+	}
+	return due, nil
+}
+
+func (o *memoryOutbox) MarkSent(_ context.Context, id uuid.UUID) error {
+	for _, msg := range o.messages {
+		if msg.ID == id {
+			msg.Status = repository.EmailOutboxStatusSent
+		}
+	}
+	o.sent = append(o.sent, id)
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(context.Context, uuid.UUID, string, time.Time) error {
+	return nil
+}
+
+func (o *memoryOutbox) Requeue(context.Context, uuid.UUID) (*repository.EmailOutbox, error) {
+	return nil, nil
+}
+
+func (o *memoryOutbox) Get(context.Context, repository.EmailOutboxRepositoryFilter) (*repository.EmailOutbox, error) {
+	return nil, nil
+}
+
+func (o *memoryOutbox) List(context.Context, repository.EmailOutboxRepositoryFilter, repository.QueryOptions) (*repository.ListResult[repository.EmailOutbox], error) {
+	return nil, nil
+}
+
+func (o *memoryOutbox) MapRepositoryToDTOModel(*repository.EmailOutbox) *dto.EmailOutboxMessage {
+	return nil
+}
+
+func TestInvitationCodeIsCapturedAndMarkedSensitive(t *testing.T) {
+	capture := email.NewCaptureTransport(0)
+	emailPkg, err := email.NewWithTransport(&config.Config{
+		Email: config.EmailConfig{FromName: "ARA Cooperative", FromAddress: "no-reply@localhost"},
+	}, capture)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outbox := &memoryOutbox{}
+	nop := zerolog.Nop()
+	m := New(outbox, emailPkg, &logger.Logger{Logger: &nop})
+
+	ctx := context.Background()
+	err = m.Enqueue(ctx, &Message{
+		To:       "ada@example.com",
+		Template: email.EmailTemplateTypeWelcome,
+		Data:     email.WelcomeData{FirstName: "Ada", Code: "482913", ExpiresInMinutes: 30},
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(capture.Messages()) != 0 {
+		t.Fatal("email was sent before the outbox was delivered")
+	}
+
+	if err := m.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	sent := capture.MessagesTo("ada@example.com")
+	if len(sent) != 1 {
+		t.Fatalf("captured %d emails to the member, want 1", len(sent))
+	}
+	if !strings.Contains(sent[0].TextBody, "482913") || !strings.Contains(sent[0].Body, "482913") {
+		t.Error("captured invitation does not contain the code")
+	}
+
+	row := outbox.messages[0]
+	if !row.Sensitive {
+		t.Error("invitation was queued without the sensitive flag, so its code would be kept")
+	}
+	if len(outbox.sent) != 1 || outbox.sent[0] != row.ID {
+		t.Errorf("marked %v as sent, want [%s]", outbox.sent, row.ID)
+	}
+}
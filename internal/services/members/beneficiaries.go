@@ -0,0 +1,331 @@
+package members
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/pkg/sms"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// MaxBeneficiaries is how many next of kin and beneficiaries a member can
+// name.
+const MaxBeneficiaries = 10
+
+func (m *Member) ListMyBeneficiaries(ctx context.Context) (*dto.Beneficiaries, error) {
+	_, member, err := m.currentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.listBeneficiaries(ctx, member.ID)
+}
+
+func (m *Member) ListBeneficiaries(ctx context.Context, slug string) (*dto.Beneficiaries, error) {
+	member, err := m.getMemberBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.listBeneficiaries(ctx, member.ID)
+}
+
+func (m *Member) listBeneficiaries(ctx context.Context, memberID uuid.UUID) (*dto.Beneficiaries, error) {
+	beneficiaries, err := m.BeneficiaryRepo.List(ctx, memberID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.beneficiariesToDTOModel(beneficiaries), nil
+}
+
+// AddMyBeneficiary names another next of kin or beneficiary. The percentages
+// may add up to less than 100 while the member is still naming beneficiaries,
+// but never to more.
+func (m *Member) AddMyBeneficiary(ctx context.Context, input dto.BeneficiaryInput) (*dto.Beneficiary, error) {
+	_, member, err := m.currentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := beneficiariesEditable(member); err != nil {
+		return nil, err
+	}
+
+	beneficiary, err := newBeneficiary(member.ID, input)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	existing, err := m.BeneficiaryRepo.List(ctx, member.ID, tx)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= MaxBeneficiaries {
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: fmt.Sprintf("a member can name at most %d next of kin and beneficiaries", MaxBeneficiaries),
+		}
+	}
+	if err := checkAllocation(append(existing, *beneficiary), false); err != nil {
+		return nil, err
+	}
+
+	created, err := m.BeneficiaryRepo.Create(ctx, beneficiary, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return m.BeneficiaryRepo.MapRepositoryToDTOModel(created), nil
+}
+
+func (m *Member) UpdateMyBeneficiary(ctx context.Context, id uuid.UUID, input dto.UpdateBeneficiaryInput) (*dto.Beneficiary, error) {
+	_, member, err := m.currentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if err := beneficiariesEditable(member); err != nil {
+		return nil, err
+	}
+
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	existing, err := m.BeneficiaryRepo.List(ctx, member.ID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	current, index, found := lo.FindIndexOf(existing, func(b repository.MemberBeneficiary) bool {
+		return b.ID == id
+	})
+	if !found {
+		return nil, svc.ErrNotFound()
+	}
+
+	merged := dto.BeneficiaryInput{
+		Name:         lo.FromPtrOr(input.Name, current.Name),
+		Relationship: lo.FromPtrOr(input.Relationship, current.Relationship),
+		Phone:        lo.FromPtrOr(input.Phone, current.Phone.String),
+		Percentage:   lo.FromPtrOr(input.Percentage, float64(repository.PercentageHundredths(current.Percentage))/100),
+		NextOfKin:    lo.FromPtrOr(input.NextOfKin, current.NextOfKin),
+	}
+
+	beneficiary, err := newBeneficiary(member.ID, merged)
+	if err != nil {
+		return nil, err
+	}
+	beneficiary.ID = id
+
+	existing[index] = *beneficiary
+	if err := checkAllocation(existing, false); err != nil {
+		return nil, err
+	}
+
+	updated, err := m.BeneficiaryRepo.Update(ctx, beneficiary, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return m.BeneficiaryRepo.MapRepositoryToDTOModel(updated), nil
+}
+
+func (m *Member) DeleteMyBeneficiary(ctx context.Context, id uuid.UUID) error {
+	_, member, err := m.currentMember(ctx)
+	if err != nil {
+		return err
+	}
+	if err := beneficiariesEditable(member); err != nil {
+		return err
+	}
+
+	deleted, err := m.BeneficiaryRepo.Delete(ctx, repository.MemberBeneficiaryRepositoryFilter{
+		ID:       &id,
+		MemberID: &member.ID,
+	}, nil)
+	if err != nil {
+		return err
+	}
+	if deleted == 0 {
+		return svc.ErrNotFound()
+	}
+
+	return nil
+}
+
+// ReplaceMyBeneficiaries swaps all of the member's next of kin and
+// beneficiaries for the ones in input, whose percentages must add up to 100.
+func (m *Member) ReplaceMyBeneficiaries(ctx context.Context, input dto.ReplaceBeneficiariesInput) (*dto.Beneficiaries, error) {
+	_, member, err := m.currentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.replaceBeneficiaries(ctx, member, input)
+}
+
+// ReplaceBeneficiaries is ReplaceMyBeneficiaries for an admin recording a
+// member's nomination form.
+func (m *Member) ReplaceBeneficiaries(ctx context.Context, slug string, input dto.ReplaceBeneficiariesInput) (*dto.Beneficiaries, error) {
+	member, err := m.getMemberBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.replaceBeneficiaries(ctx, member, input)
+}
+
+func (m *Member) replaceBeneficiaries(ctx context.Context, member *repository.Member, input dto.ReplaceBeneficiariesInput) (*dto.Beneficiaries, error) {
+	if err := beneficiariesEditable(member); err != nil {
+		return nil, err
+	}
+	if len(input.Beneficiaries) > MaxBeneficiaries {
+		return nil, invalidFieldError("beneficiaries",
+			fmt.Sprintf("a member can name at most %d next of kin and beneficiaries", MaxBeneficiaries))
+	}
+
+	beneficiaries := make([]repository.MemberBeneficiary, 0, len(input.Beneficiaries))
+	for _, item := range input.Beneficiaries {
+		beneficiary, err := newBeneficiary(member.ID, item)
+		if err != nil {
+			return nil, err
+		}
+		beneficiaries = append(beneficiaries, *beneficiary)
+	}
+	if err := checkAllocation(beneficiaries, true); err != nil {
+		return nil, err
+	}
+
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Listing locks the member, so concurrent changes wait for this one.
+	if _, err := m.BeneficiaryRepo.List(ctx, member.ID, tx); err != nil {
+		return nil, err
+	}
+	if _, err := m.BeneficiaryRepo.Delete(ctx, repository.MemberBeneficiaryRepositoryFilter{
+		MemberID: &member.ID,
+	}, tx); err != nil {
+		return nil, err
+	}
+
+	created := make([]repository.MemberBeneficiary, 0, len(beneficiaries))
+	for i := range beneficiaries {
+		beneficiary, err := m.BeneficiaryRepo.Create(ctx, &beneficiaries[i], tx)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, *beneficiary)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return m.beneficiariesToDTOModel(created), nil
+}
+
+func (m *Member) beneficiariesToDTOModel(beneficiaries []repository.MemberBeneficiary) *dto.Beneficiaries {
+	allocated := allocatedHundredths(beneficiaries)
+
+	return &dto.Beneficiaries{
+		Items: lo.Map(beneficiaries, func(b repository.MemberBeneficiary, _ int) dto.Beneficiary {
+			return *m.BeneficiaryRepo.MapRepositoryToDTOModel(&b)
+		}),
+		AllocatedPercentage: float64(allocated) / 100,
+		Complete:            allocated == repository.FullPercentage,
+	}
+}
+
+// newBeneficiary checks input and returns the row it describes. Percentages
+// have at most two decimal places, and someone with no share must be a next
+// of kin.
+func newBeneficiary(memberID uuid.UUID, input dto.BeneficiaryInput) (*repository.MemberBeneficiary, error) {
+	hundredths := math.Round(input.Percentage * 100)
+	if math.Abs(input.Percentage*100-hundredths) > 1e-6 {
+		return nil, invalidFieldError("percentage", "percentage can have at most two decimal places")
+	}
+	if hundredths == 0 && !input.NextOfKin {
+		return nil, invalidFieldError("percentage", "a beneficiary needs a percentage unless they are a next of kin")
+	}
+
+	var phone string
+	if strings.TrimSpace(input.Phone) != "" {
+		normalized, err := sms.NormalizePhone(input.Phone)
+		if err != nil {
+			return nil, invalidPhoneError("phone")
+		}
+		phone = normalized
+	}
+
+	return &repository.MemberBeneficiary{
+		MemberID:     memberID,
+		Name:         strings.TrimSpace(input.Name),
+		Relationship: strings.ToLower(strings.TrimSpace(input.Relationship)),
+		Phone:        repository.ToNullString(lo.EmptyableToPtr(phone)),
+		Percentage:   repository.FormatPercentage(int64(hundredths)),
+		NextOfKin:    input.NextOfKin,
+	}, nil
+}
+
+// checkAllocation rejects beneficiaries whose percentages add up to more than
+// 100, or with complete set to anything but 100 when any has a share.
+func checkAllocation(beneficiaries []repository.MemberBeneficiary, complete bool) error {
+	allocated := allocatedHundredths(beneficiaries)
+
+	switch {
+	case allocated > repository.FullPercentage:
+		return invalidFieldError("percentage",
+			fmt.Sprintf("the percentages add up to %s, more than 100", repository.FormatPercentage(allocated)))
+	case complete && allocated != 0 && allocated != repository.FullPercentage:
+		return invalidFieldError("percentage",
+			fmt.Sprintf("the percentages add up to %s, not 100", repository.FormatPercentage(allocated)))
+	}
+
+	return nil
+}
+
+func allocatedHundredths(beneficiaries []repository.MemberBeneficiary) int64 {
+	return lo.SumBy(beneficiaries, func(b repository.MemberBeneficiary) int64 {
+		return repository.PercentageHundredths(b.Percentage)
+	})
+}
+
+// beneficiariesEditable returns a 409 once the member has left, since their
+// nomination is then what their settlement was made on.
+func beneficiariesEditable(member *repository.Member) error {
+	if member.Status == repository.MemberStatusExited {
+		return &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "the member has left the cooperative",
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,118 @@
+package members
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/pkg/sms"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// List is the admin member directory. The total counts every member matching
+// filter, not just the page returned.
+func (m *Member) List(ctx context.Context, filter dto.MemberFilter, options *dto.QueryOptions) (*dto.ListResponse[dto.Member], error) {
+	if err := validateMemberSort(options.Sort); err != nil {
+		return nil, err
+	}
+
+	repoFilter := repository.MemberRepositoryFilter{
+		JoinedFrom: filter.JoinedFrom,
+	}
+	if filter.JoinedTo != nil {
+		repoFilter.JoinedTo = lo.ToPtr(filter.JoinedTo.AddDate(0, 0, 1))
+	}
+	if filter.Status != nil {
+		repoFilter.Status = lo.ToPtr(strings.ToUpper(string(*filter.Status)))
+	}
+	if filter.Search != nil {
+		repoFilter.Search = lo.ToPtr(searchTerms(*filter.Search))
+	}
+
+	total, err := m.MemberRepository.Count(ctx, repoFilter)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := m.MemberRepository.List(ctx, repoFilter, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	emails, err := m.emails(ctx, result.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.Member]{
+		Items: lo.Map(result.Items, func(item *repository.Member, _ int) dto.Member {
+			member := m.MemberRepository.MapRepositoryToDTOModel(item)
+			member.Email = emails[item.UserID]
+			return *member
+		}),
+		NextCursor: result.NextCursor,
+		Total:      &total,
+	}, nil
+}
+
+// emails returns the login email of each member's user.
+func (m *Member) emails(ctx context.Context, members []*repository.Member) (map[uuid.UUID]string, error) {
+	if len(members) == 0 {
+		return nil, nil
+	}
+
+	users, err := m.UserRepository.List(ctx, repository.UserRepositoryFilter{
+		IDs: lo.Map(members, func(member *repository.Member, _ int) uuid.UUID { return member.UserID }),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.SliceToMap(users, func(user repository.User) (uuid.UUID, string) {
+		return user.ID, user.Email
+	}), nil
+}
+
+// searchTerms rewrites phone numbers the way they are stored: a full number in
+// any format becomes E.164, and the trunk zero of a partial national number is
+// dropped so "0803123" finds "+234803123...".
+func searchTerms(search string) string {
+	if phone, err := sms.NormalizePhone(search); err == nil {
+		return phone
+	}
+
+	words := strings.Fields(search)
+	for i, word := range words {
+		if len(word) > 1 && strings.HasPrefix(word, "0") && strings.Trim(word, "0123456789") == "" {
+			words[i] = word[1:]
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+func validateMemberSort(sort *string) error {
+	if sort == nil {
+		return nil
+	}
+
+	column, order, _ := strings.Cut(*sort, ":")
+	if !lo.Contains(repository.MemberSortColumns, column) || (order != "asc" && order != "desc") {
+		return &svc.APIError{
+			Status: http.StatusBadRequest,
+			Message: fmt.Sprintf("sort must be <column>:asc or <column>:desc where column is one of %s",
+				strings.Join(repository.MemberSortColumns, ", ")),
+		}
+	}
+
+	return nil
+}
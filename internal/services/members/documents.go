@@ -0,0 +1,363 @@
+package members
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const (
+	// MaxDocumentSize is the largest KYC document that can be uploaded.
+	MaxDocumentSize = 5 << 20
+
+	// DocumentURLExpiry is how long a document download link works.
+	DocumentURLExpiry = 15 * time.Minute
+)
+
+// documentExtensions are the content types documents may have, sniffed from
+// the file rather than trusted from the upload, and the extension they are
+// stored with.
+var documentExtensions = map[string]string{
+	"application/pdf": ".pdf",
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+}
+
+var documentTypes = map[dto.MemberDocumentType]string{
+	dto.MemberDocumentTypeMembershipForm: repository.MemberDocumentTypeMembershipForm,
+	dto.MemberDocumentTypeIDCard:         repository.MemberDocumentTypeIDCard,
+	dto.MemberDocumentTypePassportPhoto:  repository.MemberDocumentTypePassportPhoto,
+	dto.MemberDocumentTypeUtilityBill:    repository.MemberDocumentTypeUtilityBill,
+}
+
+// UploadMyDocument stores a KYC document the member uploads themselves.
+func (m *Member) UploadMyDocument(ctx context.Context, docType dto.MemberDocumentType, file io.Reader, filename string) (*dto.MemberDocument, error) {
+	actor, member, err := m.currentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.uploadDocument(ctx, member, actor.ID, docType, file, filename)
+}
+
+// UploadDocument stores a KYC document an admin uploads for a member, such as
+// a scan of a paper membership form.
+func (m *Member) UploadDocument(ctx context.Context, slug string, docType dto.MemberDocumentType, file io.Reader, filename string) (*dto.MemberDocument, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	member, err := m.getMemberBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.uploadDocument(ctx, member, actor.ID, docType, file, filename)
+}
+
+// uploadDocument checks the size and content of file and keeps it in storage
+// before recording it, pending review. A member may upload a document type
+// again, for instance after a rejection; the earlier uploads are kept.
+func (m *Member) uploadDocument(ctx context.Context, member *repository.Member, uploadedBy uuid.UUID, docType dto.MemberDocumentType, file io.Reader, filename string) (*dto.MemberDocument, error) {
+	storedType, ok := documentTypes[docType]
+	if !ok {
+		return nil, invalidFieldError("type", "type must be one of membership_form, id_card, passport_photo, utility_bill")
+	}
+	if member.Status == repository.MemberStatusExited {
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "the member has left the cooperative",
+		}
+	}
+
+	content, err := io.ReadAll(io.LimitReader(file, MaxDocumentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case len(content) == 0:
+		return nil, invalidFieldError("file", "the file is empty")
+	case len(content) > MaxDocumentSize:
+		return nil, &svc.APIError{
+			Status:  http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("documents can be at most %d MB", MaxDocumentSize>>20),
+		}
+	}
+
+	contentType, _, _ := strings.Cut(http.DetectContentType(content), ";")
+	ext, ok := documentExtensions[contentType]
+	if !ok {
+		return nil, invalidFieldError("file", "the file must be a PDF, JPEG or PNG")
+	}
+	if docType == dto.MemberDocumentTypePassportPhoto && !strings.HasPrefix(contentType, "image/") {
+		return nil, invalidFieldError("file", "a passport photo must be a JPEG or PNG")
+	}
+
+	key := path.Join("members", member.ID.String(), string(docType), uuid.NewString()+ext)
+	if err := m.Storage.Put(ctx, key, bytes.NewReader(content), int64(len(content)), contentType); err != nil {
+		return nil, err
+	}
+
+	document, err := m.DocumentRepo.Create(ctx, &repository.MemberDocument{
+		MemberID:     member.ID,
+		Type:         storedType,
+		StorageKey:   key,
+		ContentType:  contentType,
+		SizeBytes:    int64(len(content)),
+		OriginalName: lo.CoalesceOrEmpty(path.Base(strings.ReplaceAll(filename, "\\", "/")), string(docType)+ext),
+		UploadedBy:   uploadedBy,
+	}, nil)
+	if err != nil {
+		if deleteErr := m.Storage.Delete(ctx, key); deleteErr != nil {
+			m.Logger.Error().Err(deleteErr).Str("key", key).Msg("failed to delete unrecorded document")
+		}
+		return nil, err
+	}
+
+	return m.DocumentRepo.MapRepositoryToDTOModel(document), nil
+}
+
+func (m *Member) ListMyDocuments(ctx context.Context, options *dto.QueryOptions) (*dto.ListResponse[dto.MemberDocument], error) {
+	_, member, err := m.currentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.listDocuments(ctx, member.ID, options)
+}
+
+func (m *Member) ListDocuments(ctx context.Context, slug string, options *dto.QueryOptions) (*dto.ListResponse[dto.MemberDocument], error) {
+	member, err := m.getMemberBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.listDocuments(ctx, member.ID, options)
+}
+
+func (m *Member) listDocuments(ctx context.Context, memberID uuid.UUID, options *dto.QueryOptions) (*dto.ListResponse[dto.MemberDocument], error) {
+	result, err := m.DocumentRepo.List(ctx, repository.MemberDocumentRepositoryFilter{
+		MemberID: &memberID,
+	}, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.MemberDocument]{
+		Items: lo.Map(result.Items, func(item *repository.MemberDocument, _ int) dto.MemberDocument {
+			return *m.DocumentRepo.MapRepositoryToDTOModel(item)
+		}),
+		NextCursor: result.NextCursor,
+	}, nil
+}
+
+// MyDocumentDownload is a signed link to one of the member's own documents.
+func (m *Member) MyDocumentDownload(ctx context.Context, id uuid.UUID) (*dto.DocumentDownload, error) {
+	_, member, err := m.currentMember(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.documentDownload(ctx, member.ID, id)
+}
+
+func (m *Member) DocumentDownload(ctx context.Context, slug string, id uuid.UUID) (*dto.DocumentDownload, error) {
+	member, err := m.getMemberBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.documentDownload(ctx, member.ID, id)
+}
+
+func (m *Member) documentDownload(ctx context.Context, memberID, id uuid.UUID) (*dto.DocumentDownload, error) {
+	document, err := m.getDocument(ctx, memberID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := m.Storage.SignedURL(ctx, document.StorageKey, DocumentURLExpiry)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.DocumentDownload{
+		URL:       url,
+		ExpiresAt: time.Now().Add(DocumentURLExpiry),
+	}, nil
+}
+
+// VerifyDocument accepts a pending document. When activation requires KYC and
+// this completes the member's documents, a pending member who has paid their
+// registration fee is activated.
+func (m *Member) VerifyDocument(ctx context.Context, slug string, id uuid.UUID, input dto.VerifyMemberDocumentInput) (*dto.MemberDocument, error) {
+	actor, member, err := m.reviewableDocument(ctx, slug, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var note *string
+	if strings.TrimSpace(input.Note) != "" {
+		note = &input.Note
+	}
+
+	reviewed, err := m.DocumentRepo.Review(ctx, id, repository.MemberDocumentStatusVerified, actor.ID, note, nil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, documentAlreadyReviewedError()
+		}
+		return nil, err
+	}
+
+	// The document is verified either way; activation is retried when the
+	// registration fee is confirmed or by an admin.
+	if err := m.activateIfReady(ctx, member.ID, "KYC documents verified"); err != nil {
+		m.Logger.Error().Err(err).Str("member_id", member.ID.String()).Msg("failed to activate member after KYC verification")
+	}
+
+	return m.DocumentRepo.MapRepositoryToDTOModel(reviewed), nil
+}
+
+// RejectDocument closes a pending document; the member has to upload it again.
+func (m *Member) RejectDocument(ctx context.Context, slug string, id uuid.UUID, input dto.RejectMemberDocumentInput) (*dto.MemberDocument, error) {
+	actor, _, err := m.reviewableDocument(ctx, slug, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewed, err := m.DocumentRepo.Review(ctx, id, repository.MemberDocumentStatusRejected, actor.ID, &input.Reason, nil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, documentAlreadyReviewedError()
+		}
+		return nil, err
+	}
+
+	return m.DocumentRepo.MapRepositoryToDTOModel(reviewed), nil
+}
+
+// reviewableDocument loads a pending document of the member the current admin
+// may review. Admins cannot review documents they uploaded or their own.
+func (m *Member) reviewableDocument(ctx context.Context, slug string, id uuid.UUID) (*users.UserContextValue, *repository.Member, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, nil, svc.UnauthenticatedError()
+	}
+
+	member, err := m.getMemberBySlug(ctx, slug)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	document, err := m.getDocument(ctx, member.ID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if document.Status != repository.MemberDocumentStatusPending {
+		return nil, nil, documentAlreadyReviewedError()
+	}
+	if document.UploadedBy == actor.ID || member.UserID == actor.ID {
+		return nil, nil, &svc.APIError{
+			Status:  http.StatusForbidden,
+			Message: "you cannot review a document you uploaded or your own document",
+		}
+	}
+
+	return actor, member, nil
+}
+
+func (m *Member) getDocument(ctx context.Context, memberID, id uuid.UUID) (*repository.MemberDocument, error) {
+	document, err := m.DocumentRepo.Get(ctx, repository.MemberDocumentRepositoryFilter{
+		ID:       &id,
+		MemberID: &memberID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	return document, nil
+}
+
+// missingKYCDocuments returns the required document types the member has no
+// verified document of, in the form clients use.
+func (m *Member) missingKYCDocuments(ctx context.Context, memberID uuid.UUID) ([]string, error) {
+	verified, err := m.DocumentRepo.VerifiedTypes(ctx, memberID, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Filter(m.Config.Members.KYCRequiredDocuments, func(docType string, _ int) bool {
+		return !lo.Contains(verified, strings.ToUpper(docType))
+	}), nil
+}
+
+func (m *Member) currentMember(ctx context.Context) (*users.UserContextValue, *repository.Member, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, nil, svc.UnauthenticatedError()
+	}
+
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		UserID: &actor.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, svc.ErrNotFound()
+		}
+		return nil, nil, err
+	}
+
+	return actor, member, nil
+}
+
+func (m *Member) getMemberBySlug(ctx context.Context, slug string) (*repository.Member, error) {
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		Slug: &slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func invalidFieldError(field, message string) *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusBadRequest,
+		Message: message,
+		Errors:  map[string]string{field: message},
+	}
+}
+
+func documentAlreadyReviewedError() *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusConflict,
+		Message: "document has already been reviewed",
+	}
+}
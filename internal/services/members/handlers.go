@@ -0,0 +1,82 @@
+package members
+
+import (
+	"context"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/internal/services/events"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// ActivateOnRegistrationFee activates the member once their registration
+// fee is confirmed.
+func (m *Member) ActivateOnRegistrationFee(ctx context.Context, event events.TransactionConfirmed) error {
+	if event.Ledger != repository.LedgerTypeREGISTRATIONFEE {
+		return nil
+	}
+
+	return m.activateIfReady(ctx, event.MemberID, "registration fee paid")
+}
+
+// activateIfReady activates a pending member who has paid their registration
+// fee and, when activation requires KYC, has every required document
+// verified. Other members are left alone.
+func (m *Member) activateIfReady(ctx context.Context, memberID uuid.UUID, reason string) error {
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		ID: &memberID,
+	})
+	if err != nil {
+		return err
+	}
+	if member.Status != repository.MemberStatusPending {
+		return nil
+	}
+
+	paid, err := m.TransactionRepo.GetBalance(ctx, repository.TransactionRepositoryFilter{
+		MemberID:   &member.ID,
+		LedgerType: lo.ToPtr(repository.LedgerTypeREGISTRATIONFEE),
+		Confirmed:  lo.ToPtr(true),
+	})
+	if err != nil {
+		return err
+	}
+	if paid <= 0 {
+		return nil
+	}
+
+	if m.Config.Members.ActivationRequiresKYC {
+		missing, err := m.missingKYCDocuments(ctx, member.ID)
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			m.Logger.Info().Str("member_id", member.ID.String()).Strs("missing", missing).
+				Msg("member not activated until their KYC documents are verified")
+			return nil
+		}
+	}
+
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	activated, err := m.Transition(ctx, member, repository.MemberStatusActive, reason, uuid.Nil, tx)
+	if err != nil {
+		return err
+	}
+
+	err = m.Webhooks.Emit(ctx, webhooks.EventMemberActivated, m.MemberRepository.MapRepositoryToDTOModel(activated), tx)
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	return m.Sessions.RevokeSessions(ctx, member.UserID)
+}
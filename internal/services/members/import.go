@@ -0,0 +1,290 @@
+package members
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/pkg/sms"
+	"github.com/go-playground/validator/v10"
+	"github.com/samber/lo"
+)
+
+const (
+	// MemberImportMaxRows caps the size of one import file.
+	MemberImportMaxRows = 5000
+
+	// memberImportBatchSize is how many members are created per database
+	// transaction when an import is committed.
+	memberImportBatchSize = 50
+)
+
+// memberImportColumns are the CSV columns an import file must have, named
+// like the fields of POST /members. Other columns are ignored.
+var memberImportColumns = []string{
+	"email", "first_name", "last_name", "phone", "address", "next_of_kin_name", "next_of_kin_phone",
+}
+
+var importValidator = func() *validator.Validate {
+	v := validator.New()
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	})
+	return v
+}()
+
+type memberImportRow struct {
+	line  int
+	input dto.CreateMemberInput
+}
+
+// ImportMembers onboards the members listed in a CSV file. Every row is
+// validated first, including for emails and phone numbers already in use or
+// repeated in the file, and nothing is created unless all rows are valid. With
+// dryRun set only the validation report is returned.
+//
+// Members are created in batches, each in its own transaction, exactly as
+// POST /members would create them, invitation included. Validation checks
+// every email and phone number already taken, so a batch only fails on a
+// database error or a member added while the import runs. The import then
+// stops there and earlier batches stay created: their invitations are already
+// queued and cannot be recalled. The report lists the members created and the
+// row the import stopped at, so the rest of the file can be uploaded again.
+func (m *Member) ImportMembers(ctx context.Context, file io.Reader, dryRun bool) (*dto.MemberImportReport, error) {
+	rows, err := parseMemberImport(file)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.MemberImportReport{
+		DryRun:    dryRun,
+		TotalRows: len(rows),
+		Errors:    []dto.ImportRowError{},
+	}
+
+	valid, err := m.validateMemberImport(ctx, rows, report)
+	if err != nil {
+		return nil, err
+	}
+	report.ValidRows = len(valid)
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	for _, batch := range lo.Chunk(valid, memberImportBatchSize) {
+		created, err := m.importBatch(ctx, batch)
+		if err != nil {
+			m.Logger.Error().Err(err).Int("row", batch[0].line).Msg("failed to import members")
+			report.Errors = append(report.Errors, dto.ImportRowError{
+				Row:     batch[0].line,
+				Message: "import stopped: this row and the ones after it were not created, upload them again",
+			})
+			break
+		}
+
+		report.Created += len(created)
+		report.Members = append(report.Members, created...)
+	}
+
+	return report, nil
+}
+
+func (m *Member) importBatch(ctx context.Context, batch []memberImportRow) ([]*dto.Member, error) {
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	created := make([]*dto.Member, 0, len(batch))
+	for _, row := range batch {
+		member, err := m.createMember(ctx, row.input, tx)
+		if err != nil {
+			return nil, err
+		}
+		created = append(created, member)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func parseMemberImport(file io.Reader) ([]memberImportRow, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, invalidImportError("the file is empty")
+		}
+		return nil, invalidImportError(err.Error())
+	}
+
+	columns := map[string]int{}
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))] = i
+	}
+	missing := lo.Filter(memberImportColumns, func(name string, _ int) bool {
+		_, ok := columns[name]
+		return !ok
+	})
+	if len(missing) > 0 {
+		return nil, invalidImportError(fmt.Sprintf("missing columns: %s", strings.Join(missing, ", ")))
+	}
+
+	var rows []memberImportRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, invalidImportError(err.Error())
+		}
+		if len(rows) == MemberImportMaxRows {
+			return nil, invalidImportError(fmt.Sprintf("a file can hold at most %d members", MemberImportMaxRows))
+		}
+
+		field := func(name string) string {
+			return strings.TrimSpace(record[columns[name]])
+		}
+		rows = append(rows, memberImportRow{
+			line: line,
+			input: dto.CreateMemberInput{
+				Email:          field("email"),
+				FirstName:      field("first_name"),
+				LastName:       field("last_name"),
+				Phone:          field("phone"),
+				Address:        field("address"),
+				NextOfKinName:  field("next_of_kin_name"),
+				NextOfKinPhone: field("next_of_kin_phone"),
+			},
+		})
+	}
+
+	if len(rows) == 0 {
+		return nil, invalidImportError("the file has no members")
+	}
+
+	return rows, nil
+}
+
+// validateMemberImport records every problem with rows in report and returns
+// the rows without any, with their phone numbers normalized.
+func (m *Member) validateMemberImport(ctx context.Context, rows []memberImportRow, report *dto.MemberImportReport) ([]memberImportRow, error) {
+	rowErrors := map[int][]dto.ImportRowError{}
+	fail := func(row memberImportRow, field, message string) {
+		rowErrors[row.line] = append(rowErrors[row.line], dto.ImportRowError{
+			Row:     row.line,
+			Field:   field,
+			Message: message,
+		})
+	}
+
+	emailRows := map[string]int{}
+	phoneRows := map[string]int{}
+	for i := range rows {
+		row := &rows[i]
+
+		if err := importValidator.Struct(row.input); err != nil {
+			var ve validator.ValidationErrors
+			if !errors.As(err, &ve) {
+				return nil, err
+			}
+			for _, fe := range ve {
+				fail(*row, fe.Field(), importValidationMessage(fe))
+			}
+		}
+
+		if row.input.Phone != "" {
+			phone, err := sms.NormalizePhone(row.input.Phone)
+			if err != nil {
+				fail(*row, "phone", "invalid phone number")
+			}
+			row.input.Phone = phone
+		}
+		if row.input.NextOfKinPhone != "" {
+			phone, err := sms.NormalizePhone(row.input.NextOfKinPhone)
+			if err != nil {
+				fail(*row, "next_of_kin_phone", "invalid phone number")
+			}
+			row.input.NextOfKinPhone = phone
+		}
+
+		if email := strings.ToLower(row.input.Email); email != "" {
+			if first, ok := emailRows[email]; ok {
+				fail(*row, "email", fmt.Sprintf("email is already used on row %d", first))
+			} else {
+				emailRows[email] = row.line
+			}
+		}
+		if phone := row.input.Phone; phone != "" {
+			if first, ok := phoneRows[phone]; ok {
+				fail(*row, "phone", fmt.Sprintf("phone number is already used on row %d", first))
+			} else {
+				phoneRows[phone] = row.line
+			}
+		}
+	}
+
+	existingEmails, err := m.UserRepository.ExistingEmails(ctx, lo.Keys(emailRows))
+	if err != nil {
+		return nil, err
+	}
+	for _, email := range existingEmails {
+		if line, ok := emailRows[email]; ok {
+			fail(rows[line-2], "email", "email is already in use")
+		}
+	}
+
+	existingPhones, err := m.MemberRepository.ExistingPhones(ctx, lo.Keys(phoneRows))
+	if err != nil {
+		return nil, err
+	}
+	for _, phone := range existingPhones {
+		if line, ok := phoneRows[phone]; ok {
+			fail(rows[line-2], "phone", "phone number is already in use")
+		}
+	}
+
+	var valid []memberImportRow
+	for _, row := range rows {
+		if errs, ok := rowErrors[row.line]; ok {
+			report.Errors = append(report.Errors, errs...)
+			continue
+		}
+		valid = append(valid, row)
+	}
+
+	return valid, nil
+}
+
+func importValidationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s is required", fe.Field())
+	case "email":
+		return "invalid email address"
+	default:
+		return fmt.Sprintf("%s is invalid", fe.Field())
+	}
+}
+
+func invalidImportError(message string) *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusBadRequest,
+		Message: fmt.Sprintf("invalid import file: %s", message),
+	}
+}
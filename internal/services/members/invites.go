@@ -0,0 +1,233 @@
+package members
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/helpers"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/mailer"
+	"github.com/Jidetireni/ara-cooperative/internal/services/texter"
+	"github.com/Jidetireni/ara-cooperative/pkg/email"
+	"github.com/Jidetireni/ara-cooperative/pkg/token"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	// InviteResendCooldown is the minimum time between two invitation emails to the same member.
+	InviteResendCooldown = time.Minute * 2
+
+	// InvitationEmailReference tags invitation emails in the outbox.
+	InvitationEmailReference = "member_invitation"
+)
+
+// ResendInvite lets an admin re-issue the onboarding invitation of a member
+// who has not set a password yet. The previous code stops working.
+func (m *Member) ResendInvite(ctx context.Context, slug string) (*dto.Member, error) {
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		Slug: &slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &svc.APIError{
+				Status:  http.StatusNotFound,
+				Message: "Member not found",
+			}
+		}
+		return nil, err
+	}
+
+	user, err := m.UserRepository.Get(ctx, repository.UserRepositoryFilter{
+		ID: &member.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if user.PasswordHash.Valid || member.InvitationStatus == repository.InvitationStatusAccepted {
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "member has already accepted the invitation",
+		}
+	}
+
+	if wait := inviteCooldownRemaining(member); wait > 0 {
+		return nil, &svc.APIError{
+			Status:  http.StatusTooManyRequests,
+			Message: fmt.Sprintf("invitation was sent recently, try again in %d seconds", int(wait.Seconds())+1),
+		}
+	}
+
+	updated, err := m.reissueInvite(ctx, member, user)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.MemberRepository.MapRepositoryToDTOModel(updated), nil
+}
+
+// ResendInviteByEmail is the member-facing variant of ResendInvite. It never
+// reveals whether the email belongs to a pending member.
+func (m *Member) ResendInviteByEmail(ctx context.Context, input *dto.ResendInviteInput) error {
+	user, err := m.UserRepository.Get(ctx, repository.UserRepositoryFilter{
+		Email: &input.Email,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if user.PasswordHash.Valid {
+		return nil
+	}
+
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		UserID: &user.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	if inviteCooldownRemaining(member) > 0 {
+		return nil
+	}
+
+	_, err = m.reissueInvite(ctx, member, user)
+	return err
+}
+
+// reissueInvite replaces the set-password code and queues a new invitation.
+func (m *Member) reissueInvite(ctx context.Context, member *repository.Member, user *repository.User) (*repository.Member, error) {
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rawToken, err := m.rotateSetPasswordToken(ctx, user.ID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	member, err = m.queueInvite(ctx, member, user.Email, rawToken, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return member, nil
+}
+
+// rotateSetPasswordToken issues a fresh set-password code, replacing any
+// previous one for the user.
+func (m *Member) rotateSetPasswordToken(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) (string, error) {
+	rawToken := helpers.GenerateOTP()
+	_, err := m.TokenRepo.Create(ctx, &repository.Token{
+		UserID:    userID,
+		Token:     helpers.HashToken(rawToken),
+		TokenType: token.SetPasswordToken,
+		IsValid:   true,
+		ExpiresAt: time.Now().Add(token.SetPasswordTokenExpirationTime),
+	}, tx)
+	if err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+// queueInvite writes the invitation to the email outbox in tx and texts the
+// code to the member's phone. The status stays PENDING until the email outbox
+// reports the outcome to RecordInvitationResult.
+func (m *Member) queueInvite(ctx context.Context, member *repository.Member, to string, rawToken string, tx *sqlx.Tx) (*repository.Member, error) {
+	err := m.Mailer.Enqueue(ctx, &mailer.Message{
+		To:       to,
+		Template: email.EmailTemplateTypeWelcome,
+		Data: email.WelcomeData{
+			FirstName:        member.FirstName,
+			Code:             rawToken,
+			ExpiresInMinutes: int(token.SetPasswordTokenExpirationTime.Minutes()),
+		},
+		ReferenceType: InvitationEmailReference,
+		ReferenceID:   member.ID,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.Texter.Enqueue(ctx, &texter.Message{
+		To: member.Phone,
+		Body: fmt.Sprintf("ARA Coop: your code to set your password is %s. It expires in %d minutes. Do not share it.",
+			rawToken, int(token.SetPasswordTokenExpirationTime.Minutes())),
+		Sensitive:     true,
+		ReferenceType: InvitationEmailReference,
+		ReferenceID:   member.ID,
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	member.InvitationStatus = repository.InvitationStatusPending
+	member.InvitationSentAt = sql.NullTime{Time: time.Now(), Valid: true}
+	member.InvitationCount++
+
+	return m.MemberRepository.UpdateInvitation(ctx, member, tx)
+}
+
+// RecordInvitationResult is registered with the mailer for InvitationEmailReference messages.
+func (m *Member) RecordInvitationResult(ctx context.Context, memberID uuid.UUID, delivered bool) error {
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		ID: &memberID,
+	})
+	if err != nil {
+		return err
+	}
+
+	// A late result must not undo an accepted invitation.
+	if member.InvitationStatus == repository.InvitationStatusAccepted {
+		return nil
+	}
+
+	member.InvitationStatus = repository.InvitationStatusFailed
+	if delivered {
+		member.InvitationStatus = repository.InvitationStatusSent
+	}
+
+	_, err = m.MemberRepository.UpdateInvitation(ctx, member, nil)
+	return err
+}
+
+// ExpireSetPasswordTokens invalidates set-password codes that expired unused.
+// Expired codes are already refused; this keeps the tokens table truthful.
+func (m *Member) ExpireSetPasswordTokens(ctx context.Context) error {
+	expired, err := m.TokenRepo.ExpireUnused(ctx, token.SetPasswordToken)
+	if err != nil {
+		return err
+	}
+
+	m.Logger.Info().Int64("expired", expired).Msg("expired unused set-password tokens")
+	return nil
+}
+
+func inviteCooldownRemaining(member *repository.Member) time.Duration {
+	if !member.InvitationSentAt.Valid {
+		return 0
+	}
+
+	return time.Until(member.InvitationSentAt.Time.Add(InviteResendCooldown))
+}
@@ -0,0 +1,162 @@
+package members
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+// MemberStatusChange is the payload of the member.status_changed webhook.
+type MemberStatusChange struct {
+	Member *dto.Member             `json:"member"`
+	Change *dto.MemberStatusChange `json:"change"`
+}
+
+// ChangeStatus moves a member to another lifecycle state on an admin's
+// decision. The member's sessions are revoked so their next token carries the
+// new state.
+func (m *Member) ChangeStatus(ctx context.Context, slug string, input dto.UpdateMemberStatusInput) (*dto.Member, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		Slug: &slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	to := strings.ToUpper(string(input.Status))
+	if member.Status == repository.MemberStatusExiting && to == repository.MemberStatusExited {
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "an exiting member is exited by settling their account",
+		}
+	}
+	if !constants.CanTransitionMember(member.Status, to) {
+		return nil, &svc.APIError{
+			Status: http.StatusConflict,
+			Message: fmt.Sprintf("a %s member cannot be made %s",
+				strings.ToLower(member.Status), input.Status),
+		}
+	}
+
+	if to == repository.MemberStatusActive && member.Status == repository.MemberStatusPending && m.Config.Members.ActivationRequiresKYC {
+		missing, err := m.missingKYCDocuments(ctx, member.ID)
+		if err != nil {
+			return nil, err
+		}
+		if len(missing) > 0 {
+			return nil, &svc.APIError{
+				Status:  http.StatusConflict,
+				Message: fmt.Sprintf("the member's KYC documents are not all verified, missing: %s", strings.Join(missing, ", ")),
+			}
+		}
+	}
+
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	updated, err := m.Transition(ctx, member, to, input.Reason, actor.ID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	if err := m.Sessions.RevokeSessions(ctx, member.UserID); err != nil {
+		return nil, err
+	}
+
+	return m.MemberRepository.MapRepositoryToDTOModel(updated), nil
+}
+
+// Transition moves member to status as part of tx and records who did it and
+// why. changedBy is uuid.Nil for changes the system makes. It does not check
+// that the move is allowed, and callers revoke the member's sessions once tx
+// commits.
+func (m *Member) Transition(ctx context.Context, member *repository.Member, status, reason string, changedBy uuid.UUID, tx *sqlx.Tx) (*repository.Member, error) {
+	updated, err := m.MemberRepository.UpdateStatus(ctx, member.ID, member.Status, status, tx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, &svc.APIError{
+				Status:  http.StatusConflict,
+				Message: "member status changed during the update, try again",
+			}
+		}
+		return nil, err
+	}
+
+	change, err := m.MemberRepository.RecordStatusChange(ctx, &repository.MemberStatusChange{
+		MemberID:   member.ID,
+		FromStatus: member.Status,
+		ToStatus:   status,
+		Reason:     reason,
+		ChangedBy:  repository.ToNullUUID(changedBy),
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = m.Webhooks.Emit(ctx, webhooks.EventMemberStatusChanged, &MemberStatusChange{
+		Member: m.MemberRepository.MapRepositoryToDTOModel(updated),
+		Change: m.MemberRepository.MapStatusChangeToDTOModel(change),
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+// ListStatusChanges is the lifecycle history of a member, newest first.
+func (m *Member) ListStatusChanges(ctx context.Context, slug string, options *dto.QueryOptions) (*dto.ListResponse[dto.MemberStatusChange], error) {
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		Slug: &slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	result, err := m.MemberRepository.ListStatusChanges(ctx, member.ID, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.MemberStatusChange]{
+		Items: lo.Map(result.Items, func(item *repository.MemberStatusChange, _ int) dto.MemberStatusChange {
+			return *m.MemberRepository.MapStatusChangeToDTOModel(item)
+		}),
+		NextCursor: result.NextCursor,
+	}, nil
+}
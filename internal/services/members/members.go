@@ -4,9 +4,8 @@ import (
 	"context"
 	"database/sql"
 	"errors"
-	"fmt"
+	"io"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/Jidetireni/ara-cooperative/internal/config"
@@ -15,34 +14,62 @@ import (
 	"github.com/Jidetireni/ara-cooperative/internal/helpers"
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
 	svc "github.com/Jidetireni/ara-cooperative/internal/services"
-	"github.com/Jidetireni/ara-cooperative/pkg/email"
-	"github.com/Jidetireni/ara-cooperative/pkg/token"
+	"github.com/Jidetireni/ara-cooperative/internal/services/mailer"
+	"github.com/Jidetireni/ara-cooperative/internal/services/texter"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/Jidetireni/ara-cooperative/pkg/sms"
+	"github.com/Jidetireni/ara-cooperative/pkg/storage"
 	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/samber/lo"
 )
 
 var (
-	_ MemberRepository     = (*repository.MemberRepository)(nil)
-	_ UserRepository       = (*repository.UserRepository)(nil)
-	_ RoleRepository       = (*repository.RoleRepository)(nil)
-	_ PermissionRepository = (*repository.PermissionRepository)(nil)
-	_ TokenRepository      = (*repository.TokenRepository)(nil)
+	_ MemberRepository         = (*repository.MemberRepository)(nil)
+	_ UserRepository           = (*repository.UserRepository)(nil)
+	_ RoleRepository           = (*repository.RoleRepository)(nil)
+	_ PermissionRepository     = (*repository.PermissionRepository)(nil)
+	_ TokenRepository          = (*repository.TokenRepository)(nil)
+	_ MemberChangeRepository   = (*repository.MemberChangeRepository)(nil)
+	_ MemberDocumentRepository = (*repository.MemberDocumentRepository)(nil)
+	_ TransactionRepository    = (*repository.TransactionRepository)(nil)
+	_ BeneficiaryRepository    = (*repository.MemberBeneficiaryRepository)(nil)
 )
 
 var (
-	_ EmailPkg = (*email.Email)(nil)
+	_ Mailer   = (*mailer.Mailer)(nil)
+	_ Texter   = (*texter.Texter)(nil)
+	_ Webhooks = (*webhooks.Webhooks)(nil)
+	_ Sessions = (*users.User)(nil)
+	_ Storage  = (*storage.Local)(nil)
+	_ Storage  = (*storage.S3)(nil)
 )
 
 type MemberRepository interface {
 	Create(ctx context.Context, member *repository.Member, tx *sqlx.Tx) (*repository.Member, error)
+	AllocateSlug(ctx context.Context, format func(number int64) string, tx *sqlx.Tx) (string, error)
 	Get(ctx context.Context, filter repository.MemberRepositoryFilter) (*repository.Member, error)
+	List(ctx context.Context, filter repository.MemberRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.Member], error)
+	ExistingPhones(ctx context.Context, phones []string) ([]string, error)
+	Count(ctx context.Context, filter repository.MemberRepositoryFilter) (int64, error)
+	Update(ctx context.Context, member *repository.Member, tx *sqlx.Tx) (*repository.Member, error)
+	UpdateInvitation(ctx context.Context, member *repository.Member, tx *sqlx.Tx) (*repository.Member, error)
+	UpdateStatus(ctx context.Context, id uuid.UUID, from, to string, tx *sqlx.Tx) (*repository.Member, error)
+	RecordStatusChange(ctx context.Context, change *repository.MemberStatusChange, tx *sqlx.Tx) (*repository.MemberStatusChange, error)
+	ListStatusChanges(ctx context.Context, memberID uuid.UUID, opts repository.QueryOptions) (*repository.ListResult[repository.MemberStatusChange], error)
 	MapRepositoryToDTOModel(member *repository.Member) *dto.Member
+	MapStatusChangeToDTOModel(change *repository.MemberStatusChange) *dto.MemberStatusChange
 }
 
 type UserRepository interface {
 	Create(ctx context.Context, user *repository.User, tx *sqlx.Tx) (*repository.User, error)
+	Get(ctx context.Context, filter repository.UserRepositoryFilter) (*repository.User, error)
+	List(ctx context.Context, filter repository.UserRepositoryFilter) ([]repository.User, error)
 	Exists(ctx context.Context, filter repository.UserRepositoryFilter) (bool, error)
+	ExistingEmails(ctx context.Context, emails []string) ([]string, error)
+	UpdateEmail(ctx context.Context, id uuid.UUID, email string, tx *sqlx.Tx) error
 }
 
 type RoleRepository interface {
@@ -57,10 +84,65 @@ type PermissionRepository interface {
 
 type TokenRepository interface {
 	Create(ctx context.Context, token *repository.Token, tx *sqlx.Tx) (*repository.Token, error)
+	ExpireUnused(ctx context.Context, tokenType string) (int64, error)
 }
 
-type EmailPkg interface {
-	Send(ctx context.Context, input *email.SendEmailInput) error
+type MemberChangeRepository interface {
+	CreateRequest(ctx context.Context, request *repository.MemberChangeRequest, tx *sqlx.Tx) (*repository.MemberChangeRequest, error)
+	GetRequest(ctx context.Context, filter repository.MemberChangeRequestRepositoryFilter) (*repository.MemberChangeRequest, error)
+	Review(ctx context.Context, id uuid.UUID, status string, reviewedBy uuid.UUID, note *string, tx *sqlx.Tx) (*repository.MemberChangeRequest, error)
+	ListRequests(ctx context.Context, filter repository.MemberChangeRequestRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.MemberChangeRequest], error)
+	RecordFieldChanges(ctx context.Context, changes []repository.MemberFieldChange, tx *sqlx.Tx) error
+	ListFieldChanges(ctx context.Context, filter repository.MemberFieldChangeRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.MemberFieldChange], error)
+	MapRequestToDTOModel(request *repository.MemberChangeRequest) *dto.MemberChangeRequest
+	MapFieldChangeToDTOModel(change *repository.MemberFieldChange) *dto.MemberFieldChange
+}
+
+type MemberDocumentRepository interface {
+	Create(ctx context.Context, document *repository.MemberDocument, tx *sqlx.Tx) (*repository.MemberDocument, error)
+	Get(ctx context.Context, filter repository.MemberDocumentRepositoryFilter) (*repository.MemberDocument, error)
+	List(ctx context.Context, filter repository.MemberDocumentRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.MemberDocument], error)
+	Review(ctx context.Context, id uuid.UUID, status string, reviewedBy uuid.UUID, note *string, tx *sqlx.Tx) (*repository.MemberDocument, error)
+	VerifiedTypes(ctx context.Context, memberID uuid.UUID, tx *sqlx.Tx) ([]string, error)
+	MapRepositoryToDTOModel(document *repository.MemberDocument) *dto.MemberDocument
+}
+
+type BeneficiaryRepository interface {
+	Create(ctx context.Context, beneficiary *repository.MemberBeneficiary, tx *sqlx.Tx) (*repository.MemberBeneficiary, error)
+	Update(ctx context.Context, beneficiary *repository.MemberBeneficiary, tx *sqlx.Tx) (*repository.MemberBeneficiary, error)
+	Delete(ctx context.Context, filter repository.MemberBeneficiaryRepositoryFilter, tx *sqlx.Tx) (int64, error)
+	List(ctx context.Context, memberID uuid.UUID, tx *sqlx.Tx) ([]repository.MemberBeneficiary, error)
+	MapRepositoryToDTOModel(beneficiary *repository.MemberBeneficiary) *dto.Beneficiary
+}
+
+// TransactionRepository tells whether a member has paid their registration
+// fee.
+type TransactionRepository interface {
+	GetBalance(ctx context.Context, filter repository.TransactionRepositoryFilter) (int64, error)
+}
+
+type Mailer interface {
+	Enqueue(ctx context.Context, msg *mailer.Message, tx *sqlx.Tx) error
+}
+
+type Texter interface {
+	Enqueue(ctx context.Context, msg *texter.Message, tx *sqlx.Tx) error
+}
+
+type Webhooks interface {
+	Emit(ctx context.Context, eventType webhooks.EventType, data any, tx *sqlx.Tx) error
+}
+
+// Sessions revokes a user's tokens when their standing changes.
+type Sessions interface {
+	RevokeSessions(ctx context.Context, userID uuid.UUID) error
+}
+
+// Storage keeps the files of member documents.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
 }
 
 type Member struct {
@@ -71,10 +153,19 @@ type Member struct {
 	RoleRepository   RoleRepository
 	PermissionRepo   PermissionRepository
 	TokenRepo        TokenRepository
-	Email            EmailPkg
+	MemberChangeRepo MemberChangeRepository
+	DocumentRepo     MemberDocumentRepository
+	BeneficiaryRepo  BeneficiaryRepository
+	TransactionRepo  TransactionRepository
+	Storage          Storage
+	Mailer           Mailer
+	Texter           Texter
+	Webhooks         Webhooks
+	Sessions         Sessions
+	Logger           *logger.Logger
 }
 
-func New(db *sqlx.DB, config *config.Config, memberRepo MemberRepository, userRepo UserRepository, roleRepo RoleRepository, permissionRepo PermissionRepository, tokenRepo TokenRepository, emailPkg EmailPkg) *Member {
+func New(db *sqlx.DB, config *config.Config, memberRepo MemberRepository, userRepo UserRepository, roleRepo RoleRepository, permissionRepo PermissionRepository, tokenRepo TokenRepository, memberChangeRepo MemberChangeRepository, documentRepo MemberDocumentRepository, beneficiaryRepo BeneficiaryRepository, transactionRepo TransactionRepository, fileStorage Storage, mailerSvc Mailer, texterSvc Texter, webhooksSvc Webhooks, sessions Sessions, logger *logger.Logger) *Member {
 	return &Member{
 		DB:               db,
 		Config:           config,
@@ -83,13 +174,30 @@ func New(db *sqlx.DB, config *config.Config, memberRepo MemberRepository, userRe
 		RoleRepository:   roleRepo,
 		PermissionRepo:   permissionRepo,
 		TokenRepo:        tokenRepo,
-		Email:            emailPkg,
+		MemberChangeRepo: memberChangeRepo,
+		DocumentRepo:     documentRepo,
+		BeneficiaryRepo:  beneficiaryRepo,
+		TransactionRepo:  transactionRepo,
+		Storage:          fileStorage,
+		Mailer:           mailerSvc,
+		Texter:           texterSvc,
+		Webhooks:         webhooksSvc,
+		Sessions:         sessions,
+		Logger:           logger,
 	}
 }
 
+func (m Member) formatMemberNumber(number int64) string {
+	cfg := m.Config.Members
+	return helpers.FormatMemberNumber(cfg.NumberPrefix, cfg.NumberPadding, cfg.NumberCheckDigit, number)
+}
+
 func (m Member) Create(ctx context.Context, input dto.CreateMemberInput) (*dto.Member, error) {
-	rawToken := helpers.GenerateOTP()
-	tokenHash := helpers.HashToken(rawToken)
+	phone, nextOfKinPhone, err := normalizePhones(input.Phone, input.NextOfKinPhone)
+	if err != nil {
+		return nil, err
+	}
+	input.Phone, input.NextOfKinPhone = phone, nextOfKinPhone
 
 	tx, err := m.DB.BeginTxx(ctx, nil)
 	if err != nil {
@@ -97,14 +205,34 @@ func (m Member) Create(ctx context.Context, input dto.CreateMemberInput) (*dto.M
 	}
 	defer tx.Rollback()
 
+	memberDTO, err := m.createMember(ctx, input, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return &dto.Member{}, err
+	}
+
+	return memberDTO, nil
+}
+
+// createMember creates the user and member for input, gives them the member
+// role and queues their invitation, all as part of tx. Phone numbers in input
+// must already be normalized.
+func (m *Member) createMember(ctx context.Context, input dto.CreateMemberInput, tx *sqlx.Tx) (*dto.Member, error) {
 	user, err := m.UserRepository.Create(ctx, &repository.User{
 		Email: input.Email,
 	}, tx)
 	if err != nil {
-		return &dto.Member{}, err
+		return nil, err
+	}
+
+	memberSlug, err := m.MemberRepository.AllocateSlug(ctx, m.formatMemberNumber, tx)
+	if err != nil {
+		return nil, err
 	}
 
-	memberSlug := strings.ToLower(fmt.Sprintf("ara%06d", helpers.GetNextMemberNumber()))
 	member, err := m.MemberRepository.Create(ctx, &repository.Member{
 		UserID:    user.ID,
 		Slug:      memberSlug,
@@ -125,49 +253,30 @@ func (m Member) Create(ctx context.Context, input dto.CreateMemberInput) (*dto.M
 		},
 	}, tx)
 	if err != nil {
-		return &dto.Member{}, err
+		return nil, err
 	}
 
 	err = m.AssignDefaultRoleAndPermissions(ctx, user.ID, tx)
 	if err != nil {
-		return &dto.Member{}, err
+		return nil, err
 	}
 
-	expiresAt := time.Now().Add(30 * time.Minute)
-	_, err = m.TokenRepo.Create(ctx, &repository.Token{
-		UserID:    user.ID,
-		Token:     tokenHash,
-		TokenType: token.SetPasswordToken,
-		IsValid:   true,
-		ExpiresAt: expiresAt,
-	}, tx)
+	rawToken, err := m.rotateSetPasswordToken(ctx, user.ID, tx)
 	if err != nil {
 		return nil, err
 	}
 
-	if err := tx.Commit(); err != nil {
-		return &dto.Member{}, err
+	member, err = m.queueInvite(ctx, member, input.Email, rawToken, tx)
+	if err != nil {
+		return nil, err
 	}
 
-	go func() {
-		body := fmt.Sprintf(
-			"Hello %s,\n\nYour account has been created.\nUse the following code to set your password:\n\n%s\n\nThis code expires in 15 minutes.",
-			input.FirstName,
-			rawToken,
-		)
-
-		err := m.Email.Send(context.Background(), &email.SendEmailInput{
-			To:      input.Email,
-			Subject: "Welcome! Verify your account",
-			Body:    body,
-		})
-		if err != nil {
-			// Log this error! The user exists but didn't get the code.
-			// fmt.Printf("Failed to send email to %s: %v\n", input.Email, err)
-		}
-	}()
+	memberDTO := m.MemberRepository.MapRepositoryToDTOModel(member)
+	if err := m.Webhooks.Emit(ctx, webhooks.EventMemberCreated, memberDTO, tx); err != nil {
+		return nil, err
+	}
 
-	return m.MemberRepository.MapRepositoryToDTOModel(member), nil
+	return memberDTO, nil
 }
 
 func (m *Member) GetBySlug(ctx context.Context, slug string) (*dto.Member, error) {
@@ -201,12 +310,7 @@ func (m *Member) IsMemberActive(ctx context.Context, memberID uuid.UUID) (bool,
 		return false, err
 	}
 
-	isActive := false
-	if member.ActivatedAt.Valid {
-		isActive = true
-	}
-
-	return isActive, nil
+	return member.Status == repository.MemberStatusActive, nil
 }
 
 func (m *Member) AssignDefaultRoleAndPermissions(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) error {
@@ -252,3 +356,32 @@ func (m *Member) AssignDefaultRoleAndPermissions(ctx context.Context, userID uui
 
 	return nil
 }
+
+// normalizePhones converts the member's and next of kin's numbers to E.164 so
+// they can be texted and compared. The next of kin's number is optional.
+func normalizePhones(phone, nextOfKinPhone string) (string, string, error) {
+	invalid := map[string]string{}
+
+	normalized, err := sms.NormalizePhone(phone)
+	if err != nil {
+		invalid["phone"] = "invalid phone number"
+	}
+
+	var normalizedNextOfKin string
+	if nextOfKinPhone != "" {
+		normalizedNextOfKin, err = sms.NormalizePhone(nextOfKinPhone)
+		if err != nil {
+			invalid["next_of_kin_phone"] = "invalid phone number"
+		}
+	}
+
+	if len(invalid) > 0 {
+		return "", "", &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "invalid phone number",
+			Errors:  invalid,
+		}
+	}
+
+	return normalized, normalizedNextOfKin, nil
+}
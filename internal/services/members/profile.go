@@ -0,0 +1,488 @@
+package members
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/pkg/sms"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+// UpdateProfile applies the member's own changes to their address and next of
+// kin at once. Changes to their name, phone or email identify the member and
+// decide where payouts go, so they are held in a change request for an admin
+// to approve. A member can only have one pending request at a time.
+func (m *Member) UpdateProfile(ctx context.Context, input dto.UpdateProfileInput) (*dto.UpdateProfileResponse, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		UserID: &actor.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	if input.NextOfKinPhone != nil {
+		phone, err := sms.NormalizePhone(*input.NextOfKinPhone)
+		if err != nil {
+			return nil, invalidPhoneError("next_of_kin_phone")
+		}
+		input.NextOfKinPhone = &phone
+	}
+
+	changes, err := m.requestedChanges(ctx, member, input)
+	if err != nil {
+		return nil, err
+	}
+
+	updated := *member
+	history := applyField(&updated.Address, "address", input.Address)
+	history = append(history, applyField(&updated.NextOfKinName, "next_of_kin_name", input.NextOfKinName)...)
+	history = append(history, applyField(&updated.NextOfKinPhone, "next_of_kin_phone", input.NextOfKinPhone)...)
+
+	response := &dto.UpdateProfileResponse{}
+	if len(history) == 0 && changes == nil {
+		response.Member = m.MemberRepository.MapRepositoryToDTOModel(member)
+		return response, nil
+	}
+
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if len(history) > 0 {
+		member, err = m.MemberRepository.Update(ctx, &updated, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.recordHistory(ctx, member.ID, history, actor.ID, uuid.Nil, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if changes != nil {
+		request, err := m.createChangeRequest(ctx, member.ID, actor.ID, changes, tx)
+		if err != nil {
+			return nil, err
+		}
+		response.ChangeRequest = m.MemberChangeRepo.MapRequestToDTOModel(request)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	response.Member = m.MemberRepository.MapRepositoryToDTOModel(member)
+	return response, nil
+}
+
+// requestedChanges returns the fields in input that need approval and differ
+// from what is stored, or nil when there are none.
+func (m *Member) requestedChanges(ctx context.Context, member *repository.Member, input dto.UpdateProfileInput) (*dto.MemberChanges, error) {
+	changes := dto.MemberChanges{}
+	if input.FirstName != nil && *input.FirstName != member.FirstName {
+		changes.FirstName = input.FirstName
+	}
+	if input.LastName != nil && *input.LastName != member.LastName {
+		changes.LastName = input.LastName
+	}
+	if input.Phone != nil {
+		phone, err := sms.NormalizePhone(*input.Phone)
+		if err != nil {
+			return nil, invalidPhoneError("phone")
+		}
+		if phone != member.Phone {
+			changes.Phone = &phone
+		}
+	}
+	if input.Email != nil {
+		user, err := m.UserRepository.Get(ctx, repository.UserRepositoryFilter{
+			ID: &member.UserID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		email := strings.ToLower(strings.TrimSpace(*input.Email))
+		if !strings.EqualFold(email, user.Email) {
+			changes.Email = &email
+		}
+	}
+
+	if changes == (dto.MemberChanges{}) {
+		return nil, nil
+	}
+
+	if err := m.checkChangesAvailable(ctx, member, &changes); err != nil {
+		return nil, err
+	}
+
+	return &changes, nil
+}
+
+// checkChangesAvailable returns a 409 when someone else already uses the
+// requested phone number or email. Numbers and addresses of deleted members
+// count, as they stay reserved, and emails are compared without case.
+func (m *Member) checkChangesAvailable(ctx context.Context, member *repository.Member, changes *dto.MemberChanges) error {
+	taken := map[string]string{}
+
+	if changes.Phone != nil && *changes.Phone != member.Phone {
+		existing, err := m.MemberRepository.ExistingPhones(ctx, []string{*changes.Phone})
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			taken["phone"] = "phone number is already in use"
+		}
+	}
+
+	if changes.Email != nil {
+		existing, err := m.UserRepository.ExistingEmails(ctx, []string{*changes.Email})
+		if err != nil {
+			return err
+		}
+		if len(existing) > 0 {
+			user, err := m.UserRepository.Get(ctx, repository.UserRepositoryFilter{
+				ID: &member.UserID,
+			})
+			if err != nil {
+				return err
+			}
+			if !strings.EqualFold(user.Email, *changes.Email) {
+				taken["email"] = "email is already in use"
+			}
+		}
+	}
+
+	if len(taken) > 0 {
+		return &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "requested details belong to another member",
+			Errors:  taken,
+		}
+	}
+
+	return nil
+}
+
+func (m *Member) createChangeRequest(ctx context.Context, memberID, requestedBy uuid.UUID, changes *dto.MemberChanges, tx *sqlx.Tx) (*repository.MemberChangeRequest, error) {
+	_, err := m.MemberChangeRepo.GetRequest(ctx, repository.MemberChangeRequestRepositoryFilter{
+		MemberID: &memberID,
+		Status:   lo.ToPtr(repository.MemberChangeRequestStatusPending),
+	})
+	switch {
+	case err == nil:
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "you already have a change request awaiting approval",
+		}
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	encoded, err := json.Marshal(changes)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.MemberChangeRepo.CreateRequest(ctx, &repository.MemberChangeRequest{
+		MemberID:    memberID,
+		Changes:     encoded,
+		RequestedBy: requestedBy,
+	}, tx)
+}
+
+// ApproveChangeRequest applies a pending change request to the member. The
+// requested values are checked again because another member may have taken
+// the phone number or email since the request was made.
+func (m *Member) ApproveChangeRequest(ctx context.Context, id uuid.UUID, input dto.ApproveMemberChangeInput) (*dto.MemberChangeRequest, error) {
+	actor, request, err := m.reviewableRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var changes dto.MemberChanges
+	if err := json.Unmarshal(request.Changes, &changes); err != nil {
+		return nil, err
+	}
+
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		ID: &request.MemberID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Email != nil {
+		changes.Email = lo.ToPtr(strings.ToLower(strings.TrimSpace(*changes.Email)))
+	}
+	if err := m.checkChangesAvailable(ctx, member, &changes); err != nil {
+		return nil, err
+	}
+
+	tx, err := m.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var note *string
+	if strings.TrimSpace(input.Note) != "" {
+		note = &input.Note
+	}
+	reviewed, err := m.MemberChangeRepo.Review(ctx, id, repository.MemberChangeRequestStatusApproved, actor.ID, note, tx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, alreadyReviewedError()
+		}
+		return nil, err
+	}
+
+	updated := *member
+	var history []repository.MemberFieldChange
+	history = append(history, applyValue(&updated.FirstName, "first_name", changes.FirstName)...)
+	history = append(history, applyValue(&updated.LastName, "last_name", changes.LastName)...)
+	history = append(history, applyValue(&updated.Phone, "phone", changes.Phone)...)
+
+	if _, err := m.MemberRepository.Update(ctx, &updated, tx); err != nil {
+		return nil, err
+	}
+
+	if changes.Email != nil {
+		user, err := m.UserRepository.Get(ctx, repository.UserRepositoryFilter{
+			ID: &member.UserID,
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		if err := m.UserRepository.UpdateEmail(ctx, user.ID, *changes.Email, tx); err != nil {
+			return nil, err
+		}
+		history = append(history, applyValue(&user.Email, "email", changes.Email)...)
+	}
+
+	if err := m.recordHistory(ctx, member.ID, history, actor.ID, reviewed.ID, tx); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	// The member signs in with the new email from now on, so sessions
+	// started with the old one end.
+	if changes.Email != nil {
+		if err := m.Sessions.RevokeSessions(ctx, member.UserID); err != nil {
+			return nil, err
+		}
+	}
+
+	return m.MemberChangeRepo.MapRequestToDTOModel(reviewed), nil
+}
+
+// RejectChangeRequest closes a pending change request without applying it.
+func (m *Member) RejectChangeRequest(ctx context.Context, id uuid.UUID, input dto.RejectMemberChangeInput) (*dto.MemberChangeRequest, error) {
+	actor, _, err := m.reviewableRequest(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	reviewed, err := m.MemberChangeRepo.Review(ctx, id, repository.MemberChangeRequestStatusRejected, actor.ID, &input.Reason, nil)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, alreadyReviewedError()
+		}
+		return nil, err
+	}
+
+	return m.MemberChangeRepo.MapRequestToDTOModel(reviewed), nil
+}
+
+// reviewableRequest loads a pending request the current admin may review.
+// Admins cannot review their own requests.
+func (m *Member) reviewableRequest(ctx context.Context, id uuid.UUID) (*users.UserContextValue, *repository.MemberChangeRequest, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, nil, svc.UnauthenticatedError()
+	}
+
+	request, err := m.MemberChangeRepo.GetRequest(ctx, repository.MemberChangeRequestRepositoryFilter{
+		ID: &id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil, svc.ErrNotFound()
+		}
+		return nil, nil, err
+	}
+
+	if request.Status != repository.MemberChangeRequestStatusPending {
+		return nil, nil, alreadyReviewedError()
+	}
+	if request.RequestedBy == actor.ID {
+		return nil, nil, &svc.APIError{
+			Status:  http.StatusForbidden,
+			Message: "you cannot review your own change request",
+		}
+	}
+
+	return actor, request, nil
+}
+
+func (m *Member) ListMyChangeRequests(ctx context.Context, options *dto.QueryOptions) (*dto.ListResponse[dto.MemberChangeRequest], error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		UserID: &actor.ID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	return m.listChangeRequests(ctx, repository.MemberChangeRequestRepositoryFilter{
+		MemberID: &member.ID,
+	}, options)
+}
+
+func (m *Member) ListChangeRequests(ctx context.Context, status *string, options *dto.QueryOptions) (*dto.ListResponse[dto.MemberChangeRequest], error) {
+	return m.listChangeRequests(ctx, repository.MemberChangeRequestRepositoryFilter{
+		Status: status,
+	}, options)
+}
+
+func (m *Member) listChangeRequests(ctx context.Context, filter repository.MemberChangeRequestRepositoryFilter, options *dto.QueryOptions) (*dto.ListResponse[dto.MemberChangeRequest], error) {
+	result, err := m.MemberChangeRepo.ListRequests(ctx, filter, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.MemberChangeRequest]{
+		Items: lo.Map(result.Items, func(item *repository.MemberChangeRequest, _ int) dto.MemberChangeRequest {
+			return *m.MemberChangeRepo.MapRequestToDTOModel(item)
+		}),
+		NextCursor: result.NextCursor,
+	}, nil
+}
+
+// ListFieldChanges is the change history of a member's profile, optionally
+// for a single field.
+func (m *Member) ListFieldChanges(ctx context.Context, slug string, field *string, options *dto.QueryOptions) (*dto.ListResponse[dto.MemberFieldChange], error) {
+	member, err := m.MemberRepository.Get(ctx, repository.MemberRepositoryFilter{
+		Slug: &slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	result, err := m.MemberChangeRepo.ListFieldChanges(ctx, repository.MemberFieldChangeRepositoryFilter{
+		MemberID: &member.ID,
+		Field:    field,
+	}, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.MemberFieldChange]{
+		Items: lo.Map(result.Items, func(item *repository.MemberFieldChange, _ int) dto.MemberFieldChange {
+			return *m.MemberChangeRepo.MapFieldChangeToDTOModel(item)
+		}),
+		NextCursor: result.NextCursor,
+	}, nil
+}
+
+// recordHistory stamps history with who made the changes, and the change
+// request they came from if any, and stores it in tx.
+func (m *Member) recordHistory(ctx context.Context, memberID uuid.UUID, history []repository.MemberFieldChange, changedBy, requestID uuid.UUID, tx *sqlx.Tx) error {
+	for i := range history {
+		history[i].MemberID = memberID
+		history[i].ChangedBy = repository.ToNullUUID(changedBy)
+		history[i].ChangeRequestID = repository.ToNullUUID(requestID)
+	}
+
+	return m.MemberChangeRepo.RecordFieldChanges(ctx, history, tx)
+}
+
+// applyField sets an optional column to value and returns the history entry
+// for it, or nothing when value is nil or unchanged.
+func applyField(column *sql.NullString, field string, value *string) []repository.MemberFieldChange {
+	if value == nil || (column.Valid && column.String == *value) {
+		return nil
+	}
+
+	change := repository.MemberFieldChange{
+		Field:    field,
+		OldValue: *column,
+		NewValue: sql.NullString{String: *value, Valid: true},
+	}
+	*column = change.NewValue
+
+	return []repository.MemberFieldChange{change}
+}
+
+// applyValue is applyField for a required column.
+func applyValue(column *string, field string, value *string) []repository.MemberFieldChange {
+	if value == nil || *column == *value {
+		return nil
+	}
+
+	change := repository.MemberFieldChange{
+		Field:    field,
+		OldValue: sql.NullString{String: *column, Valid: true},
+		NewValue: sql.NullString{String: *value, Valid: true},
+	}
+	*column = *value
+
+	return []repository.MemberFieldChange{change}
+}
+
+func invalidPhoneError(field string) *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusBadRequest,
+		Message: "invalid phone number",
+		Errors:  map[string]string{field: "invalid phone number"},
+	}
+}
+
+func alreadyReviewedError() *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusConflict,
+		Message: "change request has already been reviewed",
+	}
+}
@@ -0,0 +1,209 @@
+package notifications
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+type Event string
+
+const (
+	EventFineCharged             Event = "FINE_CHARGED"
+	EventFineDeadlineApproaching Event = "FINE_DEADLINE_APPROACHING"
+	EventTransactionConfirmed    Event = "TRANSACTION_CONFIRMED"
+	EventTransactionRejected     Event = "TRANSACTION_REJECTED"
+	EventSharesAllocated         Event = "SHARES_ALLOCATED"
+)
+
+// Events lists every event members can set preferences for.
+var Events = []Event{
+	EventFineCharged,
+	EventFineDeadlineApproaching,
+	EventTransactionConfirmed,
+	EventTransactionRejected,
+	EventSharesAllocated,
+}
+
+type Channel string
+
+const (
+	ChannelEmail Channel = "EMAIL"
+	ChannelInApp Channel = "IN_APP"
+	ChannelSMS   Channel = "SMS"
+)
+
+var Channels = []Channel{
+	ChannelEmail,
+	ChannelInApp,
+	ChannelSMS,
+}
+
+// smsEvents are the only events members are texted about; texts cost money,
+// so the rest stay on email and the inbox.
+var smsEvents = []Event{
+	EventFineCharged,
+	EventTransactionConfirmed,
+}
+
+// ChannelsFor returns the channels event can be delivered on.
+func ChannelsFor(event Event) []Channel {
+	if lo.Contains(smsEvents, event) {
+		return Channels
+	}
+
+	return lo.Without(Channels, ChannelSMS)
+}
+
+const (
+	ReferenceTypeFine        = "fine"
+	ReferenceTypeTransaction = "transaction"
+)
+
+// Notification is one event for one member. Title and Body are shared by
+// email and the inbox; ActionPath is a frontend path the member can follow.
+// SMSBody is the short text version, left empty when the notification is not
+// worth a text.
+type Notification struct {
+	Event         Event
+	Member        *repository.Member
+	Title         string
+	Body          string
+	SMSBody       string
+	ActionPath    string
+	ActionLabel   string
+	ReferenceType string
+	ReferenceID   uuid.UUID
+
+	// created is the inbox entry, set by Notify.
+	created *repository.Notification
+}
+
+func FineCharged(member *repository.Member, fine *repository.Fine) *Notification {
+	return &Notification{
+		Event:  EventFineCharged,
+		Member: member,
+		Title:  "You have been charged a fine",
+		Body: fmt.Sprintf("You have been charged a fine of %s for %s. Please pay it before %s.",
+			formatAmount(fine.Amount), fine.Reason, formatDate(fine.Deadline)),
+		SMSBody: fmt.Sprintf("ARA Coop: you have been fined %s for %s. Pay by %s.",
+			formatAmount(fine.Amount), fine.Reason, formatDate(fine.Deadline)),
+		ActionPath:    "/fines",
+		ActionLabel:   "View your fines",
+		ReferenceType: ReferenceTypeFine,
+		ReferenceID:   fine.ID,
+	}
+}
+
+func FineDeadlineApproaching(member *repository.Member, fine *repository.Fine) *Notification {
+	return &Notification{
+		Event:  EventFineDeadlineApproaching,
+		Member: member,
+		Title:  "Your fine is due soon",
+		Body: fmt.Sprintf("Your fine of %s for %s is due on %s and has not been paid yet.",
+			formatAmount(fine.Amount), fine.Reason, formatDate(fine.Deadline)),
+		ActionPath:    "/fines",
+		ActionLabel:   "Pay your fine",
+		ReferenceType: ReferenceTypeFine,
+		ReferenceID:   fine.ID,
+	}
+}
+
+func TransactionConfirmed(txn *repository.PopulatedTransaction) *Notification {
+	notification := &Notification{
+		Event:  EventTransactionConfirmed,
+		Member: &txn.Member,
+		Title:  fmt.Sprintf("Your %s has been confirmed", describe(txn)),
+		Body: fmt.Sprintf("Your %s of %s (reference %s) has been confirmed.",
+			describe(txn), formatAmount(txn.Amount), txn.Reference),
+		ReferenceType: ReferenceTypeTransaction,
+		ReferenceID:   txn.ID,
+	}
+
+	// Only money coming in is texted; members already know about the rest.
+	if txn.Type == repository.TransactionTypeDEPOSIT {
+		notification.SMSBody = fmt.Sprintf("ARA Coop: your %s of %s has been confirmed. Ref: %s",
+			describe(txn), formatAmount(txn.Amount), txn.Reference)
+	}
+
+	return notification
+}
+
+func TransactionRejected(txn *repository.PopulatedTransaction) *Notification {
+	return &Notification{
+		Event:  EventTransactionRejected,
+		Member: &txn.Member,
+		Title:  fmt.Sprintf("Your %s was not confirmed", describe(txn)),
+		Body: fmt.Sprintf("Your %s of %s (reference %s) was rejected. Please contact an administrator if you believe this is a mistake.",
+			describe(txn), formatAmount(txn.Amount), txn.Reference),
+		ReferenceType: ReferenceTypeTransaction,
+		ReferenceID:   txn.ID,
+	}
+}
+
+func SharesAllocated(txn *repository.PopulatedTransaction, share *repository.Share) *Notification {
+	return &Notification{
+		Event:  EventSharesAllocated,
+		Member: &txn.Member,
+		Title:  "Your shares have been allocated",
+		Body: fmt.Sprintf("Your payment of %s (reference %s) has been confirmed and %s shares at %s per unit have been allocated to you.",
+			formatAmount(txn.Amount), txn.Reference, share.Units, formatAmount(share.UnitPrice)),
+		ActionPath:    "/shares",
+		ActionLabel:   "View your shares",
+		ReferenceType: ReferenceTypeTransaction,
+		ReferenceID:   txn.ID,
+	}
+}
+
+// describe names a transaction the way members know it, e.g. "savings deposit".
+func describe(txn *repository.PopulatedTransaction) string {
+	ledger := map[repository.LedgerType]string{
+		repository.LedgerTypeSAVINGS:         "savings",
+		repository.LedgerTypeSHARES:          "shares",
+		repository.LedgerTypeLOAN:            "loan",
+		repository.LedgerTypeFINES:           "fine",
+		repository.LedgerTypeREGISTRATIONFEE: "registration fee",
+		repository.LedgerTypeSPECIALDEPOSIT:  "special deposit",
+	}[txn.Ledger]
+
+	kind := map[repository.TransactionType]string{
+		repository.TransactionTypeDEPOSIT:          "payment",
+		repository.TransactionTypeWITHDRAWAL:       "withdrawal",
+		repository.TransactionTypeLOANDISBURSEMENT: "disbursement",
+		repository.TransactionTypeLOANREPAYMENT:    "repayment",
+	}[txn.Type]
+
+	if txn.Ledger == repository.LedgerTypeSAVINGS && txn.Type == repository.TransactionTypeDEPOSIT {
+		kind = "deposit"
+	}
+
+	return strings.TrimSpace(ledger + " " + kind)
+}
+
+// formatAmount renders an amount in kobo as naira, e.g. NGN 1,000.00.
+func formatAmount(kobo int64) string {
+	sign := ""
+	if kobo < 0 {
+		sign = "-"
+		kobo = -kobo
+	}
+
+	naira := fmt.Sprintf("%d", kobo/100)
+	var grouped strings.Builder
+	for i, digit := range naira {
+		if i > 0 && (len(naira)-i)%3 == 0 {
+			grouped.WriteByte(',')
+		}
+		grouped.WriteRune(digit)
+	}
+
+	return fmt.Sprintf("%sNGN %s.%02d", sign, grouped.String(), kobo%100)
+}
+
+func formatDate(t time.Time) string {
+	return t.Format("2 Jan 2006")
+}
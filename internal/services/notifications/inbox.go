@@ -0,0 +1,102 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// List returns the current user's inbox, newest first.
+func (n *Notifications) List(ctx context.Context, filters *dto.NotificationFilter, options *dto.QueryOptions) (*dto.ListResponse[dto.Notification], error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	result, err := n.NotificationRepo.List(ctx, repository.NotificationRepositoryFilter{
+		UserID: &actor.ID,
+		Unread: filters.Unread,
+	}, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.Notification]{
+		Items: lo.Map(result.Items, func(item *repository.Notification, _ int) dto.Notification {
+			return *n.NotificationRepo.MapRepositoryToDTOModel(item)
+		}),
+		NextCursor: result.NextCursor,
+	}, nil
+}
+
+func (n *Notifications) UnreadCount(ctx context.Context) (*dto.NotificationUnreadCount, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	count, err := n.NotificationRepo.Count(ctx, repository.NotificationRepositoryFilter{
+		UserID: &actor.ID,
+		Unread: lo.ToPtr(true),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.NotificationUnreadCount{Unread: count}, nil
+}
+
+func (n *Notifications) MarkRead(ctx context.Context, id uuid.UUID) (*dto.Notification, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	notification, err := n.NotificationRepo.MarkRead(ctx, id, actor.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	// Keep the user's other open sessions in sync.
+	n.publish(ctx, streamUserChannelPrefix+actor.ID.String(), StreamEventNotificationRead, dto.NotificationsRead{
+		IDs: []uuid.UUID{id},
+	})
+
+	return n.NotificationRepo.MapRepositoryToDTOModel(notification), nil
+}
+
+func (n *Notifications) MarkAllRead(ctx context.Context) (*dto.NotificationsRead, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	count, err := n.NotificationRepo.MarkAllRead(ctx, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &dto.NotificationsRead{
+		All:   true,
+		Count: count,
+	}
+	if count > 0 {
+		n.publish(ctx, streamUserChannelPrefix+actor.ID.String(), StreamEventNotificationRead, result)
+	}
+
+	return result, nil
+}
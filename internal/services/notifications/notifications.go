@@ -0,0 +1,300 @@
+package notifications
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/mailer"
+	"github.com/Jidetireni/ara-cooperative/internal/services/texter"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/pkg/cache"
+	"github.com/Jidetireni/ara-cooperative/pkg/email"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+var (
+	_ NotificationRepository = (*repository.NotificationRepository)(nil)
+	_ UserRepository         = (*repository.UserRepository)(nil)
+	_ MemberRepository       = (*repository.MemberRepository)(nil)
+	_ FineRepository         = (*repository.FineRepository)(nil)
+)
+
+var (
+	_ Mailer = (*mailer.Mailer)(nil)
+	_ Texter = (*texter.Texter)(nil)
+	_ PubSub = (*cache.Redis)(nil)
+)
+
+type NotificationRepository interface {
+	Create(ctx context.Context, notification *repository.Notification, tx *sqlx.Tx) (*repository.Notification, error)
+	ListPreferences(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) ([]repository.NotificationPreference, error)
+	UpsertPreference(ctx context.Context, preference *repository.NotificationPreference, tx *sqlx.Tx) error
+	List(ctx context.Context, filter repository.NotificationRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.Notification], error)
+	Count(ctx context.Context, filter repository.NotificationRepositoryFilter) (int64, error)
+	MarkRead(ctx context.Context, id, userID uuid.UUID) (*repository.Notification, error)
+	MarkAllRead(ctx context.Context, userID uuid.UUID) (int64, error)
+	MapRepositoryToDTOModel(notification *repository.Notification) *dto.Notification
+}
+
+type UserRepository interface {
+	Get(ctx context.Context, filter repository.UserRepositoryFilter) (*repository.User, error)
+}
+
+type MemberRepository interface {
+	Get(ctx context.Context, filter repository.MemberRepositoryFilter) (*repository.Member, error)
+}
+
+type FineRepository interface {
+	ClaimDeadlineReminders(ctx context.Context, dueBefore time.Time, limit uint64, tx *sqlx.Tx) ([]repository.Fine, error)
+}
+
+type Mailer interface {
+	Enqueue(ctx context.Context, msg *mailer.Message, tx *sqlx.Tx) error
+}
+
+type Texter interface {
+	Enqueue(ctx context.Context, msg *texter.Message, tx *sqlx.Tx) error
+}
+
+type PubSub interface {
+	Publish(ctx context.Context, channel string, value any) error
+	Subscribe(ctx context.Context, handle func(channel string, payload []byte), patterns ...string) error
+}
+
+type Notifications struct {
+	DB               *sqlx.DB
+	Config           *config.Config
+	NotificationRepo NotificationRepository
+	UserRepo         UserRepository
+	MemberRepo       MemberRepository
+	FineRepo         FineRepository
+	Mailer           Mailer
+	Texter           Texter
+	PubSub           PubSub
+	Hub              *Hub
+	Logger           *logger.Logger
+}
+
+func New(db *sqlx.DB, cfg *config.Config, notificationRepo NotificationRepository, userRepo UserRepository, memberRepo MemberRepository, fineRepo FineRepository, mailerSvc Mailer, texterSvc Texter, pubSub PubSub, logger *logger.Logger) *Notifications {
+	return &Notifications{
+		DB:               db,
+		Config:           cfg,
+		NotificationRepo: notificationRepo,
+		UserRepo:         userRepo,
+		MemberRepo:       memberRepo,
+		FineRepo:         fineRepo,
+		Mailer:           mailerSvc,
+		Texter:           texterSvc,
+		PubSub:           pubSub,
+		Hub:              NewHub(pubSub, logger),
+		Logger:           logger,
+	}
+}
+
+// Notify delivers notification to every channel the member has enabled for its event.
+// Pass the transaction of the change being announced: the inbox entry and
+// the queued email and text are only kept if it commits. Once it has, pass
+// notification to Publish to stream it to the member's open sessions.
+func (n *Notifications) Notify(ctx context.Context, notification *Notification, tx *sqlx.Tx) error {
+	member := notification.Member
+
+	enabled, err := n.enabledChannels(ctx, member.UserID, notification.Event, tx)
+	if err != nil {
+		return err
+	}
+
+	if enabled[ChannelInApp] {
+		created, err := n.NotificationRepo.Create(ctx, &repository.Notification{
+			UserID:        member.UserID,
+			Event:         string(notification.Event),
+			Title:         notification.Title,
+			Body:          notification.Body,
+			ReferenceType: repository.ToNullString(lo.EmptyableToPtr(notification.ReferenceType)),
+			ReferenceID:   repository.ToNullUUID(notification.ReferenceID),
+		}, tx)
+		if err != nil {
+			return err
+		}
+		notification.created = created
+	}
+
+	if enabled[ChannelEmail] {
+		user, err := n.UserRepo.Get(ctx, repository.UserRepositoryFilter{
+			ID: &member.UserID,
+		})
+		if err != nil {
+			return err
+		}
+
+		data := email.NotificationData{
+			FirstName: member.FirstName,
+			Title:     notification.Title,
+			Body:      notification.Body,
+		}
+		if notification.ActionPath != "" {
+			data.ActionURL = n.Config.Server.FEURL + notification.ActionPath
+			data.ActionLabel = notification.ActionLabel
+		}
+
+		err = n.Mailer.Enqueue(ctx, &mailer.Message{
+			To:       user.Email,
+			Template: email.EmailTemplateTypeNotification,
+			Data:     data,
+		}, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	if enabled[ChannelSMS] && notification.SMSBody != "" && member.Phone != "" {
+		err := n.Texter.Enqueue(ctx, &texter.Message{
+			To:            member.Phone,
+			Body:          notification.SMSBody,
+			ReferenceType: notification.ReferenceType,
+			ReferenceID:   notification.ReferenceID,
+		}, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (n *Notifications) enabledChannels(ctx context.Context, userID uuid.UUID, event Event, tx *sqlx.Tx) (map[Channel]bool, error) {
+	preferences, err := n.NotificationRepo.ListPreferences(ctx, userID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	channels := ChannelsFor(event)
+	enabled := make(map[Channel]bool, len(channels))
+	for _, channel := range channels {
+		enabled[channel] = true
+	}
+	for _, preference := range preferences {
+		if Event(preference.Event) == event && lo.Contains(channels, Channel(preference.Channel)) {
+			enabled[Channel(preference.Channel)] = preference.Enabled
+		}
+	}
+
+	return enabled, nil
+}
+
+// GetPreferences returns every event and channel pair for the current user.
+func (n *Notifications) GetPreferences(ctx context.Context) ([]dto.NotificationPreference, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	return n.preferences(ctx, actor.ID, nil)
+}
+
+func (n *Notifications) UpdatePreferences(ctx context.Context, input *dto.UpdateNotificationPreferencesInput) ([]dto.NotificationPreference, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	invalid := map[string]string{}
+	for i, preference := range input.Preferences {
+		event, channel := Event(preference.Event), Channel(preference.Channel)
+		switch {
+		case !lo.Contains(Events, event):
+			invalid[fmt.Sprintf("preferences[%d].event", i)] = "unknown event"
+		case !lo.Contains(Channels, channel):
+			invalid[fmt.Sprintf("preferences[%d].channel", i)] = "unknown channel"
+		case !lo.Contains(ChannelsFor(event), channel):
+			invalid[fmt.Sprintf("preferences[%d].channel", i)] = "channel is not available for this event"
+		}
+	}
+	if len(invalid) > 0 {
+		return nil, &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "invalid notification preferences",
+			Errors:  invalid,
+		}
+	}
+
+	tx, err := n.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, preference := range input.Preferences {
+		err := n.NotificationRepo.UpsertPreference(ctx, &repository.NotificationPreference{
+			UserID:  actor.ID,
+			Event:   preference.Event,
+			Channel: preference.Channel,
+			Enabled: *preference.Enabled,
+		}, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	preferences, err := n.preferences(ctx, actor.ID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return preferences, nil
+}
+
+func (n *Notifications) preferences(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) ([]dto.NotificationPreference, error) {
+	stored, err := n.NotificationRepo.ListPreferences(ctx, userID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	set := lo.SliceToMap(stored, func(p repository.NotificationPreference) (string, bool) {
+		return p.Event + ":" + p.Channel, p.Enabled
+	})
+
+	preferences := make([]dto.NotificationPreference, 0, len(Events)*len(Channels))
+	for _, event := range Events {
+		for _, channel := range ChannelsFor(event) {
+			enabled, ok := set[string(event)+":"+string(channel)]
+			preferences = append(preferences, dto.NotificationPreference{
+				Event:   string(event),
+				Channel: string(channel),
+				Enabled: !ok || enabled,
+			})
+		}
+	}
+
+	return preferences, nil
+}
+
+// memberByID loads the member a notification is about, returning nil when
+// the member no longer exists.
+func (n *Notifications) memberByID(ctx context.Context, id uuid.UUID) (*repository.Member, error) {
+	member, err := n.MemberRepo.Get(ctx, repository.MemberRepositoryFilter{
+		ID: &id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
@@ -0,0 +1,67 @@
+package notifications
+
+import (
+	"context"
+	"time"
+)
+
+const (
+	// FineReminderLeadTime is how long before its deadline an unpaid fine is
+	// brought up again.
+	FineReminderLeadTime = time.Hour * 48
+
+	reminderBatchSize = 50
+)
+
+// RemindFineDeadlines notifies members once about each unpaid fine that is
+// due within FineReminderLeadTime. The worker runs it as a scheduled job.
+func (n *Notifications) RemindFineDeadlines(ctx context.Context) error {
+	for {
+		claimed, err := n.remindFineDeadlinesBatch(ctx)
+		if err != nil {
+			return err
+		}
+
+		if claimed < reminderBatchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (n *Notifications) remindFineDeadlinesBatch(ctx context.Context) (int, error) {
+	tx, err := n.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	fines, err := n.FineRepo.ClaimDeadlineReminders(ctx, time.Now().Add(FineReminderLeadTime), reminderBatchSize, tx)
+	if err != nil {
+		return 0, err
+	}
+
+	sent := make([]*Notification, 0, len(fines))
+	for i := range fines {
+		member, err := n.memberByID(ctx, fines[i].MemberID)
+		if err != nil {
+			return 0, err
+		}
+		if member == nil {
+			continue
+		}
+
+		notification := FineDeadlineApproaching(member, &fines[i])
+		if err := n.Notify(ctx, notification, tx); err != nil {
+			return 0, err
+		}
+		sent = append(sent, notification)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	n.Publish(ctx, sent...)
+
+	return len(fines), nil
+}
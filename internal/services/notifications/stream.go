@@ -0,0 +1,218 @@
+package notifications
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/google/uuid"
+)
+
+// Stream event types, sent as the SSE event name.
+const (
+	StreamEventNotification       = "notification"
+	StreamEventNotificationRead   = "notification.read"
+	StreamEventTransactionPending = "transaction.pending"
+	StreamEventTransactionUpdated = "transaction.updated"
+)
+
+const (
+	streamChannelPrefix     = "stream:"
+	streamUserChannelPrefix = streamChannelPrefix + "user:"
+	streamAdminChannel      = streamChannelPrefix + "admins"
+
+	// subscriptionBuffer is how many events a slow client may fall behind
+	// before further events are dropped for it.
+	subscriptionBuffer = 32
+
+	minResubscribeDelay = time.Second
+	maxResubscribeDelay = time.Second * 30
+)
+
+// StreamAdminPermissions are needed to receive the pending transactions queue.
+var StreamAdminPermissions = []constants.UserPermissions{constants.MemberReadALL}
+
+// StreamEvent is one message on the real-time stream.
+type StreamEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+type Subscription struct {
+	userID uuid.UUID
+	admin  bool
+	events chan StreamEvent
+	hub    *Hub
+	once   sync.Once
+}
+
+// Events is closed when the subscription is closed.
+func (s *Subscription) Events() <-chan StreamEvent {
+	return s.events
+}
+
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.hub.remove(s)
+	})
+}
+
+// Hub fans stream events out to the clients connected to this replica. Events
+// are published through Redis so a client receives them whichever replica it
+// is connected to.
+type Hub struct {
+	PubSub PubSub
+	Logger *logger.Logger
+
+	mu            sync.RWMutex
+	subscriptions map[*Subscription]struct{}
+}
+
+func NewHub(pubSub PubSub, logger *logger.Logger) *Hub {
+	return &Hub{
+		PubSub:        pubSub,
+		Logger:        logger,
+		subscriptions: map[*Subscription]struct{}{},
+	}
+}
+
+// Run relays published events to local subscriptions until ctx is cancelled,
+// resubscribing with a growing delay when Redis is unavailable.
+func (h *Hub) Run(ctx context.Context) {
+	h.Logger.Info().Msg("notification stream hub started")
+
+	delay := minResubscribeDelay
+	for {
+		started := time.Now()
+		err := h.PubSub.Subscribe(ctx, h.dispatch, streamChannelPrefix+"*")
+		if ctx.Err() != nil {
+			h.Logger.Info().Msg("notification stream hub stopped")
+			return
+		}
+
+		if time.Since(started) > maxResubscribeDelay {
+			delay = minResubscribeDelay
+		}
+		h.Logger.Error().Err(err).Dur("retry_in", delay).Msg("notification stream subscription lost")
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxResubscribeDelay)
+	}
+}
+
+func (h *Hub) dispatch(channel string, payload []byte) {
+	var event StreamEvent
+	if err := json.Unmarshal(payload, &event); err != nil {
+		h.Logger.Error().Err(err).Str("channel", channel).Msg("invalid stream event")
+		return
+	}
+
+	var match func(s *Subscription) bool
+	switch {
+	case channel == streamAdminChannel:
+		match = func(s *Subscription) bool { return s.admin }
+	case strings.HasPrefix(channel, streamUserChannelPrefix):
+		userID, err := uuid.Parse(strings.TrimPrefix(channel, streamUserChannelPrefix))
+		if err != nil {
+			return
+		}
+		match = func(s *Subscription) bool { return s.userID == userID }
+	default:
+		return
+	}
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+
+	for s := range h.subscriptions {
+		if !match(s) {
+			continue
+		}
+		select {
+		case s.events <- event:
+		default:
+			h.Logger.Warn().Str("user_id", s.userID.String()).Str("type", event.Type).Msg("stream client is too slow, dropping event")
+		}
+	}
+}
+
+func (h *Hub) add(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.subscriptions[s] = struct{}{}
+}
+
+func (h *Hub) remove(s *Subscription) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delete(h.subscriptions, s)
+	close(s.events)
+}
+
+// Subscribe opens a stream of the current user's notifications. Admins allowed
+// to see pending transactions also receive queue updates.
+func (n *Notifications) Subscribe(ctx context.Context) (*Subscription, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	s := &Subscription{
+		userID: actor.ID,
+		admin: users.HasAdminPermissions(ctx, StreamAdminPermissions) &&
+			(actor.MFA || !constants.RequiresMFA(actor.Permissions)),
+		events: make(chan StreamEvent, subscriptionBuffer),
+		hub:    n.Hub,
+	}
+	n.Hub.add(s)
+
+	return s, nil
+}
+
+// Publish streams the inbox entries created by Notify to connected clients.
+// Call it once the transaction passed to Notify has committed.
+func (n *Notifications) Publish(ctx context.Context, notifications ...*Notification) {
+	for _, notification := range notifications {
+		if notification == nil || notification.created == nil {
+			continue
+		}
+
+		n.publish(ctx, streamUserChannelPrefix+notification.created.UserID.String(), StreamEventNotification,
+			n.NotificationRepo.MapRepositoryToDTOModel(notification.created))
+	}
+}
+
+// PublishTransaction tells admins watching the pending queue that txn was
+// created or changed status.
+func (n *Notifications) PublishTransaction(ctx context.Context, eventType string, txn *dto.Transactions) {
+	n.publish(ctx, streamAdminChannel, eventType, txn)
+}
+
+// publish is best effort: clients fetch the inbox when they reconnect, so a
+// lost event only delays what they see.
+func (n *Notifications) publish(ctx context.Context, channel, eventType string, data any) {
+	raw, err := json.Marshal(data)
+	if err == nil {
+		err = n.PubSub.Publish(ctx, channel, StreamEvent{
+			Type: eventType,
+			Data: raw,
+		})
+	}
+	if err != nil && !errors.Is(err, context.Canceled) {
+		n.Logger.Error().Err(err).Str("type", eventType).Msg("failed to publish stream event")
+	}
+}
@@ -0,0 +1,158 @@
+package texter
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/Jidetireni/ara-cooperative/pkg/sms"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+)
+
+const (
+	PollInterval = time.Second * 5
+
+	batchSize = 20
+	sendLease = time.Minute * 2
+
+	// Texts are time sensitive (codes expire), so give up sooner than email.
+	defaultMaxAttempts = 5
+	baseBackoff        = time.Second * 15
+	maxBackoff         = time.Minute * 10
+)
+
+var (
+	_ OutboxRepository = (*repository.SMSOutboxRepository)(nil)
+	_ SMSPkg           = (*sms.SMS)(nil)
+)
+
+type OutboxRepository interface {
+	Create(ctx context.Context, msg *repository.SmsOutbox, tx *sqlx.Tx) (*repository.SmsOutbox, error)
+	ClaimDue(ctx context.Context, limit uint64, lease time.Duration) ([]repository.SmsOutbox, error)
+	MarkSent(ctx context.Context, id uuid.UUID) error
+	MarkFailed(ctx context.Context, id uuid.UUID, lastError string, nextAttemptAt time.Time) error
+}
+
+type SMSPkg interface {
+	Send(ctx context.Context, to, body string) error
+}
+
+// Message is a text to queue. To must be in E.164, see sms.NormalizePhone.
+// Set Sensitive when Body holds a one-time code, so it is wiped from the
+// outbox once the text is sent or dead-lettered.
+type Message struct {
+	To            string
+	Body          string
+	Sensitive     bool
+	ReferenceType string
+	ReferenceID   uuid.UUID
+}
+
+// Texter queues text messages in the SMS outbox and delivers them in the
+// background, the same way the mailer handles email.
+type Texter struct {
+	OutboxRepo OutboxRepository
+	SMS        SMSPkg
+	Logger     *logger.Logger
+}
+
+func New(outboxRepo OutboxRepository, smsPkg SMSPkg, logger *logger.Logger) *Texter {
+	return &Texter{
+		OutboxRepo: outboxRepo,
+		SMS:        smsPkg,
+		Logger:     logger,
+	}
+}
+
+// Enqueue stores msg in the outbox as part of tx. Nothing is sent if tx rolls back.
+func (t *Texter) Enqueue(ctx context.Context, msg *Message, tx *sqlx.Tx) error {
+	row := &repository.SmsOutbox{
+		Recipient:   msg.To,
+		Body:        msg.Body,
+		Sensitive:   msg.Sensitive,
+		MaxAttempts: defaultMaxAttempts,
+	}
+	if msg.ReferenceType != "" {
+		row.ReferenceType = repository.ToNullString(&msg.ReferenceType)
+		row.ReferenceID = repository.ToNullUUID(msg.ReferenceID)
+	}
+
+	_, err := t.OutboxRepo.Create(ctx, row, tx)
+	return err
+}
+
+// Run delivers queued messages until ctx is cancelled.
+func (t *Texter) Run(ctx context.Context) {
+	t.Logger.Info().Msg("sms outbox worker started")
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := t.DeliverDue(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			t.Logger.Error().Err(err).Msg("failed to deliver queued text messages")
+		}
+
+		select {
+		case <-ctx.Done():
+			t.Logger.Info().Msg("sms outbox worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends every message that is currently due.
+func (t *Texter) DeliverDue(ctx context.Context) error {
+	for {
+		messages, err := t.OutboxRepo.ClaimDue(ctx, batchSize, sendLease)
+		if err != nil {
+			return err
+		}
+
+		for i := range messages {
+			t.deliver(ctx, &messages[i])
+		}
+
+		if len(messages) < batchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (t *Texter) deliver(ctx context.Context, msg *repository.SmsOutbox) {
+	log := t.Logger.With().Str("sms_id", msg.ID.String()).Int32("attempt", msg.Attempts).Logger()
+
+	sendErr := t.SMS.Send(ctx, msg.Recipient, msg.Body)
+	if sendErr == nil {
+		if err := t.OutboxRepo.MarkSent(ctx, msg.ID); err != nil {
+			log.Error().Err(err).Msg("sms sent but not marked as sent")
+		}
+		return
+	}
+
+	var next time.Time
+	if msg.Attempts < msg.MaxAttempts {
+		next = time.Now().Add(backoff(msg.Attempts))
+		log.Warn().Err(sendErr).Time("next_attempt_at", next).Msg("sms delivery failed, will retry")
+	} else {
+		log.Error().Err(sendErr).Msg("sms delivery failed, giving up")
+	}
+
+	if err := t.OutboxRepo.MarkFailed(ctx, msg.ID, sendErr.Error(), next); err != nil {
+		log.Error().Err(err).Msg("failed to record sms delivery failure")
+	}
+}
+
+// backoff doubles the delay after every attempt, starting at baseBackoff.
+func backoff(attempts int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
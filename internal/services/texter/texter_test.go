@@ -0,0 +1,112 @@
+package texter
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/Jidetireni/ara-cooperative/pkg/sms"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/rs/zerolog"
+)
+
+// memoryOutbox is an OutboxRepository that records what the texter queues
+// and marks sent.
+type memoryOutbox struct {
+	messages []*repository.SmsOutbox
+	sent     []uuid.UUID
+}
+
+func (o *memoryOutbox) Create(_ context.Context, msg *repository.SmsOutbox, _ *sqlx.Tx) (*repository.SmsOutbox, error) {
+	msg.ID = uuid.New()
+	msg.Status = repository.SMSOutboxStatusPending
+	o.messages = append(o.messages, msg)
+	return msg, nil
+}
+
+func (o *memoryOutbox) ClaimDue(_ context.Context, limit uint64, _ time.Duration) ([]repository.SmsOutbox, error) {
+	var due []repository.SmsOutbox
+	for _, msg := range o.messages {
+		if msg.Status == repository.SMSOutboxStatusPending && uint64(len(due)) < limit {
+			msg.Attempts++
+			due = append(due, *msg)
+		}
+	}
+	return due, nil
+}
+
+func (o *memoryOutbox) MarkSent(_ context.Context, id uuid.UUID) error {
+	for _, msg := range o.messages {
+		if msg.ID == id {
+			msg.Status = repository.SMSOutboxStatusSent
+		}
+	}
+	o.sent = append(o.sent, id)
+	return nil
+}
+
+func (o *memoryOutbox) MarkFailed(context.Context, uuid.UUID, string, time.Time) error {
+	return nil
+}
+
+func TestInvitationCodeIsTextedAndMarkedSensitive(t *testing.T) {
+	logPath := filepath.Join(t.TempDir(), "sms.log")
+	provider, err := sms.NewLogProvider(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	outbox := &memoryOutbox{}
+	nop := zerolog.Nop()
+	texter := New(outbox, sms.NewWithProvider(&config.Config{SMS: config.SMSConfig{SenderID: "ARA Coop"}}, provider), &logger.Logger{Logger: &nop})
+
+	ctx := context.Background()
+	err = texter.Enqueue(ctx, &Message{
+		To:        "+2348012345678",
+		Body:      "ARA Coop: your code to set your password is 482913. It expires in 30 minutes. Do not share it.",
+		Sensitive: true,
+	}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := texter.DeliverDue(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	raw, err := os.ReadFile(logPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	if len(lines) != 1 {
+		t.Fatalf("logged %d texts, want 1", len(lines))
+	}
+
+	var sent sms.Message
+	if err := json.Unmarshal([]byte(lines[0]), &sent); err != nil {
+		t.Fatal(err)
+	}
+	if sent.To != "+2348012345678" || sent.From != "ARA Coop" {
+		t.Errorf("text sent from %q to %q", sent.From, sent.To)
+	}
+	if !strings.Contains(sent.Body, "482913") {
+		t.Error("texted invitation does not contain the code")
+	}
+
+	row := outbox.messages[0]
+	if !row.Sensitive {
+		t.Error("text was queued without the sensitive flag, so its code would be kept")
+	}
+	if len(outbox.sent) != 1 || outbox.sent[0] != row.ID {
+		t.Errorf("marked %v as sent, want [%s]", outbox.sent, row.ID)
+	}
+}
@@ -7,10 +7,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
 	"github.com/Jidetireni/ara-cooperative/internal/dto"
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
 	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/notifications"
 	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
 	"github.com/google/uuid"
 	"github.com/samber/lo"
 )
@@ -21,7 +24,7 @@ func (t *Transaction) ChargeFine(ctx context.Context, input *dto.FineInput) (*dt
 		return nil, svc.UnauthenticatedError()
 	}
 
-	_, err := t.MemberRepo.Get(ctx, repository.MemberRepositoryFilter{
+	member, err := t.MemberRepo.Get(ctx, repository.MemberRepositoryFilter{
 		ID: &input.MemberID,
 	})
 	if err != nil {
@@ -62,13 +65,24 @@ func (t *Transaction) ChargeFine(ctx context.Context, input *dto.FineInput) (*dt
 		return nil, err
 	}
 
+	notification := notifications.FineCharged(member, fine)
+	if err := t.Notifier.Notify(ctx, notification, tx); err != nil {
+		return nil, err
+	}
+
+	fineDTO := t.FineRepo.MapRepositoryToDTOModel(populatedFine)
+	if err := t.Webhooks.Emit(ctx, webhooks.EventFineCharged, fineDTO, tx); err != nil {
+		return nil, err
+	}
+
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
-	// TODO: Send notification to member about the fine charged
+	t.forgetMemberSummary(ctx, member.ID)
+	t.Notifier.Publish(ctx, notification)
 
-	return t.FineRepo.MapRepositoryToDTOModel(populatedFine), nil
+	return fineDTO, nil
 }
 
 func (t *Transaction) PayFine(ctx context.Context, fineID uuid.UUID, txInput *dto.TransactionsInput) (*dto.Fine, error) {
@@ -81,6 +95,9 @@ func (t *Transaction) PayFine(ctx context.Context, fineID uuid.UUID, txInput *dt
 	if err != nil {
 		return nil, err
 	}
+	if err := memberCan(member, constants.MemberActionPayFine); err != nil {
+		return nil, err
+	}
 
 	tx, err := t.DB.BeginTxx(ctx, nil)
 	if err != nil {
@@ -154,6 +171,9 @@ func (t *Transaction) PayFine(ctx context.Context, fineID uuid.UUID, txInput *dt
 		return nil, err
 	}
 
+	t.forgetMemberSummary(ctx, member.ID)
+	t.Notifier.PublishTransaction(ctx, notifications.StreamEventTransactionPending, t.TransactionRepo.MapRepositoryToDTOModel(createdTxn))
+
 	return t.FineRepo.MapRepositoryToDTOModel(populatedFine), nil
 }
 
@@ -0,0 +1,126 @@
+package transactions
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/internal/services/events"
+	"github.com/Jidetireni/ara-cooperative/internal/services/notifications"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+// SettleFine marks the fine paid by a confirmed FINES transaction.
+func (t *Transaction) SettleFine(ctx context.Context, event events.TransactionConfirmed) error {
+	if event.Ledger != repository.LedgerTypeFINES {
+		return nil
+	}
+
+	tx, err := t.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	fine, err := t.FineRepo.GetPopulated(ctx, repository.FineRepositoryFilter{
+		TransactionID: &event.TransactionID,
+	}, tx)
+	if err != nil {
+		return err
+	}
+	if fine.PaidAt.Valid {
+		return nil
+	}
+
+	paid, err := t.FineRepo.Update(ctx, &repository.Fine{
+		ID:            fine.ID,
+		AdminID:       fine.AdminID,
+		MemberID:      fine.MemberID,
+		TransactionID: fine.TransactionID,
+		Amount:        fine.Amount,
+		Reason:        fine.Reason,
+		Deadline:      fine.Deadline,
+		PaidAt:        sql.NullTime{Time: time.Now(), Valid: true},
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	fine.Fine = *paid
+	if err := t.Webhooks.Emit(ctx, webhooks.EventFinePaid, t.FineRepo.MapRepositoryToDTOModel(fine), tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	t.forgetMemberSummary(ctx, fine.MemberID)
+	return nil
+}
+
+// NotifyConfirmed tells the member their transaction went through. Share
+// purchases are announced with the units allocated.
+func (t *Transaction) NotifyConfirmed(ctx context.Context, event events.TransactionConfirmed) error {
+	tx, err := t.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txn, err := t.TransactionRepo.GetPopulated(ctx, repository.TransactionRepositoryFilter{
+		ID: lo.ToPtr(event.TransactionID),
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	notification := notifications.TransactionConfirmed(txn)
+	if event.Ledger == repository.LedgerTypeSHARES {
+		share, err := t.ShareRepo.GetPopulated(ctx, repository.ShareRepositoryFilter{
+			TransactionID: &txn.ID,
+		}, tx)
+		if err != nil {
+			return err
+		}
+		notification = notifications.SharesAllocated(txn, &share.Share)
+	}
+
+	return t.notify(ctx, notification, tx)
+}
+
+func (t *Transaction) NotifyRejected(ctx context.Context, event events.TransactionRejected) error {
+	tx, err := t.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	txn, err := t.TransactionRepo.GetPopulated(ctx, repository.TransactionRepositoryFilter{
+		ID: lo.ToPtr(event.TransactionID),
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	return t.notify(ctx, notifications.TransactionRejected(txn), tx)
+}
+
+// notify sends notification as part of tx, commits it and streams the
+// inbox entry to the member.
+func (t *Transaction) notify(ctx context.Context, notification *notifications.Notification, tx *sqlx.Tx) error {
+	if err := t.Notifier.Notify(ctx, notification, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	t.Notifier.Publish(ctx, notification)
+
+	return nil
+}
@@ -18,6 +18,10 @@ const (
 	SharesUnitPriceRedisKey = "shares_unit_price"
 	SharesUnitPriceCacheTTL = time.Hour * 24 * 7
 	SharePrecisionScale     = 1e4
+
+	MemberSummaryRedisKeyPrefix = "member_summary:"
+	MemberSummaryCacheTTL       = 30 * time.Second
+	MemberSummaryListLimit      = 10
 )
 
 // TransactionParams contains parameters for creating transactions
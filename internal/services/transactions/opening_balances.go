@@ -0,0 +1,381 @@
+package transactions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+const (
+	// OpeningBalanceMaxRows caps the size of one opening balance file.
+	OpeningBalanceMaxRows = 20000
+
+	// OpeningFineGracePeriod is how long members get to pay a carried-over
+	// fine when the file gives no deadline.
+	OpeningFineGracePeriod = 30 * 24 * time.Hour
+
+	openingBalanceDescription = "Opening balance"
+	openingBalanceDateLayout  = time.DateOnly
+)
+
+// openingBalanceColumns are the columns an opening balance file must have.
+// units and unit_price are only used on the shares ledger, deadline only on
+// fines, and description is optional.
+var openingBalanceColumns = []string{"member", "ledger", "amount", "effective_date"}
+
+// openingBalanceLedgers maps the ledger column of an opening balance file to
+// the ledger it is posted to.
+var openingBalanceLedgers = map[string]repository.LedgerType{
+	"savings":         repository.LedgerTypeSAVINGS,
+	"special_deposit": repository.LedgerTypeSPECIALDEPOSIT,
+	"shares":          repository.LedgerTypeSHARES,
+	"fines":           repository.LedgerTypeFINES,
+}
+
+type openingBalanceRow struct {
+	line        int
+	member      *repository.Member
+	ledger      repository.LedgerType
+	amount      int64
+	units       int64 // scaled by SharePrecisionScale
+	unitPrice   int64
+	effectiveAt time.Time
+	deadline    time.Time
+	description string
+}
+
+// ImportOpeningBalances posts the balances members held before go-live, one
+// per member and ledger, from a CSV file. Savings, special deposits and shares
+// are posted as confirmed deposits marked as opening balances and dated on
+// their effective date, so balances and share totals include them. Fines are
+// created unpaid.
+//
+// Every row is validated first and nothing is posted unless all rows are
+// valid; with dryRun set only the report is returned. The rows are posted in
+// one database transaction.
+func (t *Transaction) ImportOpeningBalances(ctx context.Context, file io.Reader, dryRun bool) (*dto.OpeningBalanceImportReport, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	records, err := readOpeningBalances(file)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &dto.OpeningBalanceImportReport{
+		DryRun:    dryRun,
+		TotalRows: len(records),
+		Errors:    []dto.ImportRowError{},
+	}
+
+	rows, err := t.validateOpeningBalances(ctx, records, report)
+	if err != nil {
+		return nil, err
+	}
+	report.ValidRows = len(rows)
+
+	if dryRun || len(report.Errors) > 0 {
+		return report, nil
+	}
+
+	tx, err := t.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	for _, row := range rows {
+		if err := t.postOpeningBalance(ctx, row, actor.ID, tx); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		t.forgetMemberSummary(ctx, row.member.ID)
+	}
+
+	report.Posted = len(rows)
+	return report, nil
+}
+
+func (t *Transaction) postOpeningBalance(ctx context.Context, row openingBalanceRow, adminID uuid.UUID, tx *sqlx.Tx) error {
+	if row.ledger == repository.LedgerTypeFINES {
+		_, err := t.FineRepo.Create(ctx, &repository.Fine{
+			AdminID:        adminID,
+			MemberID:       row.member.ID,
+			Amount:         row.amount,
+			Reason:         row.description,
+			Deadline:       row.deadline,
+			CreatedAt:      row.effectiveAt,
+			OpeningBalance: true,
+		}, tx)
+		return err
+	}
+
+	transaction, err := t.postConfirmed(ctx, repository.Transaction{
+		MemberID:       row.member.ID,
+		Description:    row.description,
+		Amount:         row.amount,
+		Type:           repository.TransactionTypeDEPOSIT,
+		Ledger:         row.ledger,
+		CreatedAt:      sql.NullTime{Time: row.effectiveAt, Valid: true},
+		OpeningBalance: true,
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	if row.ledger == repository.LedgerTypeSHARES {
+		_, err = t.ShareRepo.Create(ctx, repository.Share{
+			TransactionID: transaction.ID,
+			Units:         fmt.Sprintf("%.4f", float64(row.units)/SharePrecisionScale),
+			UnitPrice:     row.unitPrice,
+		}, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+type openingBalanceRecord struct {
+	line   int
+	fields map[string]string
+}
+
+func readOpeningBalances(file io.Reader) ([]openingBalanceRecord, error) {
+	reader := csv.NewReader(file)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, invalidOpeningBalancesError("the file is empty")
+		}
+		return nil, invalidOpeningBalancesError(err.Error())
+	}
+
+	header = lo.Map(header, func(name string, _ int) string {
+		return strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+	})
+	missing := lo.Without(openingBalanceColumns, header...)
+	if len(missing) > 0 {
+		return nil, invalidOpeningBalancesError(fmt.Sprintf("missing columns: %s", strings.Join(missing, ", ")))
+	}
+
+	var records []openingBalanceRecord
+	for line := 2; ; line++ {
+		values, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, invalidOpeningBalancesError(err.Error())
+		}
+		if len(records) == OpeningBalanceMaxRows {
+			return nil, invalidOpeningBalancesError(fmt.Sprintf("a file can hold at most %d rows", OpeningBalanceMaxRows))
+		}
+
+		fields := make(map[string]string, len(header))
+		for i, name := range header {
+			fields[name] = strings.TrimSpace(values[i])
+		}
+		records = append(records, openingBalanceRecord{line: line, fields: fields})
+	}
+
+	if len(records) == 0 {
+		return nil, invalidOpeningBalancesError("the file has no rows")
+	}
+
+	return records, nil
+}
+
+// validateOpeningBalances records every problem with records in report and
+// returns the rows that have none.
+func (t *Transaction) validateOpeningBalances(ctx context.Context, records []openingBalanceRecord, report *dto.OpeningBalanceImportReport) ([]openingBalanceRow, error) {
+	today := time.Now()
+	members := map[string]*repository.Member{}
+	seen := map[string]int{}
+	// existing holds whether a member already has opening balances on a
+	// ledger, keyed by member and ledger.
+	existing := map[string]bool{}
+
+	var rows []openingBalanceRow
+	for _, record := range records {
+		var errs []dto.ImportRowError
+		fail := func(field, message string) {
+			errs = append(errs, dto.ImportRowError{Row: record.line, Field: field, Message: message})
+		}
+
+		row := openingBalanceRow{
+			line:        record.line,
+			description: lo.CoalesceOrEmpty(record.fields["description"], openingBalanceDescription),
+		}
+
+		slug := strings.ToLower(record.fields["member"])
+		member, ok := members[slug]
+		if !ok && slug != "" {
+			found, err := t.MemberRepo.Get(ctx, repository.MemberRepositoryFilter{Slug: &slug})
+			if err != nil && !errors.Is(err, sql.ErrNoRows) {
+				return nil, err
+			}
+			member = found
+			members[slug] = member
+		}
+		switch {
+		case slug == "":
+			fail("member", "member is required")
+		case member == nil:
+			fail("member", "no member has this number")
+		case member.Status == repository.MemberStatusExited:
+			fail("member", "the member has left the cooperative")
+		default:
+			row.member = member
+		}
+
+		ledger, knownLedger := openingBalanceLedgers[strings.ToLower(record.fields["ledger"])]
+		if !knownLedger {
+			fail("ledger", "ledger must be one of savings, special_deposit, shares, fines")
+		}
+		row.ledger = ledger
+
+		effectiveAt, err := time.Parse(openingBalanceDateLayout, record.fields["effective_date"])
+		switch {
+		case err != nil:
+			fail("effective_date", "effective_date must be a date like 2024-12-31")
+		case effectiveAt.After(today):
+			fail("effective_date", "effective_date cannot be in the future")
+		}
+		row.effectiveAt = effectiveAt
+
+		if ledger == repository.LedgerTypeSHARES {
+			row.units, row.unitPrice, row.amount = parseOpeningShares(record.fields, fail)
+		} else if amount, err := strconv.ParseInt(record.fields["amount"], 10, 64); err != nil || amount <= 0 {
+			fail("amount", "amount must be a whole number greater than zero")
+		} else {
+			row.amount = amount
+		}
+
+		if ledger == repository.LedgerTypeFINES {
+			row.deadline = today.Add(OpeningFineGracePeriod)
+			if v := record.fields["deadline"]; v != "" {
+				deadline, err := time.Parse(openingBalanceDateLayout, v)
+				if err != nil {
+					fail("deadline", "deadline must be a date like 2024-12-31")
+				}
+				row.deadline = deadline
+			}
+		}
+
+		if row.member != nil && knownLedger {
+			key := row.member.ID.String() + ":" + string(ledger)
+			// Members can carry several fines over, but only one balance
+			// per other ledger.
+			if first, dup := seen[key]; dup && ledger != repository.LedgerTypeFINES {
+				fail("ledger", fmt.Sprintf("the member already has a %s balance on row %d", record.fields["ledger"], first))
+			} else {
+				seen[key] = record.line
+			}
+
+			if _, checked := existing[key]; !checked {
+				has, err := t.hasOpeningBalance(ctx, row.member.ID, ledger)
+				if err != nil {
+					return nil, err
+				}
+				existing[key] = has
+			}
+			if existing[key] {
+				fail("ledger", "the member's opening balance on this ledger was already imported")
+			}
+		}
+
+		if len(errs) > 0 {
+			report.Errors = append(report.Errors, errs...)
+			continue
+		}
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// parseOpeningShares reads the units and unit price of a shares row. amount
+// may be left out; when given it must match their product.
+func parseOpeningShares(fields map[string]string, fail func(field, message string)) (int64, int64, int64) {
+	var units, unitPrice int64
+
+	parsedUnits, err := strconv.ParseFloat(fields["units"], 64)
+	if err != nil || parsedUnits <= 0 {
+		fail("units", "units must be a number greater than zero")
+	} else {
+		units = int64(math.Round(parsedUnits * SharePrecisionScale))
+	}
+
+	unitPrice, err = strconv.ParseInt(fields["unit_price"], 10, 64)
+	if err != nil || unitPrice <= 0 {
+		fail("unit_price", "unit_price must be a whole number greater than zero")
+	}
+
+	amount := units * unitPrice / SharePrecisionScale
+	if v := fields["amount"]; v != "" && units > 0 && unitPrice > 0 {
+		given, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || given != amount {
+			fail("amount", fmt.Sprintf("amount must be units times unit_price, %d", amount))
+		}
+	}
+
+	return units, unitPrice, amount
+}
+
+func (t *Transaction) hasOpeningBalance(ctx context.Context, memberID uuid.UUID, ledger repository.LedgerType) (bool, error) {
+	if ledger == repository.LedgerTypeFINES {
+		fines, err := t.FineRepo.ListPopulated(ctx, repository.FineRepositoryFilter{
+			MemberID:       &memberID,
+			OpeningBalance: lo.ToPtr(true),
+		}, repository.QueryOptions{Limit: 1})
+		if err != nil {
+			return false, err
+		}
+		return len(fines.Items) > 0, nil
+	}
+
+	transactions, err := t.TransactionRepo.ListPopulated(ctx, repository.TransactionRepositoryFilter{
+		MemberID:       &memberID,
+		LedgerType:     &ledger,
+		OpeningBalance: lo.ToPtr(true),
+	}, repository.QueryOptions{Limit: 1})
+	if err != nil {
+		return false, err
+	}
+	return len(transactions.Items) > 0, nil
+}
+
+func invalidOpeningBalancesError(message string) *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusBadRequest,
+		Message: fmt.Sprintf("invalid opening balance file: %s", message),
+	}
+}
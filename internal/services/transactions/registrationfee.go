@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
 	"github.com/Jidetireni/ara-cooperative/internal/dto"
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
 	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/notifications"
 	"github.com/Jidetireni/ara-cooperative/internal/services/users"
 )
 
@@ -29,11 +31,8 @@ func (t *Transaction) ChargeRegistrationFee(ctx context.Context, input *dto.Tran
 		return nil, err
 	}
 
-	if member.ActivatedAt.Valid {
-		return nil, &svc.APIError{
-			Status:  http.StatusBadRequest,
-			Message: "member already activated",
-		}
+	if err := memberCan(member, constants.MemberActionPayRegistrationFee); err != nil {
+		return nil, err
 	}
 
 	tx, err := t.DB.BeginTxx(ctx, nil)
@@ -65,5 +64,9 @@ func (t *Transaction) ChargeRegistrationFee(ctx context.Context, input *dto.Tran
 		return nil, err
 	}
 
-	return t.TransactionRepo.MapRepositoryToDTOModel(transaction), nil
+	t.forgetMemberSummary(ctx, member.ID)
+	result := t.TransactionRepo.MapRepositoryToDTOModel(transaction)
+	t.Notifier.PublishTransaction(ctx, notifications.StreamEventTransactionPending, result)
+
+	return result, nil
 }
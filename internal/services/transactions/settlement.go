@@ -0,0 +1,439 @@
+package transactions
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+// SettlementStatement is what the member would be paid if their exit were
+// settled now, or the posted settlement once it has been.
+func (t *Transaction) SettlementStatement(ctx context.Context, slug string) (*dto.MemberSettlement, error) {
+	member, err := t.getMemberBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+
+	settled, err := t.SettlementRepo.GetByMember(ctx, member.ID)
+	switch {
+	case err == nil:
+		out := t.SettlementRepo.MapRepositoryToDTOModel(settled)
+		if err := decodeAllocations(settled, out); err != nil {
+			return nil, err
+		}
+		return out, nil
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, err
+	}
+
+	if member.Status != repository.MemberStatusExiting {
+		return nil, notExitingError()
+	}
+
+	statement, _, err := t.settlementStatement(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+
+	out := statementToDTOModel(statement)
+	if err := decodeAllocations(statement, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
+
+// SettleExit pays out an exiting member. In one database transaction it
+// withdraws their savings and special deposit, buys back their shares at the
+// current unit price, pays their unpaid fines out of the proceeds, records the
+// statement and marks the member exited. The postings are confirmed as made;
+// the net amount is paid to the member outside the system. A member whose
+// beneficiaries are allocated neither 0 nor 100% cannot be settled.
+func (t *Transaction) SettleExit(ctx context.Context, slug string) (*dto.MemberSettlement, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	member, err := t.getMemberBySlug(ctx, slug)
+	if err != nil {
+		return nil, err
+	}
+	if member.Status != repository.MemberStatusExiting {
+		return nil, notExitingError()
+	}
+
+	tx, err := t.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	// Moving the member out of EXITING first locks their row, so a second
+	// settlement waits here and then fails instead of paying out twice.
+	_, err = t.Members.Transition(ctx, member, repository.MemberStatusExited, "exit settled", actor.ID, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	// The statement is worked out only now, so anything confirmed while the
+	// settlement waited for the lock is paid out too. A transaction still
+	// being confirmed is pending and stops the settlement.
+	statement, fines, err := t.settlementStatement(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+	if statement.NetAmount < 0 {
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: fmt.Sprintf("the member owes %d more than they are owed, collect it before settling", -statement.NetAmount),
+		}
+	}
+	statement.SettledBy = actor.ID
+
+	withdrawals := []struct {
+		ledger repository.LedgerType
+		amount int64
+	}{
+		{repository.LedgerTypeSAVINGS, statement.Savings},
+		{repository.LedgerTypeSPECIALDEPOSIT, statement.SpecialDeposit},
+	}
+	for _, w := range withdrawals {
+		if w.amount <= 0 {
+			continue
+		}
+
+		_, err := t.postConfirmed(ctx, repository.Transaction{
+			MemberID:    member.ID,
+			Description: "Exit settlement",
+			Amount:      w.amount,
+			Type:        repository.TransactionTypeWITHDRAWAL,
+			Ledger:      w.ledger,
+		}, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if settlementUnits(statement) > 0 {
+		buyBack, err := t.postConfirmed(ctx, repository.Transaction{
+			MemberID:    member.ID,
+			Description: "Share buy-back on exit",
+			Amount:      statement.ShareValue,
+			Type:        repository.TransactionTypeWITHDRAWAL,
+			Ledger:      repository.LedgerTypeSHARES,
+		}, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = t.ShareRepo.Create(ctx, repository.Share{
+			TransactionID: buyBack.ID,
+			Units:         statement.ShareUnits,
+			UnitPrice:     statement.ShareUnitPrice,
+		}, tx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, fine := range fines {
+		payment, err := t.postConfirmed(ctx, repository.Transaction{
+			MemberID:    member.ID,
+			Description: "Deducted from exit settlement",
+			Amount:      fine.Amount,
+			Type:        repository.TransactionTypeDEPOSIT,
+			Ledger:      repository.LedgerTypeFINES,
+		}, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		paid, err := t.FineRepo.Update(ctx, &repository.Fine{
+			ID:            fine.ID,
+			AdminID:       fine.AdminID,
+			MemberID:      fine.MemberID,
+			TransactionID: uuid.NullUUID{UUID: payment.ID, Valid: true},
+			Amount:        fine.Amount,
+			Reason:        fine.Reason,
+			Deadline:      fine.Deadline,
+			PaidAt:        sql.NullTime{Time: time.Now(), Valid: true},
+		}, tx)
+		if err != nil {
+			return nil, err
+		}
+
+		fine.Fine = *paid
+		if err := t.Webhooks.Emit(ctx, webhooks.EventFinePaid, t.FineRepo.MapRepositoryToDTOModel(fine), tx); err != nil {
+			return nil, err
+		}
+	}
+
+	settlement, err := t.SettlementRepo.Create(ctx, statement, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	t.forgetMemberSummary(ctx, member.ID)
+	if err := t.Sessions.RevokeSessions(ctx, member.UserID); err != nil {
+		t.Logger.Error().Err(err).Str("member_id", member.ID.String()).Msg("failed to revoke sessions of exited member")
+	}
+
+	out := t.SettlementRepo.MapRepositoryToDTOModel(settlement)
+	// The settlement is posted by now, so a bad allocation only spoils
+	// this response.
+	if err := decodeAllocations(settlement, out); err != nil {
+		t.Logger.Error().Err(err).Str("member_id", member.ID.String()).Msg("failed to read settled beneficiary allocations")
+	}
+
+	return out, nil
+}
+
+// settlementStatement works out the member's entitlement from the ledgers and
+// returns it with the unpaid fines it offsets. Unconfirmed transactions must
+// be confirmed or rejected first so the balances are final.
+func (t *Transaction) settlementStatement(ctx context.Context, member *repository.Member) (*repository.MemberSettlement, []*repository.PopulatedFine, error) {
+	pending, err := t.TransactionRepo.ListPopulated(ctx, repository.TransactionRepositoryFilter{
+		MemberID:  &member.ID,
+		Confirmed: lo.ToPtr(false),
+		Rejected:  lo.ToPtr(false),
+	}, repository.QueryOptions{Limit: 1})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(pending.Items) > 0 {
+		return nil, nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "the member has transactions awaiting confirmation, confirm or reject them before settling",
+		}
+	}
+
+	savings, err := t.memberBalance(ctx, member.ID, repository.LedgerTypeSAVINGS)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	specialDeposit, err := t.memberBalance(ctx, member.ID, repository.LedgerTypeSPECIALDEPOSIT)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	units, _, err := t.shareHoldings(ctx, repository.ShareRepositoryFilter{
+		MemberID:   &member.ID,
+		Confirmed:  lo.ToPtr(true),
+		Rejected:   lo.ToPtr(false),
+		LedgerType: lo.ToPtr(repository.LedgerTypeSHARES),
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unitPrice, err := t.GetSharesUnitPrice(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	fines, err := t.unpaidFines(ctx, member.ID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	statement := &repository.MemberSettlement{
+		MemberID:       member.ID,
+		Savings:        savings,
+		SpecialDeposit: specialDeposit,
+		ShareUnits:     fmt.Sprintf("%.4f", float64(units)/SharePrecisionScale),
+		ShareUnitPrice: unitPrice,
+		ShareValue:     units * unitPrice / SharePrecisionScale,
+		UnpaidFines: lo.SumBy(fines, func(fine *repository.PopulatedFine) int64 {
+			return fine.Amount
+		}),
+		// There are no loans yet; this is where their outstanding balance
+		// will be offset.
+		OutstandingLoans: 0,
+	}
+	statement.NetAmount = statement.Savings + statement.SpecialDeposit + statement.ShareValue -
+		statement.UnpaidFines - statement.OutstandingLoans
+
+	beneficiaries, err := t.BeneficiaryRepo.List(ctx, member.ID, nil)
+	if err != nil {
+		return nil, nil, err
+	}
+	// A partial nomination says neither who gets the rest nor whether the
+	// member meant it, so it has to be completed or cleared first.
+	if allocated := allocatedHundredths(beneficiaries); allocated != 0 && allocated != repository.FullPercentage {
+		return nil, nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: fmt.Sprintf("the member's beneficiaries are allocated %s%%, their nomination must add up to 100%% before settling", repository.FormatPercentage(allocated)),
+		}
+	}
+	statement.Beneficiaries, err = json.Marshal(allocateNetAmount(statement.NetAmount, beneficiaries))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return statement, fines, nil
+}
+
+func allocatedHundredths(beneficiaries []repository.MemberBeneficiary) int64 {
+	return lo.SumBy(beneficiaries, func(b repository.MemberBeneficiary) int64 {
+		return repository.PercentageHundredths(b.Percentage)
+	})
+}
+
+// allocateNetAmount splits netAmount between the beneficiaries by their
+// percentages. Amounts are rounded down and the remainder goes to the largest
+// share, so they add up to netAmount exactly. Nothing is allocated unless the
+// percentages add up to 100.
+func allocateNetAmount(netAmount int64, beneficiaries []repository.MemberBeneficiary) []dto.BeneficiaryAllocation {
+	shares := lo.Filter(beneficiaries, func(b repository.MemberBeneficiary, _ int) bool {
+		return repository.PercentageHundredths(b.Percentage) > 0
+	})
+	if allocatedHundredths(shares) != repository.FullPercentage {
+		return []dto.BeneficiaryAllocation{}
+	}
+
+	payable := max(netAmount, 0)
+	allocations := make([]dto.BeneficiaryAllocation, len(shares))
+	largest, allocated := 0, int64(0)
+	for i, b := range shares {
+		hundredths := repository.PercentageHundredths(b.Percentage)
+		allocations[i] = dto.BeneficiaryAllocation{
+			BeneficiaryID: b.ID,
+			Name:          b.Name,
+			Relationship:  b.Relationship,
+			Percentage:    float64(hundredths) / 100,
+			Amount:        payable * hundredths / repository.FullPercentage,
+		}
+		allocated += allocations[i].Amount
+		if hundredths > repository.PercentageHundredths(shares[largest].Percentage) {
+			largest = i
+		}
+	}
+	allocations[largest].Amount += payable - allocated
+
+	return allocations
+}
+
+func (t *Transaction) unpaidFines(ctx context.Context, memberID uuid.UUID) ([]*repository.PopulatedFine, error) {
+	var fines []*repository.PopulatedFine
+	opts := repository.QueryOptions{Limit: 100}
+	for {
+		result, err := t.FineRepo.ListPopulated(ctx, repository.FineRepositoryFilter{
+			MemberID: &memberID,
+			Paid:     lo.ToPtr(false),
+		}, opts)
+		if err != nil {
+			return nil, err
+		}
+
+		fines = append(fines, result.Items...)
+		if result.NextCursor == nil {
+			return fines, nil
+		}
+		opts.Cursor = result.NextCursor
+	}
+}
+
+// postConfirmed records transaction as already confirmed, on the date it was
+// made when it is backdated.
+func (t *Transaction) postConfirmed(ctx context.Context, transaction repository.Transaction, tx *sqlx.Tx) (*repository.Transaction, error) {
+	if transaction.Reference == "" {
+		transaction.Reference = lo.RandomString(12, lo.AlphanumericCharset)
+	}
+
+	created, err := t.TransactionRepo.Create(ctx, transaction, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	confirmedAt := time.Now()
+	if transaction.CreatedAt.Valid {
+		confirmedAt = transaction.CreatedAt.Time
+	}
+
+	_, err = t.TransactionRepo.CreateStatus(ctx, repository.TransactionStatus{
+		TransactionID: created.ID,
+		ConfirmedAt:   sql.NullTime{Time: confirmedAt, Valid: true},
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (t *Transaction) getMemberBySlug(ctx context.Context, slug string) (*repository.Member, error) {
+	member, err := t.MemberRepo.Get(ctx, repository.MemberRepositoryFilter{
+		Slug: &slug,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	return member, nil
+}
+
+func statementToDTOModel(statement *repository.MemberSettlement) *dto.MemberSettlement {
+	out := &dto.MemberSettlement{
+		MemberID:         statement.MemberID,
+		Savings:          statement.Savings,
+		SpecialDeposit:   statement.SpecialDeposit,
+		ShareUnits:       settlementUnits(statement),
+		ShareUnitPrice:   statement.ShareUnitPrice,
+		ShareValue:       statement.ShareValue,
+		UnpaidFines:      statement.UnpaidFines,
+		OutstandingLoans: statement.OutstandingLoans,
+		NetAmount:        statement.NetAmount,
+	}
+
+	return out
+}
+
+// decodeAllocations reads the beneficiary allocations stored with a
+// statement into out.
+func decodeAllocations(statement *repository.MemberSettlement, out *dto.MemberSettlement) error {
+	out.Beneficiaries = []dto.BeneficiaryAllocation{}
+	if len(statement.Beneficiaries) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(statement.Beneficiaries, &out.Beneficiaries); err != nil {
+		return fmt.Errorf("data corruption: invalid beneficiary allocations of settlement: %w", err)
+	}
+
+	return nil
+}
+
+func settlementUnits(statement *repository.MemberSettlement) float64 {
+	units, _ := strconv.ParseFloat(statement.ShareUnits, 64)
+	return units
+}
+
+func notExitingError() *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusConflict,
+		Message: "only an exiting member can be settled",
+	}
+}
@@ -5,12 +5,15 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
+	"math"
 	"net/http"
 	"strconv"
 
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
 	"github.com/Jidetireni/ara-cooperative/internal/dto"
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
 	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/notifications"
 	"github.com/Jidetireni/ara-cooperative/internal/services/users"
 	"github.com/samber/lo"
 )
@@ -74,6 +77,21 @@ func (t *Transaction) GetSharesUnitPrice(ctx context.Context) (int64, error) {
 	return price, nil
 }
 
+// WarmSharesUnitPriceCache reloads the cached unit price from the database so
+// quotes do not fall through to it after the cache entry expires.
+func (t *Transaction) WarmSharesUnitPriceCache(ctx context.Context) error {
+	price, err := t.ShareRepo.GetUnitPrice(ctx)
+	if err != nil {
+		// No price has been set, GetSharesUnitPrice falls back to the default.
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	return t.RedisPkg.SetPrimitive(ctx, SharesUnitPriceRedisKey, strconv.FormatInt(price, 10), SharesUnitPriceCacheTTL)
+}
+
 func (t *Transaction) calculateShareQuote(ctx context.Context, amount int64) (*calculateShareQuoteResult, error) {
 	if amount <= 0 {
 		return nil, &svc.APIError{
@@ -152,6 +170,9 @@ func (t *Transaction) BuyShares(ctx context.Context, input dto.BuySharesInput) (
 	if err != nil {
 		return nil, err
 	}
+	if err := memberCan(member, constants.MemberActionBuyShares); err != nil {
+		return nil, err
+	}
 
 	tx, err := t.DB.BeginTxx(ctx, nil)
 	if err != nil {
@@ -191,6 +212,9 @@ func (t *Transaction) BuyShares(ctx context.Context, input dto.BuySharesInput) (
 		return nil, err
 	}
 
+	t.forgetMemberSummary(ctx, member.ID)
+	t.Notifier.PublishTransaction(ctx, notifications.StreamEventTransactionPending, t.TransactionRepo.MapRepositoryToDTOModel(transaction))
+
 	return t.ShareRepo.MapRepositoryToDTOModel(populatedShare), nil
 }
 
@@ -198,7 +222,6 @@ func (t *Transaction) GetTotalShares(ctx context.Context) (*dto.SharesTotal, err
 	filters := repository.ShareRepositoryFilter{
 		Confirmed:  lo.ToPtr(true),
 		Rejected:   lo.ToPtr(false),
-		Type:       lo.ToPtr(repository.TransactionTypeDEPOSIT),
 		LedgerType: lo.ToPtr(repository.LedgerTypeSHARES),
 	}
 
@@ -219,7 +242,6 @@ func (t *Transaction) GetMemberTotalShares(ctx context.Context) (*dto.SharesTota
 	filters := repository.ShareRepositoryFilter{
 		Confirmed:  lo.ToPtr(true),
 		Rejected:   lo.ToPtr(false),
-		Type:       lo.ToPtr(repository.TransactionTypeDEPOSIT),
 		MemberID:   &member.ID,
 		LedgerType: lo.ToPtr(repository.LedgerTypeSHARES),
 	}
@@ -227,22 +249,46 @@ func (t *Transaction) GetMemberTotalShares(ctx context.Context) (*dto.SharesTota
 	return t.calculateShareTotals(ctx, filters)
 }
 
+// calculateShareTotals nets the shares bought under filters against those
+// bought back, e.g. when a member exits.
 func (t *Transaction) calculateShareTotals(ctx context.Context, filters repository.ShareRepositoryFilter) (*dto.SharesTotal, error) {
-	total, err := t.ShareRepo.CountTotalSharesPurchased(ctx, filters)
+	units, amount, err := t.shareHoldings(ctx, filters)
 	if err != nil {
 		return nil, err
 	}
 
-	var units float64
-	if total.Units != "" {
-		units, err = strconv.ParseFloat(total.Units, 64)
+	return &dto.SharesTotal{
+		Units:  float64(units) / SharePrecisionScale,
+		Amount: amount,
+	}, nil
+}
+
+// shareHoldings returns the units held under filters, scaled by
+// SharePrecisionScale, and the amount paid for them.
+func (t *Transaction) shareHoldings(ctx context.Context, filters repository.ShareRepositoryFilter) (int64, int64, error) {
+	var units, amount int64
+	for _, txnType := range []repository.TransactionType{repository.TransactionTypeDEPOSIT, repository.TransactionTypeWITHDRAWAL} {
+		filters.Type = lo.ToPtr(txnType)
+		total, err := t.ShareRepo.CountTotalSharesPurchased(ctx, filters)
 		if err != nil {
-			return nil, fmt.Errorf("data corruption: invalid unit count in db: %w", err)
+			return 0, 0, err
 		}
+
+		var scaled int64
+		if total.Units != "" {
+			f, err := strconv.ParseFloat(total.Units, 64)
+			if err != nil {
+				return 0, 0, fmt.Errorf("data corruption: invalid unit count in db: %w", err)
+			}
+			scaled = int64(math.Round(f * SharePrecisionScale))
+		}
+
+		if txnType == repository.TransactionTypeWITHDRAWAL {
+			scaled, total.Amount = -scaled, -total.Amount
+		}
+		units += scaled
+		amount += total.Amount
 	}
 
-	return &dto.SharesTotal{
-		Units:  units,
-		Amount: total.Amount,
-	}, nil
+	return units, amount, nil
 }
@@ -0,0 +1,136 @@
+package transactions
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+// MemberSummary returns the signed-in member's dashboard. It is cached for
+// MemberSummaryCacheTTL and dropped whenever one of the member's transactions
+// or fines changes, so only a new share unit price can take up to the TTL to
+// show. The member's status is always read fresh.
+func (t *Transaction) MemberSummary(ctx context.Context) (*dto.MemberSummary, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	member, err := t.getMemberByUserID(ctx, actor.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var summary dto.MemberSummary
+	if err := t.RedisPkg.Get(ctx, memberSummaryKey(member.ID), &summary); err == nil {
+		summary.Status = member.Status
+		return &summary, nil
+	}
+
+	result, err := t.memberSummary(ctx, member)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		err := t.RedisPkg.Set(context.Background(), memberSummaryKey(member.ID), result, MemberSummaryCacheTTL)
+		if err != nil {
+			t.Logger.Error().Err(err).Msg("failed to set member summary cache")
+		}
+	}()
+
+	return result, nil
+}
+
+func (t *Transaction) memberSummary(ctx context.Context, member *repository.Member) (*dto.MemberSummary, error) {
+	summary := &dto.MemberSummary{
+		MemberID:    member.ID,
+		Status:      member.Status,
+		GeneratedAt: time.Now(),
+	}
+
+	totals, err := t.TransactionRepo.LedgerTotals(ctx, member.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	var units int64
+	for _, total := range totals {
+		switch total.Ledger {
+		case repository.LedgerTypeSAVINGS:
+			summary.Savings = total.Balance
+		case repository.LedgerTypeSPECIALDEPOSIT:
+			summary.SpecialDeposit = total.Balance
+		case repository.LedgerTypeREGISTRATIONFEE:
+			summary.RegistrationFee = total.Balance
+		case repository.LedgerTypeSHARES:
+			f, err := strconv.ParseFloat(total.Units, 64)
+			if err != nil {
+				return nil, fmt.Errorf("data corruption: invalid unit count in db: %w", err)
+			}
+			units = int64(math.Round(f * SharePrecisionScale))
+		}
+	}
+
+	unitPrice, err := t.GetSharesUnitPrice(ctx)
+	if err != nil {
+		return nil, err
+	}
+	summary.ShareUnits = float64(units) / SharePrecisionScale
+	summary.ShareUnitPrice = unitPrice
+	summary.ShareValue = units * unitPrice / SharePrecisionScale
+
+	pending, err := t.TransactionRepo.ListPopulated(ctx, repository.TransactionRepositoryFilter{
+		MemberID:  &member.ID,
+		Confirmed: lo.ToPtr(false),
+		Rejected:  lo.ToPtr(false),
+	}, repository.QueryOptions{Limit: MemberSummaryListLimit})
+	if err != nil {
+		return nil, err
+	}
+	summary.PendingTransactions = lo.Map(pending.Items, func(txn *repository.PopulatedTransaction, _ int) dto.Transactions {
+		return *t.TransactionRepo.MapRepositoryToDTOModel(txn)
+	})
+
+	fines, err := t.unpaidFines(ctx, member.ID)
+	if err != nil {
+		return nil, err
+	}
+	summary.UnpaidFines = lo.Map(fines, func(fine *repository.PopulatedFine, _ int) dto.Fine {
+		summary.UnpaidFinesTotal += fine.Amount
+		return *t.FineRepo.MapRepositoryToDTOModel(fine)
+	})
+
+	recent, err := t.TransactionRepo.ListPopulated(ctx, repository.TransactionRepositoryFilter{
+		MemberID: &member.ID,
+	}, repository.QueryOptions{Limit: MemberSummaryListLimit})
+	if err != nil {
+		return nil, err
+	}
+	summary.RecentActivity = lo.Map(recent.Items, func(txn *repository.PopulatedTransaction, _ int) dto.Transactions {
+		return *t.TransactionRepo.MapRepositoryToDTOModel(txn)
+	})
+
+	return summary, nil
+}
+
+// forgetMemberSummary drops the member's cached summary after their balances,
+// transactions or fines change.
+func (t *Transaction) forgetMemberSummary(ctx context.Context, memberID uuid.UUID) {
+	if err := t.RedisPkg.Delete(ctx, memberSummaryKey(memberID)); err != nil {
+		t.Logger.Error().Err(err).Str("member_id", memberID.String()).Msg("failed to clear member summary cache")
+	}
+}
+
+func memberSummaryKey(memberID uuid.UUID) string {
+	return MemberSummaryRedisKeyPrefix + memberID.String()
+}
@@ -4,13 +4,20 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
 	"github.com/Jidetireni/ara-cooperative/internal/dto"
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
 	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/events"
+	"github.com/Jidetireni/ara-cooperative/internal/services/members"
+	"github.com/Jidetireni/ara-cooperative/internal/services/notifications"
 	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/Jidetireni/ara-cooperative/internal/services/webhooks"
 	"github.com/Jidetireni/ara-cooperative/pkg/cache"
 	"github.com/Jidetireni/ara-cooperative/pkg/logger"
 	"github.com/google/uuid"
@@ -23,10 +30,17 @@ var (
 	_ MemberRepository      = (*repository.MemberRepository)(nil)
 	_ ShareRepository       = (*repository.ShareRepository)(nil)
 	_ FineRepository        = (*repository.FineRepository)(nil)
+	_ SettlementRepository  = (*repository.SettlementRepository)(nil)
+	_ BeneficiaryRepository = (*repository.MemberBeneficiaryRepository)(nil)
 )
 
 var (
-	_ RedisPkg = (*cache.Redis)(nil)
+	_ RedisPkg       = (*cache.Redis)(nil)
+	_ Notifier       = (*notifications.Notifications)(nil)
+	_ Webhooks       = (*webhooks.Webhooks)(nil)
+	_ EventPublisher = (*events.Dispatcher)(nil)
+	_ Members        = (*members.Member)(nil)
+	_ Sessions       = (*users.User)(nil)
 )
 
 type TransactionRepository interface {
@@ -35,6 +49,7 @@ type TransactionRepository interface {
 	GetStatus(ctx context.Context, filter repository.TransactionRepositoryFilter) (*repository.TransactionStatus, error)
 	UpdateStatus(ctx context.Context, transactionStatus repository.TransactionStatus, tx *sqlx.Tx) (*repository.TransactionStatus, error)
 	GetBalance(ctx context.Context, filter repository.TransactionRepositoryFilter) (int64, error)
+	LedgerTotals(ctx context.Context, memberID uuid.UUID) ([]repository.LedgerTotal, error)
 	ListPopulated(ctx context.Context, filter repository.TransactionRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.PopulatedTransaction], error)
 	GetPopulated(ctx context.Context, filter repository.TransactionRepositoryFilter, tx *sqlx.Tx) (*repository.PopulatedTransaction, error)
 	MapRepositoryToDTOModel(txn *repository.PopulatedTransaction) *dto.Transactions
@@ -42,7 +57,6 @@ type TransactionRepository interface {
 
 type MemberRepository interface {
 	Get(ctx context.Context, filter repository.MemberRepositoryFilter) (*repository.Member, error)
-	Update(ctx context.Context, member *repository.Member, tx *sqlx.Tx) (*repository.Member, error)
 }
 
 type ShareRepository interface {
@@ -62,30 +76,80 @@ type FineRepository interface {
 	ListPopulated(ctx context.Context, filter repository.FineRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.PopulatedFine], error)
 }
 
+type SettlementRepository interface {
+	Create(ctx context.Context, settlement *repository.MemberSettlement, tx *sqlx.Tx) (*repository.MemberSettlement, error)
+	GetByMember(ctx context.Context, memberID uuid.UUID) (*repository.MemberSettlement, error)
+	MapRepositoryToDTOModel(settlement *repository.MemberSettlement) *dto.MemberSettlement
+}
+
 type RedisPkg interface {
+	Set(ctx context.Context, key string, value any, expiration time.Duration) error
+	Get(ctx context.Context, key string, dest any) error
 	SetPrimitive(ctx context.Context, key string, value string, expiration time.Duration) error
 	GetPrimitive(ctx context.Context, key string) (string, error)
 	Delete(ctx context.Context, key string) error
 }
 
+type Notifier interface {
+	Notify(ctx context.Context, notification *notifications.Notification, tx *sqlx.Tx) error
+	Publish(ctx context.Context, notifications ...*notifications.Notification)
+	PublishTransaction(ctx context.Context, eventType string, txn *dto.Transactions)
+}
+
+type Webhooks interface {
+	Emit(ctx context.Context, eventType webhooks.EventType, data any, tx *sqlx.Tx) error
+}
+
+type EventPublisher interface {
+	Publish(ctx context.Context, event events.Event, tx *sqlx.Tx) error
+	Wake()
+}
+
+type Members interface {
+	Transition(ctx context.Context, member *repository.Member, status, reason string, changedBy uuid.UUID, tx *sqlx.Tx) (*repository.Member, error)
+}
+
+// BeneficiaryRepository lists who a member's settlement is allocated to.
+type BeneficiaryRepository interface {
+	List(ctx context.Context, memberID uuid.UUID, tx *sqlx.Tx) ([]repository.MemberBeneficiary, error)
+}
+
+type Sessions interface {
+	RevokeSessions(ctx context.Context, userID uuid.UUID) error
+}
+
 type Transaction struct {
 	DB              *sqlx.DB
 	TransactionRepo TransactionRepository
 	MemberRepo      MemberRepository
 	ShareRepo       ShareRepository
 	FineRepo        FineRepository
+	SettlementRepo  SettlementRepository
+	BeneficiaryRepo BeneficiaryRepository
 	RedisPkg        RedisPkg
+	Notifier        Notifier
+	Webhooks        Webhooks
+	Events          EventPublisher
+	Members         Members
+	Sessions        Sessions
 	Logger          *logger.Logger
 }
 
-func New(db *sqlx.DB, transRepo TransactionRepository, memberRepo MemberRepository, shareRepo ShareRepository, fineRepo FineRepository, redisPkg RedisPkg, logger *logger.Logger) *Transaction {
+func New(db *sqlx.DB, transRepo TransactionRepository, memberRepo MemberRepository, shareRepo ShareRepository, fineRepo FineRepository, settlementRepo SettlementRepository, beneficiaryRepo BeneficiaryRepository, redisPkg RedisPkg, notifier Notifier, webhooksSvc Webhooks, eventPublisher EventPublisher, membersSvc Members, sessions Sessions, logger *logger.Logger) *Transaction {
 	return &Transaction{
 		DB:              db,
 		TransactionRepo: transRepo,
 		MemberRepo:      memberRepo,
 		ShareRepo:       shareRepo,
 		FineRepo:        fineRepo,
+		SettlementRepo:  settlementRepo,
+		BeneficiaryRepo: beneficiaryRepo,
 		RedisPkg:        redisPkg,
+		Notifier:        notifier,
+		Webhooks:        webhooksSvc,
+		Events:          eventPublisher,
+		Members:         membersSvc,
+		Sessions:        sessions,
 		Logger:          logger,
 	}
 }
@@ -169,67 +233,59 @@ func (t *Transaction) UpdateStatus(ctx context.Context, id *uuid.UUID, input *dt
 		return nil, err
 	}
 
+	txn, err := t.TransactionRepo.GetPopulated(ctx, repository.TransactionRepositoryFilter{
+		ID: lo.ToPtr(updatedStatus.TransactionID),
+	}, tx)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &dto.TransactionStatusResult{
 		Confirmed: lo.ToPtr(updatedStatus.ConfirmedAt.Valid),
 	}
+
+	// What else changes with the status, such as activating a member or
+	// settling a fine, is up to the handlers of these events.
+	var event events.Event
+	var webhookEvent webhooks.EventType
 	if wantConfirmed {
 		result.Message = "transaction confirmed successfully"
-		switch ledger {
-		case repository.LedgerTypeREGISTRATIONFEE:
-			txn, err := t.TransactionRepo.GetPopulated(ctx, repository.TransactionRepositoryFilter{
-				ID: lo.ToPtr(updatedStatus.TransactionID),
-			}, tx)
-			if err != nil {
-				return nil, err
-			}
-			member, err := t.MemberRepo.Get(ctx, repository.MemberRepositoryFilter{
-				ID: lo.ToPtr(txn.MemberID),
-			})
-			if err != nil {
-				return nil, err
-			}
-			member.ActivatedAt = sql.NullTime{Time: time.Now(), Valid: true}
-			_, err = t.MemberRepo.Update(ctx, member, tx)
-			if err != nil {
-				return nil, err
-			}
-
-		case repository.LedgerTypeFINES:
-			txn, err := t.TransactionRepo.GetPopulated(ctx, repository.TransactionRepositoryFilter{
-				ID: lo.ToPtr(updatedStatus.TransactionID),
-			}, tx)
-			if err != nil {
-				return nil, err
-			}
-			fine, err := t.FineRepo.GetPopulated(ctx, repository.FineRepositoryFilter{
-				TransactionID: &txn.ID,
-			}, tx)
-			if err != nil {
-				return nil, err
-			}
-
-			_, err = t.FineRepo.Update(ctx, &repository.Fine{
-				ID:            fine.ID,
-				AdminID:       fine.AdminID,
-				MemberID:      fine.MemberID,
-				TransactionID: fine.TransactionID,
-				Amount:        fine.Amount,
-				Reason:        fine.Reason,
-				Deadline:      fine.Deadline,
-				PaidAt:        sql.NullTime{Time: time.Now(), Valid: true},
-			}, tx)
-			if err != nil {
-				return nil, err
-			}
+		event = events.TransactionConfirmed{
+			TransactionID: txn.ID,
+			MemberID:      txn.MemberID,
+			Ledger:        txn.Ledger,
+			Type:          txn.Type,
+			Amount:        txn.Amount,
 		}
+		webhookEvent = webhooks.EventTransactionConfirmed
 	} else {
 		result.Message = "transaction rejected successfully"
+		event = events.TransactionRejected{
+			TransactionID: txn.ID,
+			MemberID:      txn.MemberID,
+			Ledger:        txn.Ledger,
+			Type:          txn.Type,
+			Amount:        txn.Amount,
+		}
+		webhookEvent = webhooks.EventTransactionRejected
+	}
+
+	if err := t.Events.Publish(ctx, event, tx); err != nil {
+		return nil, err
+	}
+
+	if err := t.Webhooks.Emit(ctx, webhookEvent, t.TransactionRepo.MapRepositoryToDTOModel(txn), tx); err != nil {
+		return nil, err
 	}
 
 	if err := tx.Commit(); err != nil {
 		return nil, err
 	}
 
+	t.forgetMemberSummary(ctx, txn.MemberID)
+	t.Events.Wake()
+	t.Notifier.PublishTransaction(ctx, notifications.StreamEventTransactionUpdated, t.TransactionRepo.MapRepositoryToDTOModel(txn))
+
 	return result, nil
 }
 
@@ -267,6 +323,9 @@ func (t *Transaction) processDeposit(ctx context.Context, input dto.Transactions
 	if err != nil {
 		return nil, err
 	}
+	if err := memberCan(member, constants.MemberActionDeposit); err != nil {
+		return nil, err
+	}
 
 	tx, err := t.DB.BeginTxx(ctx, nil)
 	if err != nil {
@@ -287,7 +346,11 @@ func (t *Transaction) processDeposit(ctx context.Context, input dto.Transactions
 		return nil, err
 	}
 
-	return t.TransactionRepo.MapRepositoryToDTOModel(transaction), nil
+	t.forgetMemberSummary(ctx, member.ID)
+	result := t.TransactionRepo.MapRepositoryToDTOModel(transaction)
+	t.Notifier.PublishTransaction(ctx, notifications.StreamEventTransactionPending, result)
+
+	return result, nil
 }
 
 // Helper method to get member by user ID
@@ -297,6 +360,18 @@ func (t *Transaction) getMemberByUserID(ctx context.Context, userID uuid.UUID) (
 	})
 }
 
+// memberCan returns a 403 unless the member's lifecycle state allows action.
+func memberCan(member *repository.Member, action constants.MemberAction) error {
+	if constants.MemberCan(member.Status, action) {
+		return nil
+	}
+
+	return &svc.APIError{
+		Status:  http.StatusForbidden,
+		Message: fmt.Sprintf("%s members cannot %s", strings.ToLower(member.Status), strings.ReplaceAll(string(action), "_", " ")),
+	}
+}
+
 // Helper method to create transaction with status
 func (t *Transaction) createTransactionWithStatus(ctx context.Context, memberID uuid.UUID, params TransactionParams, tx *sqlx.Tx) (*repository.PopulatedTransaction, error) {
 	reference := lo.RandomString(12, lo.AlphanumericCharset)
@@ -326,7 +401,11 @@ func (t *Transaction) createTransactionWithStatus(ctx context.Context, memberID
 		return nil, err
 	}
 
-	// Notify admins of new transaction (non-blocking)
+	err = t.Webhooks.Emit(ctx, webhooks.EventTransactionCreated, t.TransactionRepo.MapRepositoryToDTOModel(populatedTxn), tx)
+	if err != nil {
+		return nil, err
+	}
+
 	return populatedTxn, nil
 }
 
@@ -349,8 +428,14 @@ func (t *Transaction) getBalance(ctx context.Context, ledger repository.LedgerTy
 		return 0, err
 	}
 
+	return t.memberBalance(ctx, member.ID, ledger)
+}
+
+// memberBalance is the member's confirmed deposits into ledger less their
+// confirmed withdrawals from it.
+func (t *Transaction) memberBalance(ctx context.Context, memberID uuid.UUID, ledger repository.LedgerType) (int64, error) {
 	totalDeposits, err := t.TransactionRepo.GetBalance(ctx, repository.TransactionRepositoryFilter{
-		MemberID:   &member.ID,
+		MemberID:   &memberID,
 		Type:       lo.ToPtr(repository.TransactionTypeDEPOSIT),
 		Confirmed:  lo.ToPtr(true),
 		LedgerType: lo.ToPtr(ledger),
@@ -360,7 +445,7 @@ func (t *Transaction) getBalance(ctx context.Context, ledger repository.LedgerTy
 	}
 
 	totalWithdrawals, err := t.TransactionRepo.GetBalance(ctx, repository.TransactionRepositoryFilter{
-		MemberID:   &member.ID,
+		MemberID:   &memberID,
 		Type:       lo.ToPtr(repository.TransactionTypeWITHDRAWAL),
 		Confirmed:  lo.ToPtr(true),
 		LedgerType: lo.ToPtr(ledger),
@@ -2,9 +2,12 @@ package users
 
 import (
 	"context"
+	"database/sql"
+	"errors"
 	"net/http"
 	"time"
 
+	"github.com/Jidetireni/ara-cooperative/internal/constants"
 	"github.com/Jidetireni/ara-cooperative/internal/dto"
 	"github.com/Jidetireni/ara-cooperative/internal/helpers"
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
@@ -13,7 +16,24 @@ import (
 	"github.com/samber/lo"
 )
 
-func (u *User) generateUserSession(ctx context.Context, user *repository.User, tx *sqlx.Tx) (*dto.AuthResponse, string, error) {
+// generateUserSession issues a token pair. mfa records whether the user proved
+// a second factor for this session. Members who have left the cooperative
+// cannot sign in.
+func (u *User) generateUserSession(ctx context.Context, user *repository.User, mfa bool, tx *sqlx.Tx) (*dto.AuthResponse, string, error) {
+	var memberStatus string
+	member, err := u.MemberRepo.Get(ctx, repository.MemberRepositoryFilter{
+		UserID: &user.ID,
+	})
+	switch {
+	case err == nil:
+		memberStatus = member.Status
+	case !errors.Is(err, sql.ErrNoRows):
+		return nil, "", err
+	}
+	if memberStatus == repository.MemberStatusExited {
+		return nil, "", MembershipEndedError()
+	}
+
 	roles, err := u.RoleRepo.List(ctx, &repository.RoleRepositoryFilter{
 		UserID: &user.ID,
 	})
@@ -30,11 +50,19 @@ func (u *User) generateUserSession(ctx context.Context, user *repository.User, t
 	}
 
 	permSlugs := lo.Map(permissions, func(p repository.Permission, _ int) string { return p.Slug })
+	version, err := u.RedisPkg.GetTokenVersion(ctx, user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
 	tokenPairs, err := u.TokenPkg.GenerateTokenPair(&token.TokenPairParams{
-		ID:          user.ID,
-		Email:       user.Email,
-		Roles:       roleNames,
-		Permissions: permSlugs,
+		ID:           user.ID,
+		Email:        user.Email,
+		Roles:        roleNames,
+		Permissions:  permSlugs,
+		Version:      version,
+		MFA:          mfa,
+		MemberStatus: memberStatus,
 	})
 	if err != nil {
 		return nil, "", err
@@ -58,7 +86,8 @@ func (u *User) generateUserSession(ctx context.Context, user *repository.User, t
 			ID:    user.ID,
 			Email: user.Email,
 		},
-		AccessToken: tokenPairs.AccessToken,
+		AccessToken:           tokenPairs.AccessToken,
+		MFAEnrollmentRequired: !mfa && constants.RequiresMFA(permSlugs),
 	}, tokenPairs.RefreshToken, nil
 }
 
@@ -2,9 +2,12 @@ package users
 
 import (
 	"context"
+	"net/http"
 	"slices"
+	"time"
 
 	"github.com/Jidetireni/ara-cooperative/internal/constants"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
 	"github.com/google/uuid"
 )
 
@@ -18,6 +21,16 @@ type UserContextValue struct {
 	Roles       []string
 	Permissions []string
 
+	// TokenID and TokenExpiresAt describe the access token the request was
+	// authenticated with, so it can be revoked on logout.
+	TokenID        string
+	TokenExpiresAt time.Time
+	// MFA is set when the session was opened with a second factor.
+	MFA bool
+	// MemberStatus is the lifecycle state of the user's membership, empty for
+	// users who are not members.
+	MemberStatus string
+
 	IsAuthenticatedAsMember bool
 	IsAuthenticatedAsAdmin  bool
 }
@@ -31,6 +44,14 @@ func FromContext(ctx context.Context) (*UserContextValue, bool) {
 	return u, ok
 }
 
+// MembershipEndedError is returned to members who have exited the cooperative.
+func MembershipEndedError() *svc.APIError {
+	return &svc.APIError{
+		Status:  http.StatusForbidden,
+		Message: "your membership has ended",
+	}
+}
+
 func HasAdminPermissions(ctx context.Context, requiredPermissions []constants.UserPermissions) bool {
 	user, ok := FromContext(ctx)
 	if !ok {
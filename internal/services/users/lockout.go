@@ -0,0 +1,195 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/mailer"
+	"github.com/Jidetireni/ara-cooperative/pkg/cache"
+	"github.com/Jidetireni/ara-cooperative/pkg/email"
+	"github.com/Jidetireni/ara-cooperative/pkg/token"
+	"github.com/google/uuid"
+)
+
+const (
+	loginFailureWindow = time.Minute * 15
+	// loginFreeAttempts failures are allowed before each further failure
+	// delays the next attempt, doubling from one second.
+	loginFreeAttempts     = 3
+	loginMaxDelay         = time.Minute * 2
+	loginLockoutThreshold = 10
+	loginLockoutDuration  = time.Minute * 30
+
+	// loginIPFailureLimit is deliberately high: many members may sit behind
+	// the same NAT. It stops credential stuffing across many emails.
+	loginIPFailureLimit = 100
+
+	// setPasswordMaxAttempts bounds guessing of the six digit set-password
+	// code. Once reached the code is invalidated and a new one has to be sent.
+	setPasswordMaxAttempts = 5
+	setPasswordLockout     = time.Minute * 15
+)
+
+var errInvalidCredentials = &svc.APIError{
+	Status:  http.StatusUnauthorized,
+	Message: "invalid email or password",
+}
+
+func normalizeEmail(e string) string {
+	return strings.ToLower(strings.TrimSpace(e))
+}
+
+// checkLoginLocks rejects the attempt while the email or the client IP is locked.
+func (u *User) checkLoginLocks(ctx context.Context, emailAddr, ip string) error {
+	lockedFor, err := u.RedisPkg.LockedFor(ctx, cache.LockScopeLoginEmail, emailAddr)
+	if err != nil {
+		return err
+	}
+	if lockedFor > 0 {
+		return svc.TooManyRequestsError("too many failed login attempts, please try again later", lockedFor)
+	}
+
+	if ip == "" {
+		return nil
+	}
+
+	lockedFor, err = u.RedisPkg.LockedFor(ctx, cache.LockScopeLoginIP, ip)
+	if err != nil {
+		return err
+	}
+	if lockedFor > 0 {
+		return svc.TooManyRequestsError("too many failed login attempts, please try again later", lockedFor)
+	}
+
+	return nil
+}
+
+// loginFailed records a failed login and returns the error to send back.
+// user is nil when the email is unknown; the response is the same either way.
+func (u *User) loginFailed(ctx context.Context, emailAddr, ip string, user *repository.User) error {
+	if ip != "" {
+		ipFailures, err := u.RedisPkg.RecordFailedAttempt(ctx, cache.LockScopeLoginIP, ip, loginFailureWindow)
+		if err != nil {
+			return err
+		}
+		if ipFailures >= loginIPFailureLimit {
+			if err := u.RedisPkg.Lock(ctx, cache.LockScopeLoginIP, ip, loginFailureWindow); err != nil {
+				return err
+			}
+		}
+	}
+
+	failures, err := u.RedisPkg.RecordFailedAttempt(ctx, cache.LockScopeLoginEmail, emailAddr, loginFailureWindow)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case failures >= loginLockoutThreshold:
+		if err := u.RedisPkg.Lock(ctx, cache.LockScopeLoginEmail, emailAddr, loginLockoutDuration); err != nil {
+			return err
+		}
+		if failures == loginLockoutThreshold && user != nil {
+			u.sendLockoutNotice(ctx, user)
+		}
+		return svc.TooManyRequestsError("too many failed login attempts, please try again later", loginLockoutDuration)
+
+	case failures > loginFreeAttempts:
+		delay := time.Second << (failures - loginFreeAttempts - 1)
+		if delay > loginMaxDelay {
+			delay = loginMaxDelay
+		}
+		if err := u.RedisPkg.Lock(ctx, cache.LockScopeLoginEmail, emailAddr, delay); err != nil {
+			return err
+		}
+		return &svc.APIError{
+			Status:     errInvalidCredentials.Status,
+			Message:    errInvalidCredentials.Message,
+			RetryAfter: delay,
+		}
+	}
+
+	return errInvalidCredentials
+}
+
+func (u *User) sendLockoutNotice(ctx context.Context, user *repository.User) {
+	err := u.Mailer.Enqueue(ctx, &mailer.Message{
+		To:       user.Email,
+		Template: email.EmailTemplateTypeAccountLocked,
+		Data: email.AccountLockedData{
+			LockMinutes:    int(loginLockoutDuration.Minutes()),
+			FailedAttempts: loginLockoutThreshold,
+		},
+	}, nil)
+	if err != nil {
+		u.Logger.Error().Err(err).Str("user_id", user.ID.String()).Msg("failed to queue lockout notice")
+	}
+}
+
+// checkSetPasswordLock rejects set-password attempts while the email is locked.
+func (u *User) checkSetPasswordLock(ctx context.Context, emailAddr string) error {
+	lockedFor, err := u.RedisPkg.LockedFor(ctx, cache.LockScopeSetPassword, emailAddr)
+	if err != nil {
+		return err
+	}
+	if lockedFor > 0 {
+		return svc.TooManyRequestsError("too many invalid codes, please request a new code later", lockedFor)
+	}
+
+	return nil
+}
+
+// setPasswordFailed records a wrong set-password code. After
+// setPasswordMaxAttempts the outstanding code is burnt.
+func (u *User) setPasswordFailed(ctx context.Context, emailAddr string, user *repository.User) error {
+	invalid := &svc.APIError{
+		Status:  http.StatusBadRequest,
+		Message: "Invalid or expired token",
+	}
+
+	failures, err := u.RedisPkg.RecordFailedAttempt(ctx, cache.LockScopeSetPassword, emailAddr, token.SetPasswordTokenExpirationTime)
+	if err != nil {
+		return err
+	}
+	if failures < setPasswordMaxAttempts {
+		return invalid
+	}
+
+	if user != nil {
+		if err := u.TokenRepo.Invalidate(ctx, user.ID, token.SetPasswordToken, nil); err != nil {
+			return err
+		}
+	}
+
+	if err := u.RedisPkg.Lock(ctx, cache.LockScopeSetPassword, emailAddr, setPasswordLockout); err != nil {
+		return err
+	}
+
+	return svc.TooManyRequestsError("too many invalid codes, please request a new code later", setPasswordLockout)
+}
+
+// UnlockUser lifts login and set-password lockouts of a user.
+func (u *User) UnlockUser(ctx context.Context, userID uuid.UUID) error {
+	user, err := u.UserRepo.Get(ctx, repository.UserRepositoryFilter{
+		ID: &userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return svc.ErrNotFound()
+		}
+		return err
+	}
+
+	emailAddr := normalizeEmail(user.Email)
+	if err := u.RedisPkg.Unlock(ctx, cache.LockScopeLoginEmail, emailAddr); err != nil {
+		return err
+	}
+
+	return u.RedisPkg.Unlock(ctx, cache.LockScopeSetPassword, emailAddr)
+}
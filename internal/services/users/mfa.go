@@ -0,0 +1,330 @@
+package users
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/base32"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/helpers"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/pkg/token"
+	"github.com/Jidetireni/ara-cooperative/pkg/totp"
+	"github.com/google/uuid"
+	"github.com/samber/lo"
+)
+
+const (
+	mfaIssuer = "ARA Cooperative"
+
+	mfaRecoveryCodeCount = 10
+
+	mfaChallengeAttemptsKeyPrefix = "mfa_challenge_attempts:"
+	mfaChallengeMaxAttempts       = 5
+
+	mfaVerifyAttemptsKeyPrefix = "mfa_verify_attempts:"
+	mfaVerifyMaxAttempts       = 10
+	mfaVerifyAttemptsWindow    = time.Minute * 15
+)
+
+// EnrollMFA starts TOTP enrollment for the current user. The secret only takes
+// effect once a code generated from it is confirmed with VerifyMFA.
+func (u *User) EnrollMFA(ctx context.Context) (*dto.MFAEnrollResponse, error) {
+	current, ok := FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	enabled, err := u.MFARepo.IsEnabled(ctx, current.ID)
+	if err != nil {
+		return nil, err
+	}
+	if enabled {
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "two-factor authentication is already enabled",
+		}
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := helpers.Encrypt(u.mfaKey(), secret)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := u.MFARepo.UpsertPending(ctx, current.ID, encrypted, nil); err != nil {
+		return nil, err
+	}
+
+	return &dto.MFAEnrollResponse{
+		Secret:          secret,
+		ProvisioningURI: totp.ProvisioningURI(mfaIssuer, current.Email, secret),
+	}, nil
+}
+
+// VerifyMFA confirms enrollment with a first TOTP code. Every existing session
+// is revoked and replaced by a session that carries the mfa claim. The
+// recovery codes are returned once and only their hashes are stored.
+func (u *User) VerifyMFA(ctx context.Context, input *dto.MFAVerifyInput) (*dto.MFAVerifyResponse, string, error) {
+	current, ok := FromContext(ctx)
+	if !ok {
+		return nil, "", svc.UnauthenticatedError()
+	}
+
+	attempts, err := u.RedisPkg.Increment(ctx, mfaVerifyAttemptsKeyPrefix+current.ID.String(), mfaVerifyAttemptsWindow)
+	if err != nil {
+		return nil, "", err
+	}
+	if attempts > mfaVerifyMaxAttempts {
+		return nil, "", &svc.APIError{
+			Status:  http.StatusTooManyRequests,
+			Message: "too many attempts, please try again later",
+		}
+	}
+
+	mfa, err := u.MFARepo.Get(ctx, current.ID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, "", &svc.APIError{
+				Status:  http.StatusBadRequest,
+				Message: "two-factor enrollment has not been started",
+			}
+		}
+		return nil, "", err
+	}
+	if mfa.EnabledAt.Valid {
+		return nil, "", &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "two-factor authentication is already enabled",
+		}
+	}
+
+	secret, err := helpers.Decrypt(u.mfaKey(), mfa.SecretEncrypted)
+	if err != nil {
+		return nil, "", err
+	}
+
+	step, valid := totp.Validate(secret, input.Code, time.Now())
+	if !valid {
+		return nil, "", &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "invalid code",
+		}
+	}
+
+	recoveryCodes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, "", err
+	}
+
+	user, err := u.UserRepo.Get(ctx, repository.UserRepositoryFilter{
+		ID: &current.ID,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	tx, err := u.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	if err := u.MFARepo.Enable(ctx, current.ID, step, tx); err != nil {
+		return nil, "", err
+	}
+
+	if err := u.MFARepo.ReplaceRecoveryCodes(ctx, current.ID, hashes, tx); err != nil {
+		return nil, "", err
+	}
+
+	// Sessions opened with the password alone must not outlive enrollment.
+	if _, err := u.RedisPkg.BumpTokenVersion(ctx, current.ID); err != nil {
+		return nil, "", err
+	}
+
+	authResponse, refreshToken, err := u.generateUserSession(ctx, user, true, tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, "", err
+	}
+
+	return &dto.MFAVerifyResponse{
+		AuthResponse:  authResponse,
+		RecoveryCodes: recoveryCodes,
+	}, refreshToken, nil
+}
+
+// LoginMFA completes a login that Login answered with an MFA challenge.
+func (u *User) LoginMFA(ctx context.Context, input *dto.LoginMFAInput) (*dto.AuthResponse, string, error) {
+	invalidChallenge := &svc.APIError{
+		Status:  http.StatusUnauthorized,
+		Message: "invalid or expired MFA challenge, please log in again",
+	}
+
+	challenge, err := u.TokenRepo.Get(ctx, &repository.TokenRepositoryFilter{
+		Token:     lo.ToPtr(helpers.HashToken(input.MFAToken)),
+		TokenType: lo.ToPtr(token.MFAChallengeToken),
+		IsValid:   lo.ToPtr(true),
+	})
+	if err != nil || challenge.ExpiresAt.Before(time.Now()) {
+		return nil, "", invalidChallenge
+	}
+
+	attempts, err := u.RedisPkg.Increment(ctx, mfaChallengeAttemptsKeyPrefix+challenge.ID.String(), token.MFAChallengeTokenExpirationTime)
+	if err != nil {
+		return nil, "", err
+	}
+	if attempts > mfaChallengeMaxAttempts {
+		if err := u.invalidateMFAChallenge(ctx, challenge); err != nil {
+			return nil, "", err
+		}
+		return nil, "", invalidChallenge
+	}
+
+	user, err := u.UserRepo.Get(ctx, repository.UserRepositoryFilter{
+		ID: &challenge.UserID,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+
+	valid, err := u.verifySecondFactor(ctx, user.ID, input.Code)
+	if err != nil {
+		return nil, "", err
+	}
+	if !valid {
+		return nil, "", &svc.APIError{
+			Status:  http.StatusUnauthorized,
+			Message: "invalid code",
+		}
+	}
+
+	tx, err := u.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	defer tx.Rollback()
+
+	err = u.TokenRepo.Update(ctx, &repository.Token{
+		ID:        challenge.ID,
+		UserID:    challenge.UserID,
+		TokenType: token.MFAChallengeToken,
+		IsValid:   false,
+	}, tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	authResponse, refreshToken, err := u.generateUserSession(ctx, user, true, tx)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, "", err
+	}
+
+	return authResponse, refreshToken, nil
+}
+
+// createMFAChallenge issues the short-lived, single-use token that lets a
+// user who passed the password check submit their second factor.
+func (u *User) createMFAChallenge(ctx context.Context, userID uuid.UUID) (string, error) {
+	rawToken, err := helpers.GenerateSecureToken()
+	if err != nil {
+		return "", err
+	}
+
+	_, err = u.TokenRepo.Create(ctx, &repository.Token{
+		UserID:    userID,
+		Token:     helpers.HashToken(rawToken),
+		TokenType: token.MFAChallengeToken,
+		IsValid:   true,
+		ExpiresAt: time.Now().Add(token.MFAChallengeTokenExpirationTime),
+	}, nil)
+	if err != nil {
+		return "", err
+	}
+
+	return rawToken, nil
+}
+
+func (u *User) invalidateMFAChallenge(ctx context.Context, challenge *repository.Token) error {
+	return u.TokenRepo.Update(ctx, &repository.Token{
+		ID:        challenge.ID,
+		UserID:    challenge.UserID,
+		TokenType: token.MFAChallengeToken,
+		IsValid:   false,
+	}, nil)
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery
+// code. Both are consumed so they cannot be replayed.
+func (u *User) verifySecondFactor(ctx context.Context, userID uuid.UUID, code string) (bool, error) {
+	code = strings.TrimSpace(code)
+	if len(code) == totp.Digits {
+		mfa, err := u.MFARepo.Get(ctx, userID)
+		if err != nil {
+			return false, err
+		}
+
+		secret, err := helpers.Decrypt(u.mfaKey(), mfa.SecretEncrypted)
+		if err != nil {
+			return false, err
+		}
+
+		step, valid := totp.Validate(secret, code, time.Now())
+		if !valid || step <= mfa.LastUsedStep {
+			return false, nil
+		}
+
+		return u.MFARepo.ConsumeStep(ctx, userID, step, nil)
+	}
+
+	return u.MFARepo.UseRecoveryCode(ctx, userID, helpers.HashToken(normalizeRecoveryCode(code)), nil)
+}
+
+func (u *User) mfaKey() []byte {
+	return helpers.DeriveKey(u.Config.Auth.MFAEncryptionKey)
+}
+
+// generateRecoveryCodes returns codes formatted as xxxxx-xxxxx together with
+// the hashes to store.
+func generateRecoveryCodes() ([]string, []string, error) {
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	codes := make([]string, mfaRecoveryCodeCount)
+	hashes := make([]string, mfaRecoveryCodeCount)
+
+	for i := range codes {
+		b := make([]byte, 7)
+		if _, err := rand.Read(b); err != nil {
+			return nil, nil, err
+		}
+
+		raw := strings.ToLower(enc.EncodeToString(b))[:10]
+		codes[i] = raw[:5] + "-" + raw[5:]
+		hashes[i] = helpers.HashToken(raw)
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	code = strings.ToLower(code)
+	code = strings.ReplaceAll(code, "-", "")
+	return strings.ReplaceAll(code, " ", "")
+}
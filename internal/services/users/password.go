@@ -0,0 +1,184 @@
+package users
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/helpers"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/mailer"
+	"github.com/Jidetireni/ara-cooperative/pkg/email"
+	"github.com/Jidetireni/ara-cooperative/pkg/token"
+	"github.com/samber/lo"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	forgotPasswordRateKeyPrefix = "forgot_password:"
+	forgotPasswordRateLimit     = 3
+	forgotPasswordRateWindow    = time.Hour
+)
+
+// ForgotPassword issues a single-use password reset link. It behaves the same
+// whether or not the email belongs to an account, so it cannot be used to
+// discover registered emails.
+func (u *User) ForgotPassword(ctx context.Context, input *dto.ForgotPasswordInput) error {
+	normalizedEmail := normalizeEmail(input.Email)
+
+	attempts, err := u.RedisPkg.Increment(ctx, forgotPasswordRateKeyPrefix+normalizedEmail, forgotPasswordRateWindow)
+	if err != nil {
+		return err
+	}
+	if attempts > forgotPasswordRateLimit {
+		return &svc.APIError{
+			Status:  http.StatusTooManyRequests,
+			Message: "too many password reset requests, please try again later",
+		}
+	}
+
+	// Look the address up the way it was rate limited, so a differently
+	// cased address neither dodges the limit nor misses the account.
+	user, err := u.UserRepo.Get(ctx, repository.UserRepositoryFilter{
+		Emails: []string{normalizedEmail},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil
+		}
+		return err
+	}
+
+	rawToken, err := helpers.GenerateSecureToken()
+	if err != nil {
+		return err
+	}
+
+	resetURL := fmt.Sprintf("%s/reset-password?email=%s&token=%s",
+		u.Config.Server.FEURL,
+		url.QueryEscape(user.Email),
+		url.QueryEscape(rawToken),
+	)
+
+	tx, err := u.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	_, err = u.TokenRepo.Create(ctx, &repository.Token{
+		UserID:    user.ID,
+		Token:     helpers.HashToken(rawToken),
+		TokenType: token.PasswordResetToken,
+		IsValid:   true,
+		ExpiresAt: time.Now().Add(token.PasswordResetTokenExpirationTime),
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	err = u.Mailer.Enqueue(ctx, &mailer.Message{
+		To:       user.Email,
+		Template: email.EmailTemplateTypePasswordReset,
+		Data: email.PasswordResetData{
+			ResetURL:         resetURL,
+			ExpiresInMinutes: int(token.PasswordResetTokenExpirationTime.Minutes()),
+		},
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// ResetPassword consumes a password reset token, sets the new password and
+// signs the user out everywhere.
+func (u *User) ResetPassword(ctx context.Context, input *dto.ResetPasswordInput) error {
+	invalidTokenErr := &svc.APIError{
+		Status:  http.StatusBadRequest,
+		Message: "Invalid or expired token",
+	}
+
+	// Look the address up as ForgotPassword did when it issued the token.
+	user, err := u.UserRepo.Get(ctx, repository.UserRepositoryFilter{
+		Emails: []string{normalizeEmail(input.Email)},
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return invalidTokenErr
+		}
+		return err
+	}
+
+	incomingTokenHash := helpers.HashToken(input.Token)
+	storedToken, err := u.TokenRepo.Get(ctx, &repository.TokenRepositoryFilter{
+		UserID:    &user.ID,
+		Token:     &incomingTokenHash,
+		TokenType: lo.ToPtr(token.PasswordResetToken),
+		IsValid:   lo.ToPtr(true),
+	})
+	if err != nil || storedToken.ExpiresAt.Before(time.Now()) {
+		return invalidTokenErr
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
+	if err != nil {
+		return err
+	}
+
+	tx, err := u.DB.BeginTxx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	err = u.TokenRepo.Update(ctx, &repository.Token{
+		ID:        storedToken.ID,
+		UserID:    user.ID,
+		TokenType: token.PasswordResetToken,
+		IsValid:   false,
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	// Following the link proves ownership of the email address.
+	emailConfirmedAt := user.EmailConfirmedAt
+	if !emailConfirmedAt.Valid {
+		emailConfirmedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	_, err = u.UserRepo.Upsert(ctx, &repository.User{
+		ID:    user.ID,
+		Email: user.Email,
+		PasswordHash: sql.NullString{
+			String: string(hashedPassword),
+			Valid:  true,
+		},
+		EmailConfirmedAt: emailConfirmedAt,
+	}, tx)
+	if err != nil {
+		return err
+	}
+
+	if err := u.invalidateRefreshToken(ctx, user.ID, tx); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	if _, err := u.RedisPkg.BumpTokenVersion(ctx, user.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -12,7 +12,11 @@ import (
 	"github.com/Jidetireni/ara-cooperative/internal/helpers"
 	"github.com/Jidetireni/ara-cooperative/internal/repository"
 	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/mailer"
+	"github.com/Jidetireni/ara-cooperative/pkg/cache"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
 	"github.com/Jidetireni/ara-cooperative/pkg/token"
+	"github.com/google/uuid"
 	"github.com/jmoiron/sqlx"
 	"github.com/samber/lo"
 	"golang.org/x/crypto/bcrypt"
@@ -23,10 +27,14 @@ var (
 	_ RoleRepository       = (*repository.RoleRepository)(nil)
 	_ PermissionRepository = (*repository.PermissionRepository)(nil)
 	_ TokenRepository      = (*repository.TokenRepository)(nil)
+	_ MemberRepository     = (*repository.MemberRepository)(nil)
+	_ MFARepository        = (*repository.MFARepository)(nil)
 )
 
 var (
 	_ TokenPkg = (*token.Jwt)(nil)
+	_ RedisPkg = (*cache.Redis)(nil)
+	_ Mailer   = (*mailer.Mailer)(nil)
 )
 
 type UserRepository interface {
@@ -45,15 +53,49 @@ type PermissionRepository interface {
 type TokenRepository interface {
 	Create(ctx context.Context, token *repository.Token, tx *sqlx.Tx) (*repository.Token, error)
 	Update(ctx context.Context, token *repository.Token, tx *sqlx.Tx) error
+	Invalidate(ctx context.Context, userID uuid.UUID, tokenType string, tx *sqlx.Tx) error
 	Get(ctx context.Context, filter *repository.TokenRepositoryFilter) (*repository.Token, error)
 	Validate(ctx context.Context, filter *repository.TokenRepositoryFilter) (bool, error)
 }
 
+type MemberRepository interface {
+	Get(ctx context.Context, filter repository.MemberRepositoryFilter) (*repository.Member, error)
+	MarkInvitationAccepted(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) error
+}
+
+type MFARepository interface {
+	Get(ctx context.Context, userID uuid.UUID) (*repository.UserMfa, error)
+	IsEnabled(ctx context.Context, userID uuid.UUID) (bool, error)
+	UpsertPending(ctx context.Context, userID uuid.UUID, secretEncrypted string, tx *sqlx.Tx) (*repository.UserMfa, error)
+	Enable(ctx context.Context, userID uuid.UUID, step int64, tx *sqlx.Tx) error
+	ConsumeStep(ctx context.Context, userID uuid.UUID, step int64, tx *sqlx.Tx) (bool, error)
+	ReplaceRecoveryCodes(ctx context.Context, userID uuid.UUID, codeHashes []string, tx *sqlx.Tx) error
+	UseRecoveryCode(ctx context.Context, userID uuid.UUID, codeHash string, tx *sqlx.Tx) (bool, error)
+}
+
 type TokenPkg interface {
 	GenerateTokenPair(params *token.TokenPairParams) (*token.TokenPair, error)
 	ValidateToken(tokenString string) (*token.UserClaims, error)
 }
 
+// RedisPkg holds the session state that has to be shared between API replicas:
+// the access-token denylist, the per-user token version and failed-attempt
+// counters.
+type RedisPkg interface {
+	DenyToken(ctx context.Context, tokenID string, expiration time.Duration) error
+	GetTokenVersion(ctx context.Context, userID uuid.UUID) (int64, error)
+	BumpTokenVersion(ctx context.Context, userID uuid.UUID) (int64, error)
+	Increment(ctx context.Context, key string, window time.Duration) (int64, error)
+	RecordFailedAttempt(ctx context.Context, scope cache.LockScope, subject string, window time.Duration) (int64, error)
+	Lock(ctx context.Context, scope cache.LockScope, subject string, duration time.Duration) error
+	LockedFor(ctx context.Context, scope cache.LockScope, subject string) (time.Duration, error)
+	Unlock(ctx context.Context, scope cache.LockScope, subject string) error
+}
+
+type Mailer interface {
+	Enqueue(ctx context.Context, msg *mailer.Message, tx *sqlx.Tx) error
+}
+
 type User struct {
 	DB             *sqlx.DB
 	Config         *config.Config
@@ -62,9 +104,14 @@ type User struct {
 	RoleRepo       RoleRepository
 	PermissionRepo PermissionRepository
 	TokenRepo      TokenRepository
+	MemberRepo     MemberRepository
+	MFARepo        MFARepository
+	RedisPkg       RedisPkg
+	Mailer         Mailer
+	Logger         *logger.Logger
 }
 
-func New(db *sqlx.DB, cfg *config.Config, tokenPkg TokenPkg, userRepo UserRepository, roleRepo RoleRepository, permissionRepo PermissionRepository, tokenRepo TokenRepository) *User {
+func New(db *sqlx.DB, cfg *config.Config, tokenPkg TokenPkg, userRepo UserRepository, roleRepo RoleRepository, permissionRepo PermissionRepository, tokenRepo TokenRepository, memberRepo MemberRepository, mfaRepo MFARepository, redisPkg RedisPkg, mailerSvc Mailer, logger *logger.Logger) *User {
 	return &User{
 		DB:             db,
 		Config:         cfg,
@@ -73,10 +120,20 @@ func New(db *sqlx.DB, cfg *config.Config, tokenPkg TokenPkg, userRepo UserReposi
 		RoleRepo:       roleRepo,
 		PermissionRepo: permissionRepo,
 		TokenRepo:      tokenRepo,
+		MemberRepo:     memberRepo,
+		MFARepo:        mfaRepo,
+		RedisPkg:       redisPkg,
+		Mailer:         mailerSvc,
+		Logger:         logger,
 	}
 }
 
 func (u *User) SetPassword(ctx context.Context, input *dto.SetPasswordInput) (*dto.AuthResponse, string, error) {
+	emailAddr := normalizeEmail(input.Email)
+	if err := u.checkSetPasswordLock(ctx, emailAddr); err != nil {
+		return nil, "", err
+	}
+
 	incomingTokenHash := helpers.HashToken(input.Token)
 	tx, err := u.DB.BeginTxx(ctx, nil)
 	if err != nil {
@@ -84,15 +141,14 @@ func (u *User) SetPassword(ctx context.Context, input *dto.SetPasswordInput) (*d
 	}
 	defer tx.Rollback()
 
+	// Look the account up by the address the lockout counts, so the two
+	// always agree on who is signing in.
 	user, err := u.UserRepo.Get(ctx, repository.UserRepositoryFilter{
-		Email: &input.Email,
+		Emails: []string{emailAddr},
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, "", &svc.APIError{
-				Status:  http.StatusBadRequest,
-				Message: "Invalid or expired token",
-			}
+			return nil, "", u.setPasswordFailed(ctx, emailAddr, nil)
 		}
 		return nil, "", err
 	}
@@ -104,10 +160,7 @@ func (u *User) SetPassword(ctx context.Context, input *dto.SetPasswordInput) (*d
 		IsValid:   lo.ToPtr(true),
 	})
 	if err != nil || storedToken.ExpiresAt.Before(time.Now()) {
-		return nil, "", &svc.APIError{
-			Status:  http.StatusBadRequest,
-			Message: "Invalid or expired token",
-		}
+		return nil, "", u.setPasswordFailed(ctx, emailAddr, user)
 	}
 
 	err = u.TokenRepo.Update(ctx, &repository.Token{
@@ -141,7 +194,11 @@ func (u *User) SetPassword(ctx context.Context, input *dto.SetPasswordInput) (*d
 		return nil, "", err
 	}
 
-	dtoUser, refreshToken, err := u.generateUserSession(ctx, upsertUser, tx)
+	if err := u.MemberRepo.MarkInvitationAccepted(ctx, user.ID, tx); err != nil {
+		return nil, "", err
+	}
+
+	dtoUser, refreshToken, err := u.generateUserSession(ctx, upsertUser, false, tx)
 	if err != nil {
 		return nil, "", err
 	}
@@ -150,29 +207,53 @@ func (u *User) SetPassword(ctx context.Context, input *dto.SetPasswordInput) (*d
 		return nil, "", err
 	}
 
+	if err := u.RedisPkg.Unlock(ctx, cache.LockScopeSetPassword, emailAddr); err != nil {
+		u.Logger.Error().Err(err).Msg("failed to reset set-password attempts")
+	}
+
 	return dtoUser, refreshToken, nil
 }
 
 // Login handles user authentication and token generation.
+// Failed attempts are throttled per email and per client IP, see lockout.go.
 func (u *User) Login(ctx context.Context, input *dto.LoginInput) (*dto.AuthResponse, string, error) {
+	emailAddr := normalizeEmail(input.Email)
+	if err := u.checkLoginLocks(ctx, emailAddr, input.IP); err != nil {
+		return nil, "", err
+	}
+
 	user, err := u.UserRepo.Get(ctx, repository.UserRepositoryFilter{
-		Email: &input.Email,
+		Emails: []string{emailAddr},
 	})
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return nil, "", &svc.APIError{
-				Status:  http.StatusUnauthorized,
-				Message: "invalid email or password",
-			}
+			return nil, "", u.loginFailed(ctx, emailAddr, input.IP, nil)
 		}
 		return nil, "", err
 	}
 
 	if err := bcrypt.CompareHashAndPassword([]byte(user.PasswordHash.String), []byte(input.Password)); err != nil {
-		return nil, "", &svc.APIError{
-			Status:  http.StatusUnauthorized,
-			Message: "invalid email or password",
+		return nil, "", u.loginFailed(ctx, emailAddr, input.IP, user)
+	}
+
+	if err := u.RedisPkg.Unlock(ctx, cache.LockScopeLoginEmail, emailAddr); err != nil {
+		return nil, "", err
+	}
+
+	mfaEnabled, err := u.MFARepo.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	if mfaEnabled {
+		challenge, err := u.createMFAChallenge(ctx, user.ID)
+		if err != nil {
+			return nil, "", err
 		}
+
+		return &dto.AuthResponse{
+			MFARequired: true,
+			MFAToken:    challenge,
+		}, "", nil
 	}
 
 	tx, err := u.DB.BeginTxx(ctx, nil)
@@ -181,7 +262,7 @@ func (u *User) Login(ctx context.Context, input *dto.LoginInput) (*dto.AuthRespo
 	}
 	defer tx.Rollback()
 
-	dtoUser, refreshToken, err := u.generateUserSession(ctx, user, tx)
+	dtoUser, refreshToken, err := u.generateUserSession(ctx, user, false, tx)
 	if err != nil {
 		return nil, "", err
 	}
@@ -230,7 +311,14 @@ func (u *User) RefreshToken(ctx context.Context, rawRefreshToken string) (*dto.A
 		return nil, "", err
 	}
 
-	authResponse, newRefreshToken, err := u.generateUserSession(ctx, user, tx)
+	// Refresh tokens are only handed out after the second factor once MFA is
+	// enabled, and enabling it revokes every earlier session.
+	mfaEnabled, err := u.MFARepo.IsEnabled(ctx, user.ID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	authResponse, newRefreshToken, err := u.generateUserSession(ctx, user, mfaEnabled, tx)
 	if err != nil {
 		return nil, "", err
 	}
@@ -241,3 +329,45 @@ func (u *User) RefreshToken(ctx context.Context, rawRefreshToken string) (*dto.A
 
 	return authResponse, newRefreshToken, nil
 }
+
+// Logout revokes the access token used for the current request and invalidates
+// the user's refresh token.
+func (u *User) Logout(ctx context.Context) error {
+	actor, ok := FromContext(ctx)
+	if !ok {
+		return svc.UnauthenticatedError()
+	}
+
+	if actor.TokenID != "" {
+		if err := u.RedisPkg.DenyToken(ctx, actor.TokenID, time.Until(actor.TokenExpiresAt)); err != nil {
+			return err
+		}
+	}
+
+	return u.invalidateRefreshToken(ctx, actor.ID, nil)
+}
+
+// RevokeSessions immediately invalidates every access and refresh token issued
+// to the user. It must be called whenever a user's roles, permissions or
+// standing change so that stale claims stop being accepted.
+func (u *User) RevokeSessions(ctx context.Context, userID uuid.UUID) error {
+	_, err := u.UserRepo.Get(ctx, repository.UserRepositoryFilter{
+		ID: &userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return svc.ErrNotFound()
+		}
+		return err
+	}
+
+	if _, err := u.RedisPkg.BumpTokenVersion(ctx, userID); err != nil {
+		return err
+	}
+
+	return u.invalidateRefreshToken(ctx, userID, nil)
+}
+
+func (u *User) invalidateRefreshToken(ctx context.Context, userID uuid.UUID, tx *sqlx.Tx) error {
+	return u.TokenRepo.Invalidate(ctx, userID, token.RefreshTokenName, tx)
+}
@@ -0,0 +1,202 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/helpers"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+)
+
+const (
+	PollInterval = time.Second * 5
+
+	// Headers sent with every delivery.
+	HeaderEvent     = "X-Ara-Event"
+	HeaderEventID   = "X-Ara-Event-Id"
+	HeaderDelivery  = "X-Ara-Delivery"
+	HeaderTimestamp = "X-Ara-Timestamp"
+	HeaderSignature = "X-Ara-Signature"
+
+	batchSize      = 20
+	requestTimeout = time.Second * 10
+	// deliveryLease must comfortably exceed batchSize * requestTimeout.
+	deliveryLease = time.Minute * 5
+
+	// With these values a receiver that is down for about a day still gets
+	// every event.
+	defaultMaxAttempts = 10
+	baseBackoff        = time.Minute
+	maxBackoff         = time.Hour * 6
+
+	// maxErrorBody is how much of a failed response is kept in the log.
+	maxErrorBody = 512
+)
+
+// Run delivers queued events until ctx is cancelled.
+func (w *Webhooks) Run(ctx context.Context) {
+	w.Logger.Info().Msg("webhook delivery worker started")
+
+	ticker := time.NewTicker(PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if err := w.DeliverDue(ctx); err != nil && !errors.Is(err, context.Canceled) {
+			w.Logger.Error().Err(err).Msg("failed to deliver queued webhooks")
+		}
+
+		select {
+		case <-ctx.Done():
+			w.Logger.Info().Msg("webhook delivery worker stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// DeliverDue sends every delivery that is currently due.
+func (w *Webhooks) DeliverDue(ctx context.Context) error {
+	for {
+		deliveries, err := w.WebhookRepo.ClaimDueDeliveries(ctx, batchSize, deliveryLease)
+		if err != nil {
+			return err
+		}
+
+		for i := range deliveries {
+			w.deliver(ctx, &deliveries[i])
+		}
+
+		if len(deliveries) < batchSize || ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+}
+
+func (w *Webhooks) deliver(ctx context.Context, delivery *repository.WebhookDelivery) {
+	log := w.Logger.With().
+		Str("delivery_id", delivery.ID.String()).
+		Str("endpoint_id", delivery.EndpointID.String()).
+		Int32("attempt", delivery.Attempts).
+		Logger()
+
+	status, sendErr := w.send(ctx, delivery)
+	if sendErr == nil {
+		if err := w.WebhookRepo.MarkDelivered(ctx, delivery.ID, status); err != nil {
+			log.Error().Err(err).Msg("webhook delivered but not marked as delivered")
+		}
+		return
+	}
+
+	var next time.Time
+	var permanent *permanentError
+	switch {
+	case errors.As(sendErr, &permanent):
+		log.Warn().Err(sendErr).Msg("webhook cannot be delivered")
+	case delivery.Attempts < delivery.MaxAttempts:
+		next = time.Now().Add(backoff(delivery.Attempts))
+		log.Warn().Err(sendErr).Int("status", status).Time("next_attempt_at", next).Msg("webhook delivery failed, will retry")
+	default:
+		log.Error().Err(sendErr).Int("status", status).Msg("webhook delivery failed, giving up")
+	}
+
+	if err := w.WebhookRepo.MarkDeliveryFailed(ctx, delivery.ID, status, sendErr.Error(), next); err != nil {
+		log.Error().Err(err).Msg("failed to record webhook delivery failure")
+	}
+}
+
+// permanentError fails a delivery without further attempts.
+type permanentError struct {
+	msg string
+}
+
+func (e *permanentError) Error() string {
+	return e.msg
+}
+
+// send posts the delivery and returns the response status, zero when no
+// response was received. Any status outside 2xx is a failure.
+func (w *Webhooks) send(ctx context.Context, delivery *repository.WebhookDelivery) (int, error) {
+	endpoint, err := w.WebhookRepo.GetEndpoint(ctx, repository.WebhookEndpointRepositoryFilter{
+		ID: &delivery.EndpointID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, &permanentError{msg: "endpoint no longer exists"}
+		}
+		return 0, err
+	}
+	if !endpoint.IsActive {
+		return 0, &permanentError{msg: "endpoint is disabled"}
+	}
+
+	secret, err := helpers.Decrypt(w.secretKey(), endpoint.SecretEncrypted)
+	if err != nil {
+		return 0, fmt.Errorf("decrypt signing secret: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint.Url, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, &permanentError{msg: err.Error()}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ARA-Cooperative-Webhooks/1.0")
+	req.Header.Set(HeaderEvent, delivery.EventType)
+	req.Header.Set(HeaderEventID, delivery.EventID.String())
+	req.Header.Set(HeaderDelivery, delivery.ID.String())
+	req.Header.Set(HeaderTimestamp, timestamp)
+	req.Header.Set(HeaderSignature, Sign(secret, timestamp, delivery.Payload))
+
+	resp, err := w.HTTPClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 200 && resp.StatusCode < 300 {
+		io.Copy(io.Discard, io.LimitReader(resp.Body, maxErrorBody))
+		return resp.StatusCode, nil
+	}
+
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	msg := fmt.Sprintf("endpoint responded with %s", resp.Status)
+	if trimmed := strings.TrimSpace(string(body)); trimmed != "" {
+		msg += ": " + trimmed
+	}
+
+	return resp.StatusCode, errors.New(msg)
+}
+
+// Sign returns the signature header value for a delivery: the hex HMAC-SHA256
+// of "<timestamp>.<body>" keyed with the endpoint secret. Receivers should
+// recompute it, compare in constant time and reject stale timestamps.
+func Sign(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// backoff doubles the delay after every attempt, starting at baseBackoff.
+func backoff(attempts int32) time.Duration {
+	delay := baseBackoff
+	for i := int32(1); i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+
+	return min(delay, maxBackoff)
+}
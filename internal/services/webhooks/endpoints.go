@@ -0,0 +1,270 @@
+package webhooks
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/helpers"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	svc "github.com/Jidetireni/ara-cooperative/internal/services"
+	"github.com/Jidetireni/ara-cooperative/internal/services/users"
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/samber/lo"
+)
+
+// secretPrefix makes leaked secrets easy to recognise.
+const secretPrefix = "whsec_"
+
+func (w *Webhooks) ListEndpoints(ctx context.Context) ([]dto.WebhookEndpoint, error) {
+	endpoints, err := w.WebhookRepo.ListEndpoints(ctx, repository.WebhookEndpointRepositoryFilter{}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return lo.Map(endpoints, func(endpoint repository.WebhookEndpoint, _ int) dto.WebhookEndpoint {
+		return *w.WebhookRepo.MapEndpointToDTOModel(&endpoint)
+	}), nil
+}
+
+func (w *Webhooks) GetEndpoint(ctx context.Context, id uuid.UUID) (*dto.WebhookEndpoint, error) {
+	endpoint, err := w.getEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.WebhookRepo.MapEndpointToDTOModel(endpoint), nil
+}
+
+// CreateEndpoint registers an endpoint and returns its signing secret. The
+// secret is not shown again; rotate it if it is lost.
+func (w *Webhooks) CreateEndpoint(ctx context.Context, input *dto.CreateWebhookEndpointInput) (*dto.WebhookEndpoint, error) {
+	actor, ok := users.FromContext(ctx)
+	if !ok {
+		return nil, svc.UnauthenticatedError()
+	}
+
+	if err := w.validate(input.URL, input.EventTypes); err != nil {
+		return nil, err
+	}
+
+	secret, encrypted, err := w.newSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	endpoint, err := w.WebhookRepo.CreateEndpoint(ctx, &repository.WebhookEndpoint{
+		Url:             input.URL,
+		Description:     repository.ToNullString(lo.EmptyableToPtr(input.Description)),
+		SecretEncrypted: encrypted,
+		EventTypes:      pq.StringArray(lo.Uniq(input.EventTypes)),
+		IsActive:        true,
+		CreatedBy:       actor.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	out := w.WebhookRepo.MapEndpointToDTOModel(endpoint)
+	out.Secret = secret
+
+	return out, nil
+}
+
+func (w *Webhooks) UpdateEndpoint(ctx context.Context, id uuid.UUID, input *dto.UpdateWebhookEndpointInput) (*dto.WebhookEndpoint, error) {
+	endpoint, err := w.getEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.URL != nil {
+		endpoint.Url = *input.URL
+	}
+	if input.Description != nil {
+		endpoint.Description = repository.ToNullString(lo.EmptyableToPtr(*input.Description))
+	}
+	if input.EventTypes != nil {
+		endpoint.EventTypes = pq.StringArray(lo.Uniq(*input.EventTypes))
+	}
+	if input.IsActive != nil {
+		endpoint.IsActive = *input.IsActive
+	}
+
+	if err := w.validate(endpoint.Url, endpoint.EventTypes); err != nil {
+		return nil, err
+	}
+
+	updated, err := w.WebhookRepo.UpdateEndpoint(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.WebhookRepo.MapEndpointToDTOModel(updated), nil
+}
+
+// RotateSecret replaces the signing secret. Deliveries sent from now on,
+// including retries of earlier events, are signed with the new one.
+func (w *Webhooks) RotateSecret(ctx context.Context, id uuid.UUID) (*dto.WebhookEndpoint, error) {
+	endpoint, err := w.getEndpoint(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, encrypted, err := w.newSecret()
+	if err != nil {
+		return nil, err
+	}
+	endpoint.SecretEncrypted = encrypted
+
+	updated, err := w.WebhookRepo.UpdateEndpoint(ctx, endpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	out := w.WebhookRepo.MapEndpointToDTOModel(updated)
+	out.Secret = secret
+
+	return out, nil
+}
+
+func (w *Webhooks) DeleteEndpoint(ctx context.Context, id uuid.UUID) error {
+	deleted, err := w.WebhookRepo.DeleteEndpoint(ctx, id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
+		return svc.ErrNotFound()
+	}
+
+	return nil
+}
+
+// ListDeliveries returns the delivery log of an endpoint, newest first by default.
+func (w *Webhooks) ListDeliveries(ctx context.Context, endpointID uuid.UUID, status *string, options *dto.QueryOptions) (*dto.ListResponse[dto.WebhookDelivery], error) {
+	if _, err := w.getEndpoint(ctx, endpointID); err != nil {
+		return nil, err
+	}
+
+	result, err := w.WebhookRepo.ListDeliveries(ctx, repository.WebhookDeliveryRepositoryFilter{
+		EndpointID: &endpointID,
+		Status:     status,
+	}, repository.QueryOptions{
+		Limit:  options.Limit,
+		Cursor: options.Cursor,
+		Sort:   options.Sort,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ListResponse[dto.WebhookDelivery]{
+		Items: lo.Map(result.Items, func(item *repository.WebhookDelivery, _ int) dto.WebhookDelivery {
+			return *w.WebhookRepo.MapDeliveryToDTOModel(item)
+		}),
+		NextCursor: result.NextCursor,
+	}, nil
+}
+
+// Redeliver queues the event of a delivery again as a new delivery, whatever
+// the outcome of the original. The event ID is kept so receivers can tell it
+// is a duplicate.
+func (w *Webhooks) Redeliver(ctx context.Context, deliveryID uuid.UUID) (*dto.WebhookDelivery, error) {
+	delivery, err := w.WebhookRepo.GetDelivery(ctx, repository.WebhookDeliveryRepositoryFilter{
+		ID: &deliveryID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	endpoint, err := w.getEndpoint(ctx, delivery.EndpointID)
+	if err != nil {
+		return nil, err
+	}
+	if !endpoint.IsActive {
+		return nil, &svc.APIError{
+			Status:  http.StatusConflict,
+			Message: "endpoint is disabled",
+		}
+	}
+
+	created, err := w.WebhookRepo.CreateDelivery(ctx, &repository.WebhookDelivery{
+		EndpointID:  delivery.EndpointID,
+		EventID:     delivery.EventID,
+		EventType:   delivery.EventType,
+		Payload:     delivery.Payload,
+		MaxAttempts: defaultMaxAttempts,
+	}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return w.WebhookRepo.MapDeliveryToDTOModel(created), nil
+}
+
+func (w *Webhooks) getEndpoint(ctx context.Context, id uuid.UUID) (*repository.WebhookEndpoint, error) {
+	endpoint, err := w.WebhookRepo.GetEndpoint(ctx, repository.WebhookEndpointRepositoryFilter{
+		ID: &id,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, svc.ErrNotFound()
+		}
+		return nil, err
+	}
+
+	return endpoint, nil
+}
+
+// validate requires HTTPS outside development, since the payload carries
+// member data, and known event types.
+func (w *Webhooks) validate(rawURL string, eventTypes []string) error {
+	invalid := map[string]string{}
+
+	u, err := url.Parse(rawURL)
+	switch {
+	case err != nil || u.Host == "":
+		invalid["url"] = "must be an absolute URL"
+	case u.Scheme != "https" && !(w.Config.IsDev && u.Scheme == "http"):
+		invalid["url"] = "must use https"
+	}
+
+	for i, eventType := range eventTypes {
+		if !lo.Contains(EventTypes, EventType(eventType)) {
+			invalid[fmt.Sprintf("event_types[%d]", i)] = "unknown event type"
+		}
+	}
+
+	if len(invalid) > 0 {
+		return &svc.APIError{
+			Status:  http.StatusBadRequest,
+			Message: "invalid webhook endpoint",
+			Errors:  invalid,
+		}
+	}
+
+	return nil
+}
+
+// newSecret returns a signing secret and its encrypted form for storage.
+func (w *Webhooks) newSecret() (string, string, error) {
+	token, err := helpers.GenerateSecureToken()
+	if err != nil {
+		return "", "", err
+	}
+
+	secret := secretPrefix + token
+	encrypted, err := helpers.Encrypt(w.secretKey(), secret)
+	if err != nil {
+		return "", "", err
+	}
+
+	return secret, encrypted, nil
+}
@@ -0,0 +1,140 @@
+package webhooks
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+	"github.com/Jidetireni/ara-cooperative/internal/dto"
+	"github.com/Jidetireni/ara-cooperative/internal/helpers"
+	"github.com/Jidetireni/ara-cooperative/internal/repository"
+	"github.com/Jidetireni/ara-cooperative/pkg/logger"
+	"github.com/google/uuid"
+	"github.com/jmoiron/sqlx"
+	"github.com/samber/lo"
+)
+
+var (
+	_ WebhookRepository = (*repository.WebhookRepository)(nil)
+)
+
+type WebhookRepository interface {
+	CreateEndpoint(ctx context.Context, endpoint *repository.WebhookEndpoint) (*repository.WebhookEndpoint, error)
+	UpdateEndpoint(ctx context.Context, endpoint *repository.WebhookEndpoint) (*repository.WebhookEndpoint, error)
+	DeleteEndpoint(ctx context.Context, id uuid.UUID) (bool, error)
+	GetEndpoint(ctx context.Context, filter repository.WebhookEndpointRepositoryFilter) (*repository.WebhookEndpoint, error)
+	ListEndpoints(ctx context.Context, filter repository.WebhookEndpointRepositoryFilter, tx *sqlx.Tx) ([]repository.WebhookEndpoint, error)
+	CreateDelivery(ctx context.Context, delivery *repository.WebhookDelivery, tx *sqlx.Tx) (*repository.WebhookDelivery, error)
+	ClaimDueDeliveries(ctx context.Context, limit uint64, lease time.Duration) ([]repository.WebhookDelivery, error)
+	MarkDelivered(ctx context.Context, id uuid.UUID, responseStatus int) error
+	MarkDeliveryFailed(ctx context.Context, id uuid.UUID, responseStatus int, lastError string, nextAttemptAt time.Time) error
+	GetDelivery(ctx context.Context, filter repository.WebhookDeliveryRepositoryFilter) (*repository.WebhookDelivery, error)
+	ListDeliveries(ctx context.Context, filter repository.WebhookDeliveryRepositoryFilter, opts repository.QueryOptions) (*repository.ListResult[repository.WebhookDelivery], error)
+	MapEndpointToDTOModel(endpoint *repository.WebhookEndpoint) *dto.WebhookEndpoint
+	MapDeliveryToDTOModel(delivery *repository.WebhookDelivery) *dto.WebhookDelivery
+}
+
+type EventType string
+
+const (
+	EventMemberCreated        EventType = "member.created"
+	EventMemberActivated      EventType = "member.activated"
+	EventMemberStatusChanged  EventType = "member.status_changed"
+	EventTransactionCreated   EventType = "transaction.created"
+	EventTransactionConfirmed EventType = "transaction.confirmed"
+	EventTransactionRejected  EventType = "transaction.rejected"
+	EventFineCharged          EventType = "fine.charged"
+	EventFinePaid             EventType = "fine.paid"
+)
+
+// EventTypes lists every event an endpoint can subscribe to.
+var EventTypes = []EventType{
+	EventMemberCreated,
+	EventMemberActivated,
+	EventMemberStatusChanged,
+	EventTransactionCreated,
+	EventTransactionConfirmed,
+	EventTransactionRejected,
+	EventFineCharged,
+	EventFinePaid,
+}
+
+// Event is the JSON body posted to endpoints. ID is shared by every delivery
+// of the event, including redeliveries, so receivers can drop duplicates.
+type Event struct {
+	ID        uuid.UUID `json:"id"`
+	Type      EventType `json:"type"`
+	CreatedAt time.Time `json:"created_at"`
+	Data      any       `json:"data"`
+}
+
+type Webhooks struct {
+	Config      *config.Config
+	WebhookRepo WebhookRepository
+	HTTPClient  *http.Client
+	Logger      *logger.Logger
+}
+
+func New(cfg *config.Config, webhookRepo WebhookRepository, logger *logger.Logger) *Webhooks {
+	return &Webhooks{
+		Config:      cfg,
+		WebhookRepo: webhookRepo,
+		HTTPClient: &http.Client{
+			Timeout: requestTimeout,
+			// A redirect is reported as a failure rather than followed, so a
+			// secret is never replayed to a host nobody registered.
+			CheckRedirect: func(*http.Request, []*http.Request) error {
+				return http.ErrUseLastResponse
+			},
+		},
+		Logger: logger,
+	}
+}
+
+// Emit queues eventType for every active endpoint subscribed to it. Pass the
+// transaction of the change being announced: nothing is sent if it rolls back.
+// data is serialised when queued, so later changes to it are not sent.
+func (w *Webhooks) Emit(ctx context.Context, eventType EventType, data any, tx *sqlx.Tx) error {
+	endpoints, err := w.WebhookRepo.ListEndpoints(ctx, repository.WebhookEndpointRepositoryFilter{
+		IsActive:  lo.ToPtr(true),
+		EventType: lo.ToPtr(string(eventType)),
+	}, tx)
+	if err != nil {
+		return err
+	}
+	if len(endpoints) == 0 {
+		return nil
+	}
+
+	event := Event{
+		ID:        uuid.New(),
+		Type:      eventType,
+		CreatedAt: time.Now().UTC(),
+		Data:      data,
+	}
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	for _, endpoint := range endpoints {
+		_, err := w.WebhookRepo.CreateDelivery(ctx, &repository.WebhookDelivery{
+			EndpointID:  endpoint.ID,
+			EventID:     event.ID,
+			EventType:   string(eventType),
+			Payload:     payload,
+			MaxAttempts: defaultMaxAttempts,
+		}, tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (w *Webhooks) secretKey() []byte {
+	return helpers.DeriveKey(w.Config.Auth.WebhookEncryptionKey)
+}
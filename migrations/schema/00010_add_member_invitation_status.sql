@@ -0,0 +1,21 @@
+-- +goose Up
+ALTER TABLE members
+    ADD COLUMN invitation_status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    ADD COLUMN invitation_sent_at TIMESTAMPTZ,
+    ADD COLUMN invitation_count INTEGER NOT NULL DEFAULT 0;
+
+-- Members who already set a password have accepted their invitation
+UPDATE members mb
+SET invitation_status = 'ACCEPTED'
+FROM users u
+WHERE u.id = mb.user_id AND u.password_hash IS NOT NULL;
+
+CREATE INDEX idx_members_invitation_status ON members (invitation_status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_members_invitation_status;
+
+ALTER TABLE members
+    DROP COLUMN IF EXISTS invitation_status,
+    DROP COLUMN IF EXISTS invitation_sent_at,
+    DROP COLUMN IF EXISTS invitation_count;
@@ -0,0 +1,31 @@
+-- +goose Up
+CREATE TABLE
+  email_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipient VARCHAR(255) NOT NULL,
+    subject VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 8,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    reference_type VARCHAR(50),
+    reference_id UUID,
+    sent_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_email_outbox_due ON email_outbox (next_attempt_at)
+WHERE
+  status = 'PENDING';
+
+CREATE INDEX idx_email_outbox_status ON email_outbox (status);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_email_outbox_due;
+
+DROP INDEX IF EXISTS idx_email_outbox_status;
+
+DROP TABLE IF EXISTS email_outbox;
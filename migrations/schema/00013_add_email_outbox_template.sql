@@ -0,0 +1,9 @@
+-- +goose Up
+ALTER TABLE email_outbox
+    ADD COLUMN template VARCHAR(50),
+    ADD COLUMN text_body TEXT;
+
+-- +goose Down
+ALTER TABLE email_outbox
+    DROP COLUMN IF EXISTS template,
+    DROP COLUMN IF EXISTS text_body;
@@ -0,0 +1,52 @@
+-- +goose Up
+CREATE TABLE
+  notifications (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    title VARCHAR(255) NOT NULL,
+    body TEXT NOT NULL,
+    reference_type VARCHAR(50),
+    reference_id UUID,
+    read_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_notifications_user_id ON notifications (user_id, created_at DESC);
+
+CREATE INDEX idx_notifications_unread ON notifications (user_id)
+WHERE
+  read_at IS NULL;
+
+-- A missing row means the channel is enabled for that event.
+CREATE TABLE
+  notification_preferences (
+    user_id UUID NOT NULL REFERENCES users (id) ON DELETE CASCADE,
+    event VARCHAR(50) NOT NULL,
+    channel VARCHAR(20) NOT NULL,
+    enabled BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, event, channel)
+  );
+
+ALTER TABLE fines
+ADD COLUMN reminder_sent_at TIMESTAMPTZ;
+
+CREATE INDEX idx_fines_unreminded ON fines (deadline)
+WHERE
+  paid_at IS NULL
+  AND reminder_sent_at IS NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_fines_unreminded;
+
+ALTER TABLE fines
+DROP COLUMN IF EXISTS reminder_sent_at;
+
+DROP TABLE IF EXISTS notification_preferences;
+
+DROP INDEX IF EXISTS idx_notifications_unread;
+
+DROP INDEX IF EXISTS idx_notifications_user_id;
+
+DROP TABLE IF EXISTS notifications;
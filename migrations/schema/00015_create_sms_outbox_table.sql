@@ -0,0 +1,26 @@
+-- +goose Up
+CREATE TABLE
+  sms_outbox (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    recipient VARCHAR(20) NOT NULL,
+    body TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    max_attempts INTEGER NOT NULL DEFAULT 5,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    last_error TEXT,
+    reference_type VARCHAR(50),
+    reference_id UUID,
+    sent_at TIMESTAMPTZ,
+    updated_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_sms_outbox_due ON sms_outbox (next_attempt_at)
+WHERE
+  status = 'PENDING';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_sms_outbox_due;
+
+DROP TABLE IF EXISTS sms_outbox;
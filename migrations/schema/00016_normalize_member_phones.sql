@@ -0,0 +1,49 @@
+-- +goose Up
+-- Store Nigerian numbers in E.164 (+234XXXXXXXXXX). Numbers in any other
+-- format are left alone and normalized when the member next updates them.
+--
+-- Numbers that would collide with another member's, because they only differ
+-- in format, are left as they are for an admin to resolve, since the unique
+-- index on phone would abort the migration.
+WITH
+  normalized AS (
+    SELECT
+      id,
+      '+234' || RIGHT(regexp_replace(phone, '\D', '', 'g'), 10) AS phone
+    FROM
+      members
+    WHERE
+      regexp_replace(phone, '\D', '', 'g') ~ '^(0|234)[1-9][0-9]{9}$'
+  ),
+  unique_phones AS (
+    SELECT
+      n.id,
+      n.phone
+    FROM
+      normalized n
+    WHERE
+      NOT EXISTS (
+        SELECT 1 FROM normalized o WHERE o.phone = n.phone AND o.id <> n.id
+      )
+      AND NOT EXISTS (
+        SELECT 1 FROM members m WHERE m.phone = n.phone AND m.id <> n.id
+      )
+  )
+UPDATE members
+SET
+  phone = u.phone
+FROM
+  unique_phones u
+WHERE
+  members.id = u.id
+  AND members.phone <> u.phone;
+
+UPDATE members
+SET
+  next_of_kin_phone = '+234' || RIGHT(regexp_replace(next_of_kin_phone, '\D', '', 'g'), 10)
+WHERE
+  regexp_replace(next_of_kin_phone, '\D', '', 'g') ~ '^(0|234)[1-9][0-9]{9}$';
+
+-- +goose Down
+-- Normalized numbers are equivalent to the originals, so there is nothing to undo.
+SELECT 1;
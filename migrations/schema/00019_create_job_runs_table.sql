@@ -0,0 +1,29 @@
+-- +goose Up
+CREATE TABLE
+  job_runs (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    job_name VARCHAR(100) NOT NULL,
+    source VARCHAR(20) NOT NULL,
+    status VARCHAR(20) NOT NULL,
+    -- Set for scheduled runs; the unique constraint lets one replica claim each occurrence
+    scheduled_for TIMESTAMPTZ,
+    triggered_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    started_at TIMESTAMPTZ,
+    finished_at TIMESTAMPTZ,
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (job_name, scheduled_for)
+  );
+
+CREATE INDEX idx_job_runs_job_name ON job_runs (job_name, created_at DESC);
+
+CREATE INDEX idx_job_runs_queued ON job_runs (created_at)
+WHERE
+  status = 'QUEUED';
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_job_runs_job_name;
+
+DROP INDEX IF EXISTS idx_job_runs_queued;
+
+DROP TABLE IF EXISTS job_runs;
@@ -0,0 +1,46 @@
+-- +goose Up
+CREATE TABLE
+  member_change_requests (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+    -- Requested values keyed by field, e.g. {"phone": "+2348031234567"}
+    changes JSONB NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    requested_by UUID NOT NULL REFERENCES users (id),
+    reviewed_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    review_note TEXT,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+-- A member has at most one request awaiting review
+CREATE UNIQUE INDEX idx_member_change_requests_pending ON member_change_requests (member_id)
+WHERE
+  status = 'PENDING';
+
+CREATE INDEX idx_member_change_requests_status ON member_change_requests (status, created_at);
+
+CREATE TABLE
+  member_field_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+    field VARCHAR(50) NOT NULL,
+    old_value TEXT,
+    new_value TEXT,
+    changed_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    change_request_id UUID REFERENCES member_change_requests (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_member_field_changes_member_id ON member_field_changes (member_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_member_field_changes_member_id;
+
+DROP TABLE IF EXISTS member_field_changes;
+
+DROP INDEX IF EXISTS idx_member_change_requests_status;
+
+DROP INDEX IF EXISTS idx_member_change_requests_pending;
+
+DROP TABLE IF EXISTS member_change_requests;
@@ -0,0 +1,32 @@
+-- +goose Up
+ALTER TABLE members
+    ADD COLUMN status VARCHAR(20) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'ACTIVE', 'SUSPENDED', 'DORMANT', 'EXITING', 'EXITED'));
+
+-- Members who paid their registration fee are active
+UPDATE members SET status = 'ACTIVE' WHERE activated_at IS NOT NULL;
+
+CREATE INDEX idx_members_status ON members (status);
+
+CREATE TABLE
+  member_status_changes (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+    from_status VARCHAR(20) NOT NULL,
+    to_status VARCHAR(20) NOT NULL,
+    reason TEXT NOT NULL,
+    -- NULL when the system made the change, e.g. activation on payment
+    changed_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_member_status_changes_member ON member_status_changes (member_id, created_at DESC);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_member_status_changes_member;
+DROP TABLE IF EXISTS member_status_changes;
+
+DROP INDEX IF EXISTS idx_members_status;
+
+ALTER TABLE members
+    DROP COLUMN IF EXISTS status;
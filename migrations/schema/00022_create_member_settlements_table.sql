@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE TABLE
+  member_settlements (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL UNIQUE REFERENCES members (id) ON DELETE CASCADE,
+    savings BIGINT NOT NULL,
+    special_deposit BIGINT NOT NULL,
+    -- Units bought back and the unit price they were valued at
+    share_units DECIMAL(18, 4) NOT NULL,
+    share_unit_price BIGINT NOT NULL,
+    share_value BIGINT NOT NULL,
+    unpaid_fines BIGINT NOT NULL,
+    outstanding_loans BIGINT NOT NULL,
+    -- What the cooperative owes the member after offsets
+    net_amount BIGINT NOT NULL,
+    settled_by UUID NOT NULL REFERENCES users (id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+-- +goose Down
+DROP TABLE IF EXISTS member_settlements;
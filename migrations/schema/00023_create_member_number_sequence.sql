@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE SEQUENCE member_number_seq AS BIGINT MINVALUE 1;
+
+-- Existing slugs are kept. Start numbering after the highest legacy number so
+-- new members sort after them; the allocator skips any slug that is taken.
+SELECT setval(
+    'member_number_seq',
+    GREATEST(COALESCE(MAX(substring(slug FROM '([0-9]+)$')::BIGINT), 0), 1),
+    COALESCE(MAX(substring(slug FROM '([0-9]+)$')::BIGINT), 0) > 0
+  )
+FROM members;
+
+-- +goose Down
+DROP SEQUENCE IF EXISTS member_number_seq;
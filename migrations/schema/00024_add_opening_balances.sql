@@ -0,0 +1,14 @@
+-- +goose Up
+-- Opening balances carry a member's history over from the spreadsheets the
+-- cooperative kept before go-live. Their created_at is the effective date.
+ALTER TABLE transactions ADD COLUMN opening_balance BOOLEAN NOT NULL DEFAULT FALSE;
+ALTER TABLE fines ADD COLUMN opening_balance BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- A member has at most one opening balance per ledger
+CREATE UNIQUE INDEX idx_transactions_opening_balance ON transactions (member_id, ledger)
+WHERE opening_balance;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_transactions_opening_balance;
+ALTER TABLE fines DROP COLUMN IF EXISTS opening_balance;
+ALTER TABLE transactions DROP COLUMN IF EXISTS opening_balance;
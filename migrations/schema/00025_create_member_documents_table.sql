@@ -0,0 +1,30 @@
+-- +goose Up
+CREATE TABLE
+  member_documents (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+    -- MEMBERSHIP_FORM, ID_CARD, PASSPORT_PHOTO or UTILITY_BILL
+    type VARCHAR(30) NOT NULL,
+    -- Where the file is kept in the configured storage backend
+    storage_key TEXT NOT NULL UNIQUE,
+    content_type VARCHAR(100) NOT NULL,
+    size_bytes BIGINT NOT NULL,
+    original_name TEXT NOT NULL,
+    status VARCHAR(20) NOT NULL DEFAULT 'PENDING',
+    uploaded_by UUID NOT NULL REFERENCES users (id),
+    reviewed_by UUID REFERENCES users (id) ON DELETE SET NULL,
+    review_note TEXT,
+    reviewed_at TIMESTAMPTZ,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP
+  );
+
+CREATE INDEX idx_member_documents_member_id ON member_documents (member_id, type, created_at DESC);
+
+CREATE INDEX idx_member_documents_status ON member_documents (status, created_at);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_member_documents_status;
+
+DROP INDEX IF EXISTS idx_member_documents_member_id;
+
+DROP TABLE IF EXISTS member_documents;
@@ -0,0 +1,29 @@
+-- +goose Up
+-- Next of kin beyond the one on members, and the beneficiaries of a member's
+-- entitlement on their death. A row is either or both.
+CREATE TABLE
+  member_beneficiaries (
+    id UUID PRIMARY KEY DEFAULT gen_random_uuid(),
+    member_id UUID NOT NULL REFERENCES members (id) ON DELETE CASCADE,
+    name VARCHAR(100) NOT NULL,
+    relationship VARCHAR(50) NOT NULL,
+    phone VARCHAR(20),
+    -- Share of the entitlement; the shares of a member add up to 100
+    percentage DECIMAL(5, 2) NOT NULL DEFAULT 0 CHECK (percentage >= 0 AND percentage <= 100),
+    next_of_kin BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ,
+    CHECK (percentage > 0 OR next_of_kin)
+  );
+
+CREATE INDEX idx_member_beneficiaries_member_id ON member_beneficiaries (member_id, created_at);
+
+-- How the net amount of a settlement was split between the beneficiaries
+ALTER TABLE member_settlements ADD COLUMN beneficiaries JSONB NOT NULL DEFAULT '[]';
+
+-- +goose Down
+ALTER TABLE member_settlements DROP COLUMN IF EXISTS beneficiaries;
+
+DROP INDEX IF EXISTS idx_member_beneficiaries_member_id;
+
+DROP TABLE IF EXISTS member_beneficiaries;
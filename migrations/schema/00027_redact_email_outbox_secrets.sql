@@ -0,0 +1,20 @@
+-- +goose Up
+-- Messages carrying one-time codes or reset links are marked sensitive and
+-- their bodies are wiped once they are sent or dead-lettered.
+ALTER TABLE email_outbox
+    ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN redacted_at TIMESTAMPTZ;
+
+-- Messages queued before templates were recorded may hold secrets as well.
+UPDATE email_outbox
+SET sensitive = TRUE
+WHERE template IS NULL OR template IN ('welcome', 'password_reset');
+
+UPDATE email_outbox
+SET body = '', text_body = NULL, redacted_at = NOW()
+WHERE sensitive AND status IN ('SENT', 'DEAD');
+
+-- +goose Down
+ALTER TABLE email_outbox
+    DROP COLUMN IF EXISTS sensitive,
+    DROP COLUMN IF EXISTS redacted_at;
@@ -0,0 +1,19 @@
+-- +goose Up
+-- Texts carrying one-time codes are marked sensitive and their bodies are
+-- wiped once they are sent or dead-lettered.
+ALTER TABLE sms_outbox
+    ADD COLUMN sensitive BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN redacted_at TIMESTAMPTZ;
+
+UPDATE sms_outbox
+SET sensitive = TRUE
+WHERE body LIKE '%your code to set your password is%';
+
+UPDATE sms_outbox
+SET body = '', redacted_at = NOW()
+WHERE sensitive AND status IN ('SENT', 'DEAD');
+
+-- +goose Down
+ALTER TABLE sms_outbox
+    DROP COLUMN IF EXISTS sensitive,
+    DROP COLUMN IF EXISTS redacted_at;
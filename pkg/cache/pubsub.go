@@ -0,0 +1,44 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Publish sends value as JSON to every subscriber of channel, on every replica.
+// Messages are not stored: subscribers that are not connected miss them.
+func (r *Redis) Publish(ctx context.Context, channel string, value any) error {
+	v, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	r.Logger.Debug().Str("channel", channel).Msg("publishing message")
+	return r.Client.Publish(ctx, channel, v).Err()
+}
+
+// Subscribe calls handle with every message published to a channel matching
+// one of patterns until ctx is cancelled. handle runs on a single goroutine,
+// so it must not block.
+func (r *Redis) Subscribe(ctx context.Context, handle func(channel string, payload []byte), patterns ...string) error {
+	pubsub := r.Client.PSubscribe(ctx, patterns...)
+	defer pubsub.Close()
+
+	// Wait for the subscription to be confirmed so connection errors surface here.
+	if _, err := pubsub.Receive(ctx); err != nil {
+		return err
+	}
+
+	messages := pubsub.Channel()
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case msg, ok := <-messages:
+			if !ok {
+				return nil
+			}
+			handle(msg.Channel, []byte(msg.Payload))
+		}
+	}
+}
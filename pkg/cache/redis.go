@@ -98,3 +98,21 @@ func (r *Redis) Delete(ctx context.Context, key string) error {
 	r.Logger.Debug().Str("key", key).Msg("deleting cache value")
 	return r.Client.Del(ctx, key).Err()
 }
+
+// Increment bumps a counter that resets once window has passed since its first
+// increment, and returns the new count. It is the building block for rate limits.
+func (r *Redis) Increment(ctx context.Context, key string, window time.Duration) (int64, error) {
+	r.Logger.Debug().Str("key", key).Msg("incrementing counter")
+
+	var incr *rds.IntCmd
+	_, err := r.Client.TxPipelined(ctx, func(pipe rds.Pipeliner) error {
+		incr = pipe.Incr(ctx, key)
+		pipe.ExpireNX(ctx, key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return incr.Val(), nil
+}
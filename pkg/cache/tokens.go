@@ -0,0 +1,55 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	rds "github.com/redis/go-redis/v9"
+)
+
+const (
+	tokenDenylistKeyPrefix = "token_denylist:"
+	tokenVersionKeyPrefix  = "token_version:"
+)
+
+// DenyToken adds a token ID (jti) to the denylist until the token would have expired anyway.
+func (r *Redis) DenyToken(ctx context.Context, tokenID string, expiration time.Duration) error {
+	if expiration <= 0 {
+		return nil
+	}
+
+	r.Logger.Debug().Str("jti", tokenID).Msg("adding token to denylist")
+	return r.Client.Set(ctx, tokenDenylistKeyPrefix+tokenID, 1, expiration).Err()
+}
+
+func (r *Redis) IsTokenDenied(ctx context.Context, tokenID string) (bool, error) {
+	count, err := r.Client.Exists(ctx, tokenDenylistKeyPrefix+tokenID).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetTokenVersion returns the current token version for a user. Users that
+// never had their sessions revoked are on version 0.
+func (r *Redis) GetTokenVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	val, err := r.Client.Get(ctx, tokenVersionKeyPrefix+userID.String()).Result()
+	if err != nil {
+		if errors.Is(err, rds.Nil) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return strconv.ParseInt(val, 10, 64)
+}
+
+// BumpTokenVersion invalidates every access token issued to the user so far.
+func (r *Redis) BumpTokenVersion(ctx context.Context, userID uuid.UUID) (int64, error) {
+	r.Logger.Debug().Str("user_id", userID.String()).Msg("bumping token version")
+	return r.Client.Incr(ctx, tokenVersionKeyPrefix+userID.String()).Result()
+}
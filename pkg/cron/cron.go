@@ -0,0 +1,205 @@
+// Package cron parses the five-field cron expressions used to schedule jobs.
+package cron
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// descriptors are the shorthand expressions Parse accepts.
+var descriptors = map[string]string{
+	"@yearly":   "0 0 1 1 *",
+	"@annually": "0 0 1 1 *",
+	"@monthly":  "0 0 1 * *",
+	"@weekly":   "0 0 * * 0",
+	"@daily":    "0 0 * * *",
+	"@midnight": "0 0 * * *",
+	"@hourly":   "0 * * * *",
+}
+
+type bounds struct {
+	min, max int
+}
+
+var (
+	minutes  = bounds{0, 59}
+	hours    = bounds{0, 23}
+	days     = bounds{1, 31}
+	months   = bounds{1, 12}
+	weekdays = bounds{0, 7} // 0 and 7 are both Sunday
+)
+
+// Schedule is a parsed cron expression. Each field is a bitset of the values
+// it matches.
+type Schedule struct {
+	minute, hour, dom, month, dow uint64
+
+	// domAny and dowAny record a "*" day field. When both day fields are
+	// restricted a day matches if either does, as in crontab(5).
+	domAny, dowAny bool
+}
+
+// Parse parses "minute hour day-of-month month day-of-week", where each field
+// is "*" or a comma separated list of values, ranges ("1-5") and steps ("*/15",
+// "0-30/10"), or one of the descriptors such as "@hourly".
+func Parse(expr string) (*Schedule, error) {
+	spec := strings.TrimSpace(expr)
+	if d, ok := descriptors[spec]; ok {
+		spec = d
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("cron: %q: expected 5 fields, got %d", expr, len(fields))
+	}
+
+	var s Schedule
+	var err error
+	if s.minute, _, err = parseField(fields[0], minutes); err != nil {
+		return nil, fmt.Errorf("cron: %q: minute: %w", expr, err)
+	}
+	if s.hour, _, err = parseField(fields[1], hours); err != nil {
+		return nil, fmt.Errorf("cron: %q: hour: %w", expr, err)
+	}
+	if s.dom, s.domAny, err = parseField(fields[2], days); err != nil {
+		return nil, fmt.Errorf("cron: %q: day of month: %w", expr, err)
+	}
+	if s.month, _, err = parseField(fields[3], months); err != nil {
+		return nil, fmt.Errorf("cron: %q: month: %w", expr, err)
+	}
+	if s.dow, s.dowAny, err = parseField(fields[4], weekdays); err != nil {
+		return nil, fmt.Errorf("cron: %q: day of week: %w", expr, err)
+	}
+
+	if s.dow&(1<<7) != 0 {
+		s.dow = s.dow&^(1<<7) | 1
+	}
+
+	return &s, nil
+}
+
+// Next returns the first matching minute strictly after t, in t's location.
+// It returns the zero time if nothing matches within five years, which only
+// happens for dates that do not exist such as "0 0 30 2 *".
+//
+// Fields match wall clock time. A time repeated when the clocks go back
+// matches once, at its first occurrence, and a time skipped when they go
+// forward matches at the end of the gap.
+func (s *Schedule) Next(t time.Time) time.Time {
+	loc := t.Location()
+
+	// Search wall clock times in UTC, which has no transitions, and convert
+	// each match back to loc.
+	w := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), 0, 0, time.UTC).Add(time.Minute)
+	limit := w.AddDate(5, 0, 0)
+
+	for w.Before(limit) {
+		if s.month&(1<<uint(w.Month())) == 0 {
+			w = time.Date(w.Year(), w.Month()+1, 1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if !s.dayMatches(w) {
+			w = time.Date(w.Year(), w.Month(), w.Day()+1, 0, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.hour&(1<<uint(w.Hour())) == 0 {
+			w = time.Date(w.Year(), w.Month(), w.Day(), w.Hour()+1, 0, 0, 0, time.UTC)
+			continue
+		}
+		if s.minute&(1<<uint(w.Minute())) == 0 {
+			w = w.Add(time.Minute)
+			continue
+		}
+
+		next := time.Date(w.Year(), w.Month(), w.Day(), w.Hour(), w.Minute(), 0, 0, loc)
+		if next.Hour() != w.Hour() || next.Minute() != w.Minute() {
+			// w falls in a gap, which time.Date resolves to before it.
+			next = time.Date(w.Year(), w.Month(), w.Day(), w.Hour()+1, 0, 0, 0, loc)
+		}
+		// The first occurrence of a repeated time can lie before t.
+		if !next.After(t) {
+			w = w.Add(time.Minute)
+			continue
+		}
+
+		return next
+	}
+
+	return time.Time{}
+}
+
+func (s *Schedule) dayMatches(t time.Time) bool {
+	dom := s.dom&(1<<uint(t.Day())) != 0
+	dow := s.dow&(1<<uint(t.Weekday())) != 0
+	if s.domAny || s.dowAny {
+		return dom && dow
+	}
+
+	return dom || dow
+}
+
+// parseField returns the bitset of values matched by field and whether it is
+// an unrestricted "*".
+func parseField(field string, b bounds) (uint64, bool, error) {
+	var bits uint64
+	for _, part := range strings.Split(field, ",") {
+		rng, stepStr, hasStep := strings.Cut(part, "/")
+
+		step := 1
+		if hasStep {
+			n, err := strconv.Atoi(stepStr)
+			if err != nil || n <= 0 {
+				return 0, false, fmt.Errorf("invalid step %q", stepStr)
+			}
+			step = n
+		}
+
+		var lo, hi int
+		switch {
+		case rng == "*":
+			lo, hi = b.min, b.max
+		case strings.Contains(rng, "-"):
+			from, to, _ := strings.Cut(rng, "-")
+			var err error
+			if lo, err = parseValue(from, b); err != nil {
+				return 0, false, err
+			}
+			if hi, err = parseValue(to, b); err != nil {
+				return 0, false, err
+			}
+			if lo > hi {
+				return 0, false, fmt.Errorf("invalid range %q", rng)
+			}
+		default:
+			v, err := parseValue(rng, b)
+			if err != nil {
+				return 0, false, err
+			}
+			lo, hi = v, v
+			// "5/15" means from 5 to the end in steps of 15.
+			if hasStep {
+				hi = b.max
+			}
+		}
+
+		for v := lo; v <= hi; v += step {
+			bits |= 1 << uint(v)
+		}
+	}
+
+	return bits, field == "*", nil
+}
+
+func parseValue(s string, b bounds) (int, error) {
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid value %q", s)
+	}
+	if v < b.min || v > b.max {
+		return 0, fmt.Errorf("value %d out of range %d-%d", v, b.min, b.max)
+	}
+
+	return v, nil
+}
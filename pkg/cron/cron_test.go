@@ -0,0 +1,138 @@
+package cron
+
+import (
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestNext(t *testing.T) {
+	utc := func(year int, month time.Month, day, hour, min int) time.Time {
+		return time.Date(year, month, day, hour, min, 0, 0, time.UTC)
+	}
+
+	// 2025-06-01 is a Sunday.
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"step", "*/15 * * * *", utc(2025, 6, 1, 10, 7), utc(2025, 6, 1, 10, 15)},
+		{"step into next hour", "*/15 * * * *", utc(2025, 6, 1, 10, 45), utc(2025, 6, 1, 11, 0)},
+		{"step from value", "5/20 * * * *", utc(2025, 6, 1, 10, 6), utc(2025, 6, 1, 10, 25)},
+		{"step over range", "0-30/10 8 * * *", utc(2025, 6, 1, 8, 31), utc(2025, 6, 2, 8, 0)},
+		{"strictly after", "0 12 * * *", utc(2025, 6, 1, 12, 0), utc(2025, 6, 2, 12, 0)},
+		{"ignores seconds", "0 12 * * *", utc(2025, 6, 1, 11, 59).Add(30 * time.Second), utc(2025, 6, 1, 12, 0)},
+		{"weekday range", "0 9 * * 1-5", utc(2025, 6, 7, 10, 0), utc(2025, 6, 9, 9, 0)},
+		{"list", "0 0 1,15 * *", utc(2025, 6, 2, 0, 0), utc(2025, 6, 15, 0, 0)},
+		{"month boundary", "0 0 1 * *", utc(2025, 1, 31, 12, 0), utc(2025, 2, 1, 0, 0)},
+		{"year boundary", "0 0 * * *", utc(2025, 12, 31, 23, 59), utc(2026, 1, 1, 0, 0)},
+		{"skips short months", "0 0 31 * *", utc(2025, 4, 1, 0, 0), utc(2025, 5, 31, 0, 0)},
+		{"leap day", "0 0 29 2 *", utc(2025, 3, 1, 0, 0), utc(2028, 2, 29, 0, 0)},
+		{"day that never exists", "0 0 30 2 *", utc(2025, 1, 1, 0, 0), time.Time{}},
+		{"month list", "0 0 1 3,9 *", utc(2025, 3, 2, 0, 0), utc(2025, 9, 1, 0, 0)},
+
+		{"day of week only", "0 0 * * 5", utc(2025, 6, 7, 0, 0), utc(2025, 6, 13, 0, 0)},
+		{"day of month only", "0 0 10 * *", utc(2025, 6, 7, 0, 0), utc(2025, 6, 10, 0, 0)},
+		{"either day field, weekday first", "0 0 13 * 5", utc(2025, 6, 1, 0, 0), utc(2025, 6, 6, 0, 0)},
+		{"either day field, date first", "0 0 10 * 5", utc(2025, 6, 7, 0, 0), utc(2025, 6, 10, 0, 0)},
+		{"7 is sunday", "0 0 * * 7", utc(2025, 6, 2, 0, 0), utc(2025, 6, 8, 0, 0)},
+		{"0 is sunday", "0 0 * * 0", utc(2025, 6, 2, 0, 0), utc(2025, 6, 8, 0, 0)},
+		{"restricted month with weekday", "0 0 * 2 1", utc(2025, 6, 1, 0, 0), utc(2026, 2, 2, 0, 0)},
+
+		{"@yearly", "@yearly", utc(2025, 6, 1, 0, 0), utc(2026, 1, 1, 0, 0)},
+		{"@annually", "@annually", utc(2025, 6, 1, 0, 0), utc(2026, 1, 1, 0, 0)},
+		{"@monthly", "@monthly", utc(2025, 6, 2, 0, 0), utc(2025, 7, 1, 0, 0)},
+		{"@weekly", "@weekly", utc(2025, 6, 2, 0, 0), utc(2025, 6, 8, 0, 0)},
+		{"@daily", "@daily", utc(2025, 6, 1, 13, 0), utc(2025, 6, 2, 0, 0)},
+		{"@midnight", "@midnight", utc(2025, 6, 1, 13, 0), utc(2025, 6, 2, 0, 0)},
+		{"@hourly", "@hourly", utc(2025, 6, 1, 10, 7), utc(2025, 6, 1, 11, 0)},
+		{"surrounding space", "  @hourly ", utc(2025, 6, 1, 10, 7), utc(2025, 6, 1, 11, 0)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := s.Next(tt.from); !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNextDST(t *testing.T) {
+	ny, err := time.LoadLocation("America/New_York")
+	if err != nil {
+		t.Fatal(err)
+	}
+	edt := time.FixedZone("EDT", -4*60*60)
+	est := time.FixedZone("EST", -5*60*60)
+
+	// On 2025-03-09 New York skips from 02:00 EST to 03:00 EDT, and on
+	// 2025-11-02 it repeats 01:00-02:00.
+	tests := []struct {
+		name string
+		expr string
+		from time.Time
+		want time.Time
+	}{
+		{"hourly over gap", "0 * * * *", time.Date(2025, 3, 9, 1, 30, 0, 0, est), time.Date(2025, 3, 9, 3, 0, 0, 0, edt)},
+		{"time in gap", "30 2 * * *", time.Date(2025, 3, 9, 0, 0, 0, 0, est), time.Date(2025, 3, 9, 3, 0, 0, 0, edt)},
+		{"time in gap next day", "30 2 * * *", time.Date(2025, 3, 9, 3, 0, 0, 0, edt), time.Date(2025, 3, 10, 2, 30, 0, 0, edt)},
+		{"after gap", "30 3 * * *", time.Date(2025, 3, 9, 0, 0, 0, 0, est), time.Date(2025, 3, 9, 3, 30, 0, 0, edt)},
+		{"repeated time", "30 1 * * *", time.Date(2025, 11, 2, 0, 0, 0, 0, edt), time.Date(2025, 11, 2, 1, 30, 0, 0, edt)},
+		{"repeated time once", "30 1 * * *", time.Date(2025, 11, 2, 1, 30, 0, 0, edt), time.Date(2025, 11, 3, 1, 30, 0, 0, est)},
+		{"from second occurrence", "30 1 * * *", time.Date(2025, 11, 2, 1, 10, 0, 0, est), time.Date(2025, 11, 3, 1, 30, 0, 0, est)},
+		{"hourly over repeat", "0 * * * *", time.Date(2025, 11, 2, 1, 0, 0, 0, edt), time.Date(2025, 11, 2, 2, 0, 0, 0, est)},
+		{"daily over repeat", "0 12 * * *", time.Date(2025, 11, 1, 12, 0, 0, 0, edt), time.Date(2025, 11, 2, 12, 0, 0, 0, est)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.expr)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := s.Next(tt.from.In(ny))
+			if !got.Equal(tt.want) {
+				t.Errorf("Next(%s) = %s, want %s", tt.from.In(ny), got, tt.want.In(ny))
+			}
+			if got.Location() != ny {
+				t.Errorf("Next returned a time in %s, want %s", got.Location(), ny)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"* * * *",
+		"* * * * * *",
+		"@every 5m",
+		"60 * * * *",
+		"* 24 * * *",
+		"* * 0 * *",
+		"* * 32 * *",
+		"* * * 0 *",
+		"* * * 13 *",
+		"* * * * 8",
+		"-1 * * * *",
+		"a * * * *",
+		"1-x * * * *",
+		"5-1 * * * *",
+		"*/0 * * * *",
+		"*/-5 * * * *",
+		"*/x * * * *",
+		"1,,2 * * * *",
+		"1-2-3 * * * *",
+	} {
+		if _, err := Parse(expr); err == nil {
+			t.Errorf("Parse(%q) succeeded, want an error", expr)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package email
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+const defaultCaptureCapacity = 200
+
+// CaptureTransport keeps the most recent messages in memory instead of
+// sending them. It backs local development and lets tests assert on the
+// emails a flow sent.
+type CaptureTransport struct {
+	mu       sync.RWMutex
+	capacity int
+	messages []Message
+}
+
+func NewCaptureTransport(capacity int) *CaptureTransport {
+	if capacity <= 0 {
+		capacity = defaultCaptureCapacity
+	}
+
+	return &CaptureTransport{capacity: capacity}
+}
+
+func (t *CaptureTransport) Send(_ context.Context, msg *Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = append(t.messages, *msg)
+	if len(t.messages) > t.capacity {
+		t.messages = t.messages[len(t.messages)-t.capacity:]
+	}
+
+	return nil
+}
+
+// Messages returns the captured messages, newest first.
+func (t *CaptureTransport) Messages() []Message {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	out := slices.Clone(t.messages)
+	slices.Reverse(out)
+	return out
+}
+
+// MessagesTo returns the captured messages sent to recipient, newest first.
+func (t *CaptureTransport) MessagesTo(recipient string) []Message {
+	return slices.DeleteFunc(t.Messages(), func(m Message) bool {
+		return m.To != recipient
+	})
+}
+
+func (t *CaptureTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.messages = nil
+}
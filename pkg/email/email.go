@@ -2,53 +2,88 @@ package email
 
 import (
 	"context"
-	"crypto/tls"
 	"fmt"
+	"net/mail"
+	"time"
 
 	"github.com/Jidetireni/ara-cooperative/internal/config"
-	"gopkg.in/gomail.v2"
 )
 
 type Email struct {
-	config *config.Config
-	cache  *EmailTemplateCache
+	config    *config.Config
+	cache     *EmailTemplateCache
+	from      string
+	transport EmailTransport
+	// mailbox is set when the capture transport is in use.
+	mailbox *CaptureTransport
 }
 
+// New picks the transport configured in cfg.Email.
 func New(cfg *config.Config) (*Email, error) {
-	cache, err := NewEmailTemplateCache(EmailDirectory, 10)
+	var transport EmailTransport
+	switch cfg.Email.Transport {
+	case config.EmailTransportSMTP:
+		transport = NewSMTPTransport(cfg.Email)
+	case config.EmailTransportMaildir:
+		maildir, err := NewMaildirTransport(cfg.Email.MaildirPath)
+		if err != nil {
+			return nil, err
+		}
+		transport = maildir
+	case config.EmailTransportCapture:
+		transport = NewCaptureTransport(defaultCaptureCapacity)
+	default:
+		return nil, fmt.Errorf("unknown email transport %q", cfg.Email.Transport)
+	}
+
+	return NewWithTransport(cfg, transport)
+}
+
+// NewWithTransport builds an Email around an explicit transport, typically a
+// CaptureTransport in tests.
+func NewWithTransport(cfg *config.Config, transport EmailTransport) (*Email, error) {
+	cache, err := NewEmailTemplateCache(cfg.Email.FromName)
 	if err != nil {
 		return nil, err
 	}
 
-	return &Email{
-		config: cfg,
-		cache:  cache,
-	}, nil
+	e := &Email{
+		config:    cfg,
+		cache:     cache,
+		from:      (&mail.Address{Name: cfg.Email.FromName, Address: cfg.Email.FromAddress}).String(),
+		transport: transport,
+	}
+	if capture, ok := transport.(*CaptureTransport); ok {
+		e.mailbox = capture
+	}
+
+	return e, nil
 }
 
 func (e *Email) Send(ctx context.Context, input *SendEmailInput) error {
-	// In dev mode
-	if e.config.IsDev {
-		fmt.Printf("--- Email to be sent to %s ---\n", input.To)
-		fmt.Printf("Subject: %s\n", input.Subject)
-		fmt.Println("Body:")
-		fmt.Println(input.Body)
-		fmt.Println("---------------------------------")
-		return nil
+	msg := &Message{
+		ID:       newMessageID(),
+		From:     e.from,
+		To:       input.To,
+		Subject:  input.Subject,
+		Body:     input.Body,
+		TextBody: input.TextBody,
+		SentAt:   time.Now(),
 	}
 
-	m := gomail.NewMessage()
-	m.SetHeader("From", ARAFromEmail)
-	m.SetHeader("To", input.To)
-	m.SetHeader("Subject", input.Subject)
-	m.SetBody("text/html", input.Body)
-
-	d := gomail.NewDialer(SMTPHost, SMTPPort, ARAFromEmail, e.config.Email.Password)
-	d.TLSConfig = &tls.Config{InsecureSkipVerify: true}
-
-	if err := d.DialAndSend(m); err != nil {
+	if err := e.transport.Send(ctx, msg); err != nil {
 		return fmt.Errorf("failed to send email: %w", err)
 	}
 
 	return nil
 }
+
+// Mailbox returns the in-memory mailbox, or nil unless the capture transport is configured.
+func (e *Email) Mailbox() *CaptureTransport {
+	return e.mailbox
+}
+
+// Render executes one of the email templates.
+func (e *Email) Render(name EmailTemplateType, data any) (*Rendered, error) {
+	return e.cache.Render(name, data)
+}
@@ -0,0 +1,40 @@
+package email
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// MaildirTransport writes every message into a maildir, so it can be opened
+// with any mail client (for example `mutt -f <dir>`).
+type MaildirTransport struct {
+	Dir string
+}
+
+func NewMaildirTransport(dir string) (*MaildirTransport, error) {
+	for _, sub := range []string{"tmp", "new", "cur"} {
+		if err := os.MkdirAll(filepath.Join(dir, sub), 0o755); err != nil {
+			return nil, fmt.Errorf("create maildir: %w", err)
+		}
+	}
+
+	return &MaildirTransport{Dir: dir}, nil
+}
+
+func (t *MaildirTransport) Send(_ context.Context, msg *Message) error {
+	raw, err := msg.MIME()
+	if err != nil {
+		return err
+	}
+
+	// Write to tmp/ and rename into new/ so readers never see a partial file.
+	name := fmt.Sprintf("%d.%s.ara", msg.SentAt.UnixNano(), msg.ID)
+	tmpPath := filepath.Join(t.Dir, "tmp", name)
+	if err := os.WriteFile(tmpPath, raw, 0o644); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, filepath.Join(t.Dir, "new", name))
+}
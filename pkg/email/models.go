@@ -3,17 +3,73 @@ package email
 type EmailTemplateType string
 
 const (
-	SMTPHost       = "smtp.gmail.com"
-	SMTPPort       = 587
-	ARAFromEmail   = "jtirenipraise@gmail.com"
-	EmailDirectory = "./email/templates"
-
 	EmailTemplateTypeWelcome       EmailTemplateType = "welcome"
 	EmailTemplateTypePasswordReset EmailTemplateType = "password_reset"
+	EmailTemplateTypeAccountLocked EmailTemplateType = "account_locked"
+	EmailTemplateTypeNotification  EmailTemplateType = "notification"
 )
 
+// TemplateTypes lists every template; each must have a .html and a .txt file.
+var TemplateTypes = []EmailTemplateType{
+	EmailTemplateTypeWelcome,
+	EmailTemplateTypePasswordReset,
+	EmailTemplateTypeAccountLocked,
+	EmailTemplateTypeNotification,
+}
+
+type WelcomeData struct {
+	FirstName        string
+	Code             string
+	ExpiresInMinutes int
+}
+
+type PasswordResetData struct {
+	ResetURL         string
+	ExpiresInMinutes int
+}
+
+type AccountLockedData struct {
+	LockMinutes    int
+	FailedAttempts int
+}
+
+// NotificationData is the email version of an in-app notification.
+type NotificationData struct {
+	FirstName   string
+	Title       string
+	Body        string
+	ActionURL   string
+	ActionLabel string
+}
+
+// TemplateSamples holds example data used to preview each template.
+var TemplateSamples = map[EmailTemplateType]any{
+	EmailTemplateTypeWelcome: WelcomeData{
+		FirstName:        "Ada",
+		Code:             "123456",
+		ExpiresInMinutes: 30,
+	},
+	EmailTemplateTypePasswordReset: PasswordResetData{
+		ResetURL:         "https://example.com/reset-password?token=preview",
+		ExpiresInMinutes: 30,
+	},
+	EmailTemplateTypeAccountLocked: AccountLockedData{
+		LockMinutes:    30,
+		FailedAttempts: 10,
+	},
+	EmailTemplateTypeNotification: NotificationData{
+		FirstName:   "Ada",
+		Title:       "You have been charged a fine",
+		Body:        "You have been charged a fine of NGN 2,000.00 for late attendance. Please pay it before 30 Nov 2026.",
+		ActionURL:   "https://example.com/fines",
+		ActionLabel: "View your fines",
+	},
+}
+
 type SendEmailInput struct {
 	To      string
 	Subject string
 	Body    string
+	// TextBody is the plain-text alternative of Body.
+	TextBody string
 }
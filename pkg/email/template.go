@@ -2,58 +2,98 @@ package email
 
 import (
 	"bytes"
+	"embed"
 	"fmt"
-	"html/template"
-	"path/filepath"
-	"sync"
-
-	lru "github.com/hashicorp/golang-lru"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
 )
 
-type EmailTemplateCache struct {
-	dir string
-	lru *lru.Cache // holds *template.Template
-	mu  sync.Mutex
+//go:embed templates
+var templateFS embed.FS
+
+// Rendered is a template executed into both message parts.
+type Rendered struct {
+	Subject string `json:"subject"`
+	HTML    string `json:"html"`
+	Text    string `json:"text"`
 }
 
-func NewEmailTemplateCache(dir string, capacity int) (*EmailTemplateCache, error) {
-	lruCache, err := lru.New(capacity)
-	if err != nil {
-		return nil, err
-	}
-	return &EmailTemplateCache{
-		dir: dir,
-		lru: lruCache,
-	}, nil
+type buttonData struct {
+	URL   string
+	Label string
 }
 
-func (c *EmailTemplateCache) Get(name string) (*template.Template, error) {
-	if v, ok := c.lru.Get(name); ok {
-		return v.(*template.Template), nil
+// EmailTemplateCache holds every email template, parsed once at startup.
+// Each template is a <name>.html file defining "content" and a <name>.txt
+// file defining "subject" and "content", rendered inside the shared layouts
+// with the partials in templates/partials.
+type EmailTemplateCache struct {
+	html map[EmailTemplateType]*htmltemplate.Template
+	text map[EmailTemplateType]*texttemplate.Template
+}
+
+func NewEmailTemplateCache(appName string) (*EmailTemplateCache, error) {
+	funcs := map[string]any{
+		"appName": func() string { return appName },
+		"button":  func(url, label string) buttonData { return buttonData{URL: url, Label: label} },
 	}
-	c.mu.Lock()
-	defer c.mu.Unlock()
-	// re-check under write lock
-	if v, ok := c.lru.Get(name); ok {
-		return v.(*template.Template), nil
+
+	c := &EmailTemplateCache{
+		html: make(map[EmailTemplateType]*htmltemplate.Template, len(TemplateTypes)),
+		text: make(map[EmailTemplateType]*texttemplate.Template, len(TemplateTypes)),
 	}
-	path := filepath.Join(c.dir, name)
-	tmpl, err := template.ParseFiles(path + ".html")
-	if err != nil {
-		return nil, fmt.Errorf("parse %s: %w", path, err)
+
+	for _, name := range TemplateTypes {
+		h, err := htmltemplate.New("layout.html").Funcs(funcs).ParseFS(templateFS,
+			"templates/layout.html",
+			"templates/partials/*.html",
+			"templates/"+string(name)+".html",
+		)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s.html: %w", name, err)
+		}
+
+		t, err := texttemplate.New("layout.txt").Funcs(funcs).ParseFS(templateFS,
+			"templates/layout.txt",
+			"templates/partials/*.txt",
+			"templates/"+string(name)+".txt",
+		)
+		if err != nil {
+			return nil, fmt.Errorf("parse %s.txt: %w", name, err)
+		}
+		if t.Lookup("subject") == nil {
+			return nil, fmt.Errorf("template %s.txt does not define a subject", name)
+		}
+
+		c.html[name] = h
+		c.text[name] = t
 	}
-	c.lru.Add(name, tmpl)
-	return tmpl, nil
+
+	return c, nil
 }
 
-func (c *EmailTemplateCache) Render(name EmailTemplateType, data any) (string, error) {
-	tmpl, err := c.Get(string(name))
-	if err != nil {
-		return "", err
+func (c *EmailTemplateCache) Render(name EmailTemplateType, data any) (*Rendered, error) {
+	h, ok := c.html[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown email template %q", name)
 	}
-	var buf bytes.Buffer
-	if err := tmpl.Execute(&buf, data); err != nil {
-		return "", fmt.Errorf("exec %s: %w", name, err)
+	t := c.text[name]
+
+	var subject, text, html bytes.Buffer
+	if err := t.ExecuteTemplate(&subject, "subject", data); err != nil {
+		return nil, fmt.Errorf("exec %s subject: %w", name, err)
 	}
-	return buf.String(), nil
+	if err := t.Execute(&text, data); err != nil {
+		return nil, fmt.Errorf("exec %s.txt: %w", name, err)
+	}
+	if err := h.Execute(&html, data); err != nil {
+		return nil, fmt.Errorf("exec %s.html: %w", name, err)
+	}
+
+	return &Rendered{
+		Subject: strings.TrimSpace(subject.String()),
+		HTML:    html.String(),
+		Text:    strings.TrimSpace(text.String()) + "\n",
+	}, nil
 }
@@ -0,0 +1,59 @@
+package email
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"gopkg.in/gomail.v2"
+)
+
+// EmailTransport delivers a fully addressed message.
+type EmailTransport interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+// Message is an email as handed to a transport.
+type Message struct {
+	ID      string `json:"id"`
+	From    string `json:"from"`
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	// TextBody is sent as the plain-text alternative of the HTML Body.
+	TextBody string    `json:"text_body,omitempty"`
+	SentAt   time.Time `json:"sent_at"`
+}
+
+func newMessageID() string {
+	b := make([]byte, 12)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// MIME renders the message in RFC 5322 format.
+func (m *Message) MIME() ([]byte, error) {
+	gm := gomail.NewMessage()
+	gm.SetHeader("From", m.From)
+	gm.SetHeader("To", m.To)
+	gm.SetHeader("Subject", m.Subject)
+	gm.SetHeader("Message-ID", fmt.Sprintf("<%s@ara-cooperative>", m.ID))
+	gm.SetDateHeader("Date", m.SentAt)
+	if m.TextBody != "" {
+		// multipart/alternative: clients pick the last part they can display.
+		gm.SetBody("text/plain", m.TextBody)
+		gm.AddAlternative("text/html", m.Body)
+	} else {
+		gm.SetBody("text/html", m.Body)
+	}
+
+	var buf bytes.Buffer
+	if _, err := gm.WriteTo(&buf); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
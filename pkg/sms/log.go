@@ -0,0 +1,45 @@
+package sms
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// LogProvider appends every message as a JSON line to a file instead of
+// sending it, e.g. `tail -f tmp/sms.log` during development.
+type LogProvider struct {
+	Path string
+
+	mu sync.Mutex
+}
+
+func NewLogProvider(path string) (*LogProvider, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create sms log directory: %w", err)
+	}
+
+	return &LogProvider{Path: path}, nil
+}
+
+func (l *LogProvider) Send(_ context.Context, msg *Message) error {
+	line, err := json.Marshal(msg)
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	f, err := os.OpenFile(l.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(line, '\n'))
+	return err
+}
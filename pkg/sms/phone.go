@@ -0,0 +1,60 @@
+package sms
+
+import (
+	"errors"
+	"strings"
+)
+
+// DefaultCountryCode is assumed for numbers written in national format.
+const DefaultCountryCode = "234"
+
+var ErrInvalidPhone = errors.New("invalid phone number")
+
+// NormalizePhone converts a phone number to E.164, e.g. "0803 123 4567" and
+// "2348031234567" both become "+2348031234567". Numbers without a country code
+// are taken to be Nigerian.
+func NormalizePhone(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+
+	international := strings.HasPrefix(raw, "+") || strings.HasPrefix(raw, "00")
+	var digits strings.Builder
+	for _, r := range raw {
+		switch {
+		case r >= '0' && r <= '9':
+			digits.WriteRune(r)
+		case r == '+' || r == ' ' || r == '-' || r == '.' || r == '(' || r == ')':
+		default:
+			return "", ErrInvalidPhone
+		}
+	}
+	number := digits.String()
+
+	switch {
+	case strings.HasPrefix(raw, "00"):
+		number = strings.TrimPrefix(number, "00")
+	case international:
+	case strings.HasPrefix(number, DefaultCountryCode) && len(number) == len(DefaultCountryCode)+10:
+	case strings.HasPrefix(number, "0") && len(number) == 11:
+		number = DefaultCountryCode + number[1:]
+	case len(number) == 10:
+		number = DefaultCountryCode + number
+	default:
+		return "", ErrInvalidPhone
+	}
+
+	// Nigerian subscriber numbers have ten digits after the country code and
+	// never start with a trunk zero.
+	if strings.HasPrefix(number, DefaultCountryCode) {
+		national := strings.TrimPrefix(number, DefaultCountryCode)
+		if len(national) != 10 || national[0] == '0' {
+			return "", ErrInvalidPhone
+		}
+	}
+
+	// E.164 allows at most 15 digits; shorter than 8 is no real number.
+	if len(number) < 8 || len(number) > 15 || number[0] == '0' {
+		return "", ErrInvalidPhone
+	}
+
+	return "+" + number, nil
+}
@@ -0,0 +1,70 @@
+package sms
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+)
+
+// Provider delivers a text message.
+type Provider interface {
+	Send(ctx context.Context, msg *Message) error
+}
+
+type Message struct {
+	From   string    `json:"from"`
+	To     string    `json:"to"`
+	Body   string    `json:"body"`
+	SentAt time.Time `json:"sent_at"`
+}
+
+type SMS struct {
+	senderID string
+	provider Provider
+}
+
+// New picks the provider configured in cfg.SMS.
+func New(cfg *config.Config) (*SMS, error) {
+	var provider Provider
+	switch cfg.SMS.Provider {
+	case config.SMSProviderTermii:
+		provider = NewTermiiProvider(cfg.SMS)
+	case config.SMSProviderLog:
+		logProvider, err := NewLogProvider(cfg.SMS.LogPath)
+		if err != nil {
+			return nil, err
+		}
+		provider = logProvider
+	default:
+		return nil, fmt.Errorf("unknown sms provider %q", cfg.SMS.Provider)
+	}
+
+	return NewWithProvider(cfg, provider), nil
+}
+
+// NewWithProvider builds an SMS around an explicit provider, typically a
+// LogProvider in tests.
+func NewWithProvider(cfg *config.Config, provider Provider) *SMS {
+	return &SMS{
+		senderID: cfg.SMS.SenderID,
+		provider: provider,
+	}
+}
+
+// Send delivers body to the E.164 number to.
+func (s *SMS) Send(ctx context.Context, to, body string) error {
+	msg := &Message{
+		From:   s.senderID,
+		To:     to,
+		Body:   body,
+		SentAt: time.Now(),
+	}
+
+	if err := s.provider.Send(ctx, msg); err != nil {
+		return fmt.Errorf("failed to send sms: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,94 @@
+package sms
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+)
+
+const termiiTimeout = time.Second * 15
+
+// TermiiProvider sends messages through the Termii API, which routes to all
+// Nigerian networks.
+type TermiiProvider struct {
+	apiKey  string
+	baseURL string
+	channel string
+	client  *http.Client
+}
+
+func NewTermiiProvider(cfg config.SMSConfig) *TermiiProvider {
+	return &TermiiProvider{
+		apiKey:  cfg.APIKey,
+		baseURL: strings.TrimRight(cfg.BaseURL, "/"),
+		channel: cfg.Channel,
+		client:  &http.Client{Timeout: termiiTimeout},
+	}
+}
+
+type termiiSendRequest struct {
+	To      string `json:"to"`
+	From    string `json:"from"`
+	SMS     string `json:"sms"`
+	Type    string `json:"type"`
+	Channel string `json:"channel"`
+	APIKey  string `json:"api_key"`
+}
+
+type termiiSendResponse struct {
+	MessageID string `json:"message_id"`
+	Message   string `json:"message"`
+}
+
+func (t *TermiiProvider) Send(ctx context.Context, msg *Message) error {
+	payload, err := json.Marshal(termiiSendRequest{
+		// Termii expects the international number without the plus sign.
+		To:      strings.TrimPrefix(msg.To, "+"),
+		From:    msg.From,
+		SMS:     msg.Body,
+		Type:    "plain",
+		Channel: t.channel,
+		APIKey:  t.apiKey,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.baseURL+"/api/sms/send", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("termii returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result termiiSendResponse
+	if err := json.Unmarshal(body, &result); err != nil {
+		return fmt.Errorf("decode termii response: %w", err)
+	}
+	if result.MessageID == "" {
+		return fmt.Errorf("termii did not accept the message: %s", result.Message)
+	}
+
+	return nil
+}
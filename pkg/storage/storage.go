@@ -0,0 +1,55 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/Jidetireni/ara-cooperative/internal/config"
+	"github.com/Jidetireni/ara-cooperative/internal/helpers"
+)
+
+// ErrInvalidKey is returned for keys that are empty, absolute or climb out of
+// their directory.
+var ErrInvalidKey = errors.New("invalid storage key")
+
+// Storage keeps uploaded files. Keys are slash separated paths such as
+// "members/<id>/id_card/<id>.pdf". Files are never served directly; readers
+// get a signed URL that stops working after a while.
+type Storage interface {
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, expiry time.Duration) (string, error)
+}
+
+// New picks the backend configured in cfg.Storage.
+func New(cfg *config.Config) (Storage, error) {
+	switch cfg.Storage.Backend {
+	case config.StorageBackendLocal:
+		local, err := NewLocal(cfg.Storage.LocalDir, cfg.Storage.PublicURL, helpers.DeriveKey(cfg.Storage.SigningKey))
+		if err != nil {
+			return nil, err
+		}
+		return local, nil
+	case config.StorageBackendS3:
+		return NewS3(cfg.Storage), nil
+	default:
+		return nil, fmt.Errorf("unknown storage backend %q", cfg.Storage.Backend)
+	}
+}
+
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, part := range strings.Split(key, "/") {
+		if part == "" || part == "." || part == ".." {
+			return ErrInvalidKey
+		}
+	}
+
+	return nil
+}
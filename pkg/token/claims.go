@@ -12,6 +12,13 @@ type UserClaims struct {
 	Email       string    `json:"email"`
 	Roles       []string  `json:"roles"`
 	Permissions []string  `json:"scopes"`
+	Version     int64     `json:"ver"`
+	MFA         bool      `json:"mfa,omitempty"`
+	// MemberStatus is the member's lifecycle state when the token was issued.
+	// Sessions are revoked whenever it changes.
+	MemberStatus string `json:"member_status,omitempty"`
+	// Use is TokenUseAccess or TokenUseRefresh.
+	Use string `json:"typ"`
 	jwt.RegisteredClaims
 }
 
@@ -22,10 +29,14 @@ func newUserClaims(params *CreatetokenParams) (*UserClaims, error) {
 	}
 
 	return &UserClaims{
-		ID:          params.ID,
-		Email:       params.Email,
-		Roles:       params.Roles,
-		Permissions: params.Permissions,
+		ID:           params.ID,
+		Email:        params.Email,
+		Roles:        params.Roles,
+		Permissions:  params.Permissions,
+		Version:      params.Version,
+		MFA:          params.MFA,
+		MemberStatus: params.MemberStatus,
+		Use:          params.Use,
 		RegisteredClaims: jwt.RegisteredClaims{
 			ID:        tokenID.String(),
 			Subject:   params.Email,
@@ -8,14 +8,14 @@ import (
 )
 
 type Jwt struct {
-	SecretKey string
-	IsDev     bool
+	Keys  *KeySet
+	IsDev bool
 }
 
-func NewJwt(secretKey string, isDev bool) *Jwt {
+func NewJwt(keys *KeySet, isDev bool) *Jwt {
 	return &Jwt{
-		SecretKey: secretKey,
-		IsDev:     isDev,
+		Keys:  keys,
+		IsDev: isDev,
 	}
 }
 
@@ -25,8 +25,14 @@ func (j *Jwt) createToken(params *CreatetokenParams) (string, *UserClaims, error
 		return "", nil, err
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.SecretKey))
+	key, err := j.Keys.SigningKey()
+	if err != nil {
+		return "", nil, err
+	}
+
+	token := jwt.NewWithClaims(key.method(), claims)
+	token.Header["kid"] = key.ID
+	tokenString, err := token.SignedString(key.signingKey)
 	if err != nil {
 		return "", nil, err
 	}
@@ -36,11 +42,21 @@ func (j *Jwt) createToken(params *CreatetokenParams) (string, *UserClaims, error
 
 func (j *Jwt) ValidateToken(tokenString string) (*UserClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &UserClaims{}, func(token *jwt.Token) (any, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		kid, _ := token.Header["kid"].(string)
+		key, err := j.Keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+
+		if token.Method.Alg() != string(key.Algorithm) {
 			return nil, fmt.Errorf("invalid token singing method")
 		}
-		return []byte(j.SecretKey), nil
-	})
+		return key.verifyKey, nil
+	}, jwt.WithValidMethods([]string{
+		string(SigningAlgorithmHS256),
+		string(SigningAlgorithmRS256),
+		string(SigningAlgorithmEdDSA),
+	}))
 
 	if err != nil {
 		return nil, err
@@ -61,11 +77,15 @@ func (j *Jwt) GenerateTokenPair(params *TokenPairParams) (*TokenPair, error) {
 	}
 
 	accessToken, _, err := j.createToken(&CreatetokenParams{
-		ID:          params.ID,
-		Email:       params.Email,
-		Roles:       params.Roles,
-		Permissions: params.Permissions,
-		Duration:    accessExpiry,
+		ID:           params.ID,
+		Email:        params.Email,
+		Roles:        params.Roles,
+		Permissions:  params.Permissions,
+		Version:      params.Version,
+		MFA:          params.MFA,
+		MemberStatus: params.MemberStatus,
+		Use:          TokenUseAccess,
+		Duration:     accessExpiry,
 	})
 	if err != nil {
 		return nil, err
@@ -73,11 +93,15 @@ func (j *Jwt) GenerateTokenPair(params *TokenPairParams) (*TokenPair, error) {
 
 	refreshExpiry := RefreshTokenExpirationTime
 	refreshToken, _, err := j.createToken(&CreatetokenParams{
-		ID:          params.ID,
-		Email:       params.Email,
-		Roles:       params.Roles,
-		Permissions: params.Permissions,
-		Duration:    refreshExpiry,
+		ID:           params.ID,
+		Email:        params.Email,
+		Roles:        params.Roles,
+		Permissions:  params.Permissions,
+		Version:      params.Version,
+		MFA:          params.MFA,
+		MemberStatus: params.MemberStatus,
+		Use:          TokenUseRefresh,
+		Duration:     refreshExpiry,
 	})
 	if err != nil {
 		return nil, err
@@ -88,3 +112,9 @@ func (j *Jwt) GenerateTokenPair(params *TokenPairParams) (*TokenPair, error) {
 		RefreshToken: refreshToken,
 	}, nil
 }
+
+// JWKS returns the public keys other services need to verify our tokens.
+// They must also check that the typ claim is TokenUseAccess.
+func (j *Jwt) JWKS() JWKS {
+	return j.Keys.JWKS()
+}
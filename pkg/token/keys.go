@@ -0,0 +1,308 @@
+package token
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+type SigningAlgorithm string
+
+const (
+	SigningAlgorithmHS256 SigningAlgorithm = "HS256"
+	SigningAlgorithmRS256 SigningAlgorithm = "RS256"
+	SigningAlgorithmEdDSA SigningAlgorithm = "EdDSA"
+
+	// LegacyKeyID identifies the shared JWT_SECRET. Tokens minted before key
+	// rotation was introduced carry no kid and are verified with this key.
+	LegacyKeyID = "hs256-legacy"
+
+	// legacyKeyGracePeriod covers the longest access token lifetime (dev mode).
+	legacyKeyGracePeriod = time.Hour * 24
+)
+
+var (
+	ErrNoSigningKey = errors.New("no active signing key")
+	ErrUnknownKey   = errors.New("unknown signing key")
+)
+
+// Key is a single entry of a KeySet. A key signs new tokens from ActiveFrom
+// until a newer key becomes active, and verifies tokens until VerifyUntil.
+type Key struct {
+	ID          string
+	Algorithm   SigningAlgorithm
+	ActiveFrom  time.Time
+	VerifyUntil time.Time
+
+	signingKey any
+	verifyKey  any
+}
+
+func (k *Key) method() jwt.SigningMethod {
+	switch k.Algorithm {
+	case SigningAlgorithmRS256:
+		return jwt.SigningMethodRS256
+	case SigningAlgorithmEdDSA:
+		return jwt.SigningMethodEdDSA
+	default:
+		return jwt.SigningMethodHS256
+	}
+}
+
+func (k *Key) canVerifyAt(t time.Time) bool {
+	return k.VerifyUntil.IsZero() || t.Before(k.VerifyUntil)
+}
+
+// KeySet holds every key the service signs or verifies tokens with.
+type KeySet struct {
+	keys []*Key
+	now  func() time.Time
+}
+
+// keyManifest is the on-disk description of the asymmetric keys, pointed to by
+// JWT_KEYS_FILE. Private key paths are resolved relative to the manifest.
+//
+//	{"keys": [{"kid": "2026-10", "alg": "EdDSA", "private_key_file": "2026-10.pem",
+//	           "active_from": "2026-10-01T00:00:00Z", "verify_until": ""}]}
+type keyManifest struct {
+	Keys []struct {
+		ID             string           `json:"kid"`
+		Algorithm      SigningAlgorithm `json:"alg"`
+		PrivateKeyFile string           `json:"private_key_file"`
+		ActiveFrom     time.Time        `json:"active_from"`
+		VerifyUntil    *time.Time       `json:"verify_until,omitempty"`
+	} `json:"keys"`
+}
+
+// NewKeySet builds the key set from the shared secret and, when keysFile is
+// set, the asymmetric keys listed in the manifest. The shared secret only
+// signs tokens while no asymmetric key is active, but keeps verifying so that
+// sessions issued before a migration survive until they expire.
+func NewKeySet(secret string, keysFile string) (*KeySet, error) {
+	ks := &KeySet{now: time.Now}
+
+	if secret != "" {
+		ks.keys = append(ks.keys, &Key{
+			ID:         LegacyKeyID,
+			Algorithm:  SigningAlgorithmHS256,
+			signingKey: []byte(secret),
+			verifyKey:  []byte(secret),
+		})
+	}
+
+	if keysFile != "" {
+		keys, err := loadManifest(keysFile)
+		if err != nil {
+			return nil, err
+		}
+
+		// Once the first asymmetric key takes over, the shared secret only
+		// has to outlive the access tokens it already signed, none of which
+		// were signed after that key's ActiveFrom. The window is anchored to
+		// the manifest, not to this process starting, so restarts do not
+		// reopen it and the secret can be removed once it has passed.
+		if len(keys) > 0 && len(ks.keys) > 0 {
+			first := keys[0].ActiveFrom
+			for _, k := range keys[1:] {
+				if k.ActiveFrom.Before(first) {
+					first = k.ActiveFrom
+				}
+			}
+			ks.keys[0].VerifyUntil = first.Add(legacyKeyGracePeriod)
+		}
+
+		ks.keys = append(ks.keys, keys...)
+	}
+
+	if len(ks.keys) == 0 {
+		return nil, ErrNoSigningKey
+	}
+
+	seen := map[string]bool{}
+	for _, k := range ks.keys {
+		if seen[k.ID] {
+			return nil, fmt.Errorf("duplicate key id %q", k.ID)
+		}
+		seen[k.ID] = true
+	}
+
+	// Newest first, so SigningKey picks the most recently activated key.
+	sort.SliceStable(ks.keys, func(i, j int) bool {
+		return ks.keys[i].ActiveFrom.After(ks.keys[j].ActiveFrom)
+	})
+
+	return ks, nil
+}
+
+func loadManifest(path string) ([]*Key, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read jwt keys file: %w", err)
+	}
+
+	var manifest keyManifest
+	if err := json.Unmarshal(raw, &manifest); err != nil {
+		return nil, fmt.Errorf("parse jwt keys file: %w", err)
+	}
+
+	keys := make([]*Key, 0, len(manifest.Keys))
+	for _, entry := range manifest.Keys {
+		if entry.ID == "" {
+			return nil, errors.New("jwt key is missing a kid")
+		}
+
+		keyPath := entry.PrivateKeyFile
+		if !filepath.IsAbs(keyPath) {
+			keyPath = filepath.Join(filepath.Dir(path), keyPath)
+		}
+
+		private, err := parsePrivateKey(keyPath)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", entry.ID, err)
+		}
+
+		key := &Key{
+			ID:         entry.ID,
+			Algorithm:  entry.Algorithm,
+			ActiveFrom: entry.ActiveFrom,
+			signingKey: private,
+		}
+		if entry.VerifyUntil != nil {
+			key.VerifyUntil = *entry.VerifyUntil
+		}
+
+		switch pk := private.(type) {
+		case *rsa.PrivateKey:
+			if entry.Algorithm != SigningAlgorithmRS256 {
+				return nil, fmt.Errorf("key %s: RSA key cannot be used with %s", entry.ID, entry.Algorithm)
+			}
+			key.verifyKey = &pk.PublicKey
+		case ed25519.PrivateKey:
+			if entry.Algorithm != SigningAlgorithmEdDSA {
+				return nil, fmt.Errorf("key %s: Ed25519 key cannot be used with %s", entry.ID, entry.Algorithm)
+			}
+			key.verifyKey = pk.Public()
+		default:
+			return nil, fmt.Errorf("key %s: unsupported private key type %T", entry.ID, private)
+		}
+
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+func parsePrivateKey(path string) (crypto.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode(raw)
+	if block == nil {
+		return nil, errors.New("no PEM block found")
+	}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		return x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		return x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("unsupported PEM block type %q", block.Type)
+	}
+}
+
+// SigningKey returns the most recently activated key.
+func (ks *KeySet) SigningKey() (*Key, error) {
+	now := ks.now()
+	for _, k := range ks.keys {
+		if !k.ActiveFrom.After(now) && k.canVerifyAt(now) {
+			return k, nil
+		}
+	}
+
+	return nil, ErrNoSigningKey
+}
+
+// VerificationKey looks up the key a token claims to be signed with.
+func (ks *KeySet) VerificationKey(kid string) (*Key, error) {
+	if kid == "" {
+		kid = LegacyKeyID
+	}
+
+	now := ks.now()
+	for _, k := range ks.keys {
+		if k.ID == kid && k.canVerifyAt(now) {
+			return k, nil
+		}
+	}
+
+	return nil, ErrUnknownKey
+}
+
+type JWK struct {
+	KeyType   string `json:"kty"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+	Use       string `json:"use"`
+	Curve     string `json:"crv,omitempty"`
+	X         string `json:"x,omitempty"`
+	N         string `json:"n,omitempty"`
+	E         string `json:"e,omitempty"`
+}
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS publishes the public half of every asymmetric key that can still verify
+// tokens, including keys scheduled for future activation so that verifiers
+// can cache them ahead of a rotation. Shared secrets are never published.
+func (ks *KeySet) JWKS() JWKS {
+	now := ks.now()
+	set := JWKS{Keys: []JWK{}}
+
+	for _, k := range ks.keys {
+		if !k.canVerifyAt(now) {
+			continue
+		}
+
+		enc := base64.RawURLEncoding
+		switch pub := k.verifyKey.(type) {
+		case *rsa.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "RSA",
+				KeyID:     k.ID,
+				Algorithm: string(k.Algorithm),
+				Use:       "sig",
+				N:         enc.EncodeToString(pub.N.Bytes()),
+				E:         enc.EncodeToString(big.NewInt(int64(pub.E)).Bytes()),
+			})
+		case ed25519.PublicKey:
+			set.Keys = append(set.Keys, JWK{
+				KeyType:   "OKP",
+				KeyID:     k.ID,
+				Algorithm: string(k.Algorithm),
+				Use:       "sig",
+				Curve:     "Ed25519",
+				X:         enc.EncodeToString(pub),
+			})
+		}
+	}
+
+	return set
+}
@@ -0,0 +1,385 @@
+package token
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+const testSecret = "legacy-secret"
+
+type testKey struct {
+	ID          string
+	Algorithm   SigningAlgorithm
+	ActiveFrom  time.Time
+	VerifyUntil *time.Time
+}
+
+// writeManifest generates a private key for each entry and writes them with
+// a manifest to a temporary directory, returning the manifest path.
+func writeManifest(t *testing.T, keys ...testKey) string {
+	t.Helper()
+	dir := t.TempDir()
+
+	type entry struct {
+		ID             string           `json:"kid"`
+		Algorithm      SigningAlgorithm `json:"alg"`
+		PrivateKeyFile string           `json:"private_key_file"`
+		ActiveFrom     time.Time        `json:"active_from"`
+		VerifyUntil    *time.Time       `json:"verify_until,omitempty"`
+	}
+	var manifest struct {
+		Keys []entry `json:"keys"`
+	}
+
+	for _, k := range keys {
+		var private any
+		var err error
+		switch k.Algorithm {
+		case SigningAlgorithmRS256:
+			private, err = rsa.GenerateKey(rand.Reader, 2048)
+		default:
+			_, private, err = ed25519.GenerateKey(rand.Reader)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		der, err := x509.MarshalPKCS8PrivateKey(private)
+		if err != nil {
+			t.Fatal(err)
+		}
+		file := k.ID + ".pem"
+		if err := os.WriteFile(filepath.Join(dir, file), pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}), 0o600); err != nil {
+			t.Fatal(err)
+		}
+
+		manifest.Keys = append(manifest.Keys, entry{k.ID, k.Algorithm, file, k.ActiveFrom, k.VerifyUntil})
+	}
+
+	raw, err := json.Marshal(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(dir, "keys.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func newTestKeySet(t *testing.T, secret string, keys ...testKey) *KeySet {
+	t.Helper()
+
+	var path string
+	if len(keys) > 0 {
+		path = writeManifest(t, keys...)
+	}
+	ks, err := NewKeySet(secret, path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return ks
+}
+
+func at(ks *KeySet, now time.Time) {
+	ks.now = func() time.Time { return now }
+}
+
+func newTestPair(t *testing.T, j *Jwt) *TokenPair {
+	t.Helper()
+
+	pair, err := j.GenerateTokenPair(&TokenPairParams{ID: uuid.New(), Email: "ada@example.com", Roles: []string{"member"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return pair
+}
+
+func tokenKeyID(t *testing.T, tokenString string) string {
+	t.Helper()
+
+	token, _, err := jwt.NewParser().ParseUnverified(tokenString, &UserClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid, _ := token.Header["kid"].(string)
+
+	return kid
+}
+
+func TestSigningKeyRotation(t *testing.T) {
+	now := time.Now()
+	ks := newTestKeySet(t, testSecret,
+		testKey{ID: "2026-09", Algorithm: SigningAlgorithmRS256, ActiveFrom: now.Add(-30 * 24 * time.Hour)},
+		testKey{ID: "2026-10", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now.Add(24 * time.Hour)},
+	)
+
+	tests := []struct {
+		name string
+		now  time.Time
+		want string
+	}{
+		{"before any manifest key", now.Add(-60 * 24 * time.Hour), LegacyKeyID},
+		{"current key", now, "2026-09"},
+		{"just before the rotation", now.Add(24*time.Hour - time.Second), "2026-09"},
+		{"at the rotation", now.Add(24 * time.Hour), "2026-10"},
+		{"after the rotation", now.Add(48 * time.Hour), "2026-10"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			at(ks, tt.now)
+			key, err := ks.SigningKey()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if key.ID != tt.want {
+				t.Errorf("SigningKey() = %s, want %s", key.ID, tt.want)
+			}
+		})
+	}
+}
+
+func TestSigningKeyWithoutManifest(t *testing.T) {
+	ks := newTestKeySet(t, testSecret)
+	key, err := ks.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.ID != LegacyKeyID || key.Algorithm != SigningAlgorithmHS256 {
+		t.Errorf("SigningKey() = %s %s, want the legacy HS256 key", key.ID, key.Algorithm)
+	}
+}
+
+func TestRetiredKey(t *testing.T) {
+	now := time.Now()
+	retired := now.Add(-time.Hour)
+	ks := newTestKeySet(t, "",
+		testKey{ID: "old", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now.Add(-60 * 24 * time.Hour)},
+		testKey{ID: "retired", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now.Add(-2 * time.Hour), VerifyUntil: &retired},
+	)
+
+	key, err := ks.SigningKey()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if key.ID != "old" {
+		t.Errorf("SigningKey() = %s, want the newest key that has not expired", key.ID)
+	}
+
+	if _, err := ks.VerificationKey("retired"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("VerificationKey(retired) returned %v, want ErrUnknownKey", err)
+	}
+
+	at(ks, retired.Add(-time.Minute))
+	if _, err := ks.VerificationKey("retired"); err != nil {
+		t.Errorf("VerificationKey(retired) before it expired: %v", err)
+	}
+}
+
+func TestVerificationKey(t *testing.T) {
+	now := time.Now()
+	ks := newTestKeySet(t, testSecret,
+		testKey{ID: "2026-10", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now.Add(-time.Hour)},
+	)
+
+	for kid, want := range map[string]string{"": LegacyKeyID, LegacyKeyID: LegacyKeyID, "2026-10": "2026-10"} {
+		key, err := ks.VerificationKey(kid)
+		if err != nil {
+			t.Fatalf("VerificationKey(%q): %v", kid, err)
+		}
+		if key.ID != want {
+			t.Errorf("VerificationKey(%q) = %s, want %s", kid, key.ID, want)
+		}
+	}
+
+	if _, err := ks.VerificationKey("unknown"); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("VerificationKey(unknown) returned %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestTokensCarryKeyIDAndVerify(t *testing.T) {
+	for _, alg := range []SigningAlgorithm{SigningAlgorithmRS256, SigningAlgorithmEdDSA} {
+		t.Run(string(alg), func(t *testing.T) {
+			ks := newTestKeySet(t, testSecret, testKey{ID: "current", Algorithm: alg, ActiveFrom: time.Now().Add(-time.Hour)})
+			j := NewJwt(ks, false)
+			pair := newTestPair(t, j)
+
+			if kid := tokenKeyID(t, pair.AccessToken); kid != "current" {
+				t.Errorf("access token kid = %q, want current", kid)
+			}
+
+			claims, err := j.ValidateToken(pair.AccessToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Use != TokenUseAccess {
+				t.Errorf("access token typ = %q, want %q", claims.Use, TokenUseAccess)
+			}
+
+			claims, err = j.ValidateToken(pair.RefreshToken)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if claims.Use != TokenUseRefresh {
+				t.Errorf("refresh token typ = %q, want %q", claims.Use, TokenUseRefresh)
+			}
+		})
+	}
+}
+
+func TestLegacyGraceWindow(t *testing.T) {
+	now := time.Now()
+	legacy := NewJwt(newTestKeySet(t, testSecret), false)
+	pair := newTestPair(t, legacy)
+
+	// The manifest key took over an hour ago, so the secret verifies for
+	// another 23 hours.
+	ks := newTestKeySet(t, testSecret, testKey{ID: "2026-10", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now.Add(-time.Hour)})
+	j := NewJwt(ks, false)
+
+	if _, err := j.ValidateToken(pair.AccessToken); err != nil {
+		t.Errorf("HS256 token rejected within the grace window: %v", err)
+	}
+
+	// A token minted before kids were introduced has no kid header.
+	claims, err := newUserClaims(&CreatetokenParams{ID: uuid.New(), Use: TokenUseAccess, Duration: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	noKid, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.ValidateToken(noKid); err != nil {
+		t.Errorf("HS256 token without kid rejected within the grace window: %v", err)
+	}
+
+	at(ks, now.Add(23*time.Hour+time.Minute))
+	for name, tokenString := range map[string]string{"with kid": pair.AccessToken, "without kid": noKid} {
+		if _, err := j.ValidateToken(tokenString); !errors.Is(err, ErrUnknownKey) {
+			t.Errorf("HS256 token %s after the grace window returned %v, want ErrUnknownKey", name, err)
+		}
+	}
+
+	// Restarting does not reopen the window.
+	restarted := NewJwt(newTestKeySet(t, testSecret, testKey{ID: "2026-10", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now.Add(-25 * time.Hour)}), false)
+	if _, err := restarted.ValidateToken(pair.AccessToken); !errors.Is(err, ErrUnknownKey) {
+		t.Errorf("HS256 token after a restart past the grace window returned %v, want ErrUnknownKey", err)
+	}
+}
+
+func TestAlgorithmMustMatchKey(t *testing.T) {
+	ks := newTestKeySet(t, testSecret, testKey{ID: "2026-10", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: time.Now().Add(-time.Hour)})
+	j := NewJwt(ks, false)
+
+	claims, err := newUserClaims(&CreatetokenParams{ID: uuid.New(), Use: TokenUseAccess, Duration: time.Minute})
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	forged.Header["kid"] = "2026-10"
+	tokenString, err := forged.SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := j.ValidateToken(tokenString); err == nil {
+		t.Error("accepted an HS256 token claiming an EdDSA kid")
+	}
+}
+
+func TestJWKS(t *testing.T) {
+	now := time.Now()
+	retired := now.Add(-time.Hour)
+	ks := newTestKeySet(t, testSecret,
+		testKey{ID: "rsa", Algorithm: SigningAlgorithmRS256, ActiveFrom: now.Add(-30 * 24 * time.Hour)},
+		testKey{ID: "retired", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now.Add(-60 * 24 * time.Hour), VerifyUntil: &retired},
+		testKey{ID: "next", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now.Add(24 * time.Hour)},
+	)
+
+	set := ks.JWKS()
+	published := map[string]JWK{}
+	for _, k := range set.Keys {
+		published[k.KeyID] = k
+	}
+	if len(published) != 2 {
+		t.Fatalf("published %d keys, want rsa and next", len(set.Keys))
+	}
+	if _, ok := published[LegacyKeyID]; ok {
+		t.Error("published the shared secret")
+	}
+
+	rsaKey, ok := published["rsa"]
+	if !ok {
+		t.Fatal("current RSA key is not published")
+	}
+	signing, _ := ks.VerificationKey("rsa")
+	pub := signing.verifyKey.(*rsa.PublicKey)
+	if rsaKey.KeyType != "RSA" || rsaKey.Algorithm != "RS256" || rsaKey.Use != "sig" ||
+		rsaKey.N != base64.RawURLEncoding.EncodeToString(pub.N.Bytes()) || rsaKey.E != "AQAB" {
+		t.Errorf("RSA JWK = %+v", rsaKey)
+	}
+
+	next, ok := published["next"]
+	if !ok {
+		t.Fatal("key scheduled for the next rotation is not published")
+	}
+	x, err := base64.RawURLEncoding.DecodeString(next.X)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signing, _ = ks.VerificationKey("next")
+	if next.KeyType != "OKP" || next.Curve != "Ed25519" || next.Algorithm != "EdDSA" || !signing.verifyKey.(ed25519.PublicKey).Equal(ed25519.PublicKey(x)) {
+		t.Errorf("Ed25519 JWK = %+v", next)
+	}
+}
+
+func TestNewKeySetErrors(t *testing.T) {
+	now := time.Now()
+
+	if _, err := NewKeySet("", ""); !errors.Is(err, ErrNoSigningKey) {
+		t.Errorf("NewKeySet without keys returned %v, want ErrNoSigningKey", err)
+	}
+
+	dup := writeManifest(t,
+		testKey{ID: "same", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now},
+		testKey{ID: "same", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now},
+	)
+	if _, err := NewKeySet("", dup); err == nil {
+		t.Error("NewKeySet accepted a duplicate kid")
+	}
+
+	legacy := writeManifest(t, testKey{ID: LegacyKeyID, Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now})
+	if _, err := NewKeySet(testSecret, legacy); err == nil {
+		t.Error("NewKeySet accepted a manifest key reusing the legacy kid")
+	}
+
+	// The key file holds an Ed25519 key but the manifest claims RS256.
+	mismatch := writeManifest(t, testKey{ID: "k", Algorithm: SigningAlgorithmEdDSA, ActiveFrom: now})
+	raw, _ := os.ReadFile(mismatch)
+	var manifest map[string][]map[string]any
+	json.Unmarshal(raw, &manifest)
+	manifest["keys"][0]["alg"] = SigningAlgorithmRS256
+	raw, _ = json.Marshal(manifest)
+	os.WriteFile(mismatch, raw, 0o600)
+	if _, err := NewKeySet("", mismatch); err == nil {
+		t.Error("NewKeySet accepted an Ed25519 key declared as RS256")
+	}
+}
@@ -12,21 +12,35 @@ const (
 	AccessTokenExpirationTime          = time.Minute * 15    // 15 minutes
 	RefreshTokenExpirationTime         = time.Hour * 24 * 7  // 7 days
 	RefreshTokenExpirationTimeForAdmin = time.Hour * 24 * 14 // 30 days
+	SetPasswordTokenExpirationTime     = time.Minute * 30    // 30 minutes
+	PasswordResetTokenExpirationTime   = time.Minute * 30    // 30 minutes
+	MFAChallengeTokenExpirationTime    = time.Minute * 5     // 5 minutes
 
-	RefreshTokenName = "refresh_token"
-	AccessTokenName  = "access_token"
-	SetPasswordToken = "set_password_token"
+	RefreshTokenName   = "refresh_token"
+	AccessTokenName    = "access_token"
+	SetPasswordToken   = "set_password_token"
+	PasswordResetToken = "password_reset_token"
+	MFAChallengeToken  = "mfa_challenge_token"
 
 	JWTTypeMember = "member"
 	JWTTypeAdmin  = "admin"
+
+	// TokenUseAccess and TokenUseRefresh are the values of the typ claim,
+	// which keeps a refresh token from being accepted as an access token.
+	TokenUseAccess  = "access"
+	TokenUseRefresh = "refresh"
 )
 
 type CreatetokenParams struct {
-	ID          uuid.UUID
-	Email       string
-	Roles       []string
-	Permissions []string
-	Duration    time.Duration
+	ID           uuid.UUID
+	Email        string
+	Roles        []string
+	Permissions  []string
+	Version      int64
+	MFA          bool
+	MemberStatus string
+	Use          string
+	Duration     time.Duration
 }
 
 type TokenPair struct {
@@ -35,10 +49,13 @@ type TokenPair struct {
 }
 
 type TokenPairParams struct {
-	ID          uuid.UUID
-	Email       string
-	Roles       []string
-	Permissions []string
+	ID           uuid.UUID
+	Email        string
+	Roles        []string
+	Permissions  []string
+	Version      int64
+	MFA          bool
+	MemberStatus string
 }
 
 type TokenExpirationConfig struct {
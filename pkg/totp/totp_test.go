@@ -0,0 +1,58 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+)
+
+// rfc6238Secret is the SHA1 seed of RFC 6238 Appendix B, base32 encoded.
+var rfc6238Secret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+// TestCodeRFC6238 checks the SHA1 test vectors of RFC 6238 Appendix B. The RFC
+// lists 8-digit codes; a 6-digit code is their last six digits.
+func TestCodeRFC6238(t *testing.T) {
+	vectors := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, v := range vectors {
+		want := v.code[len(v.code)-Digits:]
+		got, err := Code(rfc6238Secret, Step(time.Unix(v.unix, 0)))
+		if err != nil {
+			t.Fatalf("Code at %d: %v", v.unix, err)
+		}
+		if got != want {
+			t.Errorf("Code at %d = %s, want %s", v.unix, got, want)
+		}
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	code, err := Code(rfc6238Secret, Step(now))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	step, ok := Validate(rfc6238Secret, code, now.Add(Period))
+	if !ok || step != Step(now) {
+		t.Errorf("Validate one step later = %d, %v, want %d, true", step, ok, Step(now))
+	}
+
+	if _, ok := Validate(rfc6238Secret, code, now.Add(3*Period)); ok {
+		t.Error("Validate accepted a code three steps old")
+	}
+
+	if _, ok := Validate(rfc6238Secret, "12345", now); ok {
+		t.Error("Validate accepted a code of the wrong length")
+	}
+}